@@ -13,7 +13,8 @@ import (
 )
 
 var (
-	noTui bool
+	noTui  bool
+	dryRun bool
 )
 
 var rootCmd = &cobra.Command{
@@ -38,6 +39,9 @@ func Execute() {
 		existingCmds := make(map[string]bool)
 		for _, cmd := range rootCmd.Commands() {
 			existingCmds[cmd.Name()] = true
+			for _, alias := range cmd.Aliases {
+				existingCmds[alias] = true
+			}
 		}
 
 		for id, task := range config.Tasks {
@@ -49,13 +53,18 @@ func Execute() {
 				Use:   taskID,
 				Short: task.Description,
 				Run: func(cmd *cobra.Command, args []string) {
+					if dryRun {
+						commands.RunExplain(taskID, "text")
+						return
+					}
 					if noTui {
-						runner.RunTask(taskID, nil, nil)
+						commands.RunHeadless(taskID, func() { runner.RunTask(taskID, nil, nil) })
 						return
 					}
 					launchTUIWithTask(taskID)
 				},
 			}
+			cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the execution plan without running anything")
 			rootCmd.AddCommand(cmd)
 		}
 	}
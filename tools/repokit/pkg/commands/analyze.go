@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"repokit/pkg/core"
+	"repokit/pkg/report"
+)
+
+// RunAnalyze prints the critical path, worker-count estimates, caching and
+// splitting candidates and fail-early orderings of a pipeline, based on the
+// durations recorded over the most recent runs.
+func RunAnalyze(id string, lastRuns int) {
+	config, err := core.GetConfig()
+	if err != nil {
+		core.Fatal("%v", err)
+	}
+	runs, err := core.LoadHistory()
+	if err != nil {
+		core.Fatal("Failed to load run history: %v", err)
+	}
+	if lastRuns > 0 && len(runs) > lastRuns {
+		runs = runs[len(runs)-lastRuns:]
+	}
+
+	a, err := report.Analyze(id, config, runs)
+	if err != nil {
+		core.Fatal("%v", err)
+	}
+
+	core.Info("Analysis of %s over %d recorded runs", id, a.Runs)
+	if len(a.Unknown) > 0 {
+		core.Warning("No recorded durations for: %s. Run them at least once for a complete estimate.", strings.Join(a.Unknown, ", "))
+	}
+
+	section := func(title string) { fmt.Println("\n" + core.Bold.Render(title)) }
+
+	section("Critical path")
+	fmt.Printf("  estimated wall time %s\n", core.Cyan.Render(seconds(a.Estimate)))
+	if len(a.CriticalPath) == 0 {
+		fmt.Println("  " + core.Subtle.Render("No timings recorded yet."))
+	} else {
+		fmt.Printf("  %s %s\n", strings.Join(a.CriticalPath, " → "), core.Subtle.Render(seconds(a.CriticalTime)))
+	}
+
+	if len(a.Workers) > 0 {
+		section("Workers")
+		for _, w := range a.Workers {
+			fmt.Printf("  %s %s\n", w.Batch, core.Subtle.Render(fmt.Sprintf("(configured %d, recommended %d)", w.Configured, w.Recommended)))
+			for i, wall := range w.Walls {
+				marker := " "
+				if i+1 == w.Recommended {
+					marker = core.Green.Render("●")
+				}
+				fmt.Printf("    %s %2d workers  %s\n", marker, i+1, seconds(wall))
+			}
+		}
+	}
+
+	if len(a.Candidates) > 0 {
+		section("Candidates")
+		for _, c := range a.Candidates {
+			fmt.Printf("  %-6s %s %s\n", core.Yellow.Render(c.Kind), c.Task, core.Subtle.Render(c.Reason))
+		}
+	}
+
+	if len(a.Orderings) > 0 {
+		section("Fail-early ordering")
+		for _, o := range a.Orderings {
+			fmt.Printf("  %s %s\n", o.Pipeline, core.Subtle.Render(fmt.Sprintf("(expected time to first failure or completion %s → %s)",
+				seconds(o.CurrentExpected), seconds(o.SuggestedExpected))))
+			fmt.Printf("    current    %s\n", strings.Join(o.Current, ", "))
+			fmt.Printf("    suggested  %s\n", core.Green.Render(strings.Join(o.Suggested, ", ")))
+		}
+		fmt.Println("  " + core.Subtle.Render("Only reorder steps that do not depend on each other's output."))
+	}
+	fmt.Println()
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
@@ -49,10 +49,10 @@ func RunClean(force bool) {
 	}
 
 	core.Step("Cleaning project...")
-	runner.RunInteractive("Git Clean", "git clean -Xfd -e .env.local", ".")
+	runner.RunInteractive("git_clean", "Git Clean", "git clean -Xfd -e .env.local", ".")
 
 	core.Step("Reinstalling dependencies...")
-	runner.RunInteractive("PNPM Install", "pnpm install", ".")
+	runner.RunInteractive("pnpm_install", "PNPM Install", "pnpm install", ".")
 
 	core.Success("Project cleaned and dependencies reinstalled.")
 }
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"repokit/pkg/core"
+	"repokit/pkg/runner"
+)
+
+// RunExplain prints the execution plan of a task, as a tree or as JSON,
+// without running anything.
+func RunExplain(id, format string) {
+	if _, err := core.GetConfig(); err != nil {
+		core.Fatal("%v", err)
+	}
+	plan := runner.Plan(id, nil)
+
+	switch format {
+	case "text":
+		fmt.Println(core.Subtle.Render("Dry run: nothing is executed. Task results are never cached, so every task below runs."))
+		fmt.Println()
+		printPlan(plan, "", "", "")
+		fmt.Println()
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(plan); err != nil {
+			core.Fatal("Failed to write plan: %v", err)
+		}
+	default:
+		core.Fatal("Unknown format %q (expected text or json)", format)
+	}
+}
+
+// printPlan draws a node and its children with box-drawing branches. role
+// labels hooks ("pre_run", "post_run") in their parent.
+func printPlan(n *runner.PlanNode, role, branch, indent string) {
+	title := core.Bold.Render(n.ID)
+	if n.Name != "" {
+		title += " " + core.Subtle.Render(n.Name)
+	}
+	if role != "" {
+		title = core.Yellow.Render(role) + " " + title
+	}
+
+	var tags []string
+	switch {
+	case n.Parallel:
+		tags = append(tags, fmt.Sprintf("parallel × %d", n.Workers), n.OnFailure)
+	case len(n.Tasks) > 0:
+		tags = append(tags, "sequential")
+	}
+	if n.Nested {
+		tags = append(tags, "nested process")
+	}
+	if n.Interactive {
+		tags = append(tags, "interactive")
+	}
+	if n.Slots > 1 {
+		tags = append(tags, fmt.Sprintf("%d slots", n.Slots))
+	}
+	if len(n.Locks) > 0 {
+		tags = append(tags, "locks "+strings.Join(n.Locks, ", "))
+	}
+	if len(tags) > 0 {
+		title += " " + core.Cyan.Render("["+strings.Join(tags, " · ")+"]")
+	}
+	fmt.Println(branch + title)
+
+	detail := func(label, value string) {
+		fmt.Printf("%s  %s %s\n", indent, core.Subtle.Render(fmt.Sprintf("%-5s", label)), value)
+	}
+	if n.Error != "" {
+		detail("error", core.Red.Render(n.Error))
+	}
+	if n.Skipped != "" {
+		detail("skip", core.Subtle.Render(n.Skipped))
+	}
+	if n.Command != "" {
+		detail("run", n.Command)
+	}
+	if n.Cwd != "" && (n.Command != "" || n.Nested) {
+		detail("cwd", n.Cwd)
+	}
+	if len(n.Env) > 0 {
+		detail("env", strings.Join(n.Env, " "))
+	}
+	if len(n.Params) > 0 {
+		names := make([]string, 0, len(n.Params))
+		for name := range n.Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var params []string
+		for _, name := range names {
+			params = append(params, fmt.Sprintf("%s=%q", name, n.Params[name]))
+		}
+		detail("param", strings.Join(params, " "))
+	}
+
+	type child struct {
+		node *runner.PlanNode
+		role string
+	}
+	var children []child
+	for _, c := range n.PreRun {
+		children = append(children, child{c, "pre_run"})
+	}
+	for _, c := range n.Tasks {
+		children = append(children, child{c, ""})
+	}
+	for _, c := range n.PostRun {
+		children = append(children, child{c, "post_run"})
+	}
+	for i, c := range children {
+		if i == len(children)-1 {
+			printPlan(c.node, c.role, indent+"└─ ", indent+"   ")
+		} else {
+			printPlan(c.node, c.role, indent+"├─ ", indent+"│  ")
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"repokit/pkg/core"
+	"repokit/pkg/report"
+)
+
+// RunHeadless calls run, which runs taskID without the TUI, records it in
+// the run history, prints the problems found and notifies when it finishes,
+// including when a failure exits the process early.
+func RunHeadless(taskID string, run func()) {
+	// Nested invocations, and commands that tasks of a recorded run call,
+	// are part of an outer run that notifies and merges what they record.
+	joined := os.Getenv(core.RunIDEnv) != ""
+	if os.Getenv("REPOKIT_NESTED") == "1" && !joined {
+		run()
+		return
+	}
+
+	var recorder *core.Recorder
+	if joined {
+		recorder = core.JoinRecording(taskID)
+	} else {
+		recorder = core.StartRecording(taskID)
+	}
+	finish := func(failure string) {
+		run := recorder.Finish(failure)
+		if joined {
+			if err := core.SaveNested(run); err != nil {
+				core.Warning("Failed to save run record: %v", err)
+			}
+			return
+		}
+		if !core.Quiet {
+			PrintProblems(report.Problems(run))
+		}
+		if err := core.AppendHistory(run); err != nil {
+			core.Warning("Failed to save run history: %v", err)
+		}
+		n := core.Notification{Task: taskID, Failed: run.Status == "error", Duration: run.Duration}
+		if err := core.Notify(n); err != nil {
+			core.Warning("Notification failed: %v", err)
+		}
+	}
+
+	exit := core.OSExit
+	core.OSExit = func(code int) {
+		failure := ""
+		if code != 0 {
+			failure = fmt.Sprintf("exit status %d", code)
+		}
+		finish(failure)
+		exit(code)
+	}
+	defer func() { core.OSExit = exit }()
+
+	run()
+	finish("")
+}
@@ -2,22 +2,27 @@ package commands
 
 import (
 	"repokit/pkg/core"
-
-	"github.com/spf13/cobra"
 )
 
 // LLMConfig is now an alias or we just use core.LLMConfig
 type LLMConfig = core.LLMConfig
 
-// AddLLMFlags adds common LLM-related flags to a Cobra command.
-// It returns a pointer to an LLMConfig struct which will be populated when flags are parsed.
-func AddLLMFlags(cmd *cobra.Command, defaultOutput string) *LLMConfig {
-	cfg := &LLMConfig{}
-
-	cmd.Flags().StringVar(&cfg.Provider, "provider", "gemini", "LLM provider (gemini, groq, local)")
-	cmd.Flags().StringVar(&cfg.Model, "model", "", "Model name (defaults: gemini-2.5-flash, llama3-8b-8192)")
-	cmd.Flags().StringVar(&cfg.APIKey, "api-key", "", "API Key (or set GEMINI_API_KEY / GROQ_API_KEY)")
-	cmd.Flags().StringVar(&cfg.Output, "output", defaultOutput, "Output file path (e.g., README.md for generate_readme)")
+// llmParams declares the common LLM-related params of a native command.
+func llmParams(defaultOutput string) []core.Param {
+	return []core.Param{
+		{Name: "provider", Description: "LLM provider", Default: "gemini", Options: []string{"gemini", "groq", "local"}},
+		{Name: "model", Description: "Model name (defaults: gemini-2.5-flash, llama3-8b-8192)"},
+		{Name: "api-key", Description: "API Key (or set GEMINI_API_KEY / GROQ_API_KEY)"},
+		{Name: "output", Description: "Output file path (e.g., README.md for generate_readme)", Default: defaultOutput},
+	}
+}
 
-	return cfg
+// llmConfig builds the LLM configuration from the params declared by llmParams.
+func llmConfig(p core.Params) *LLMConfig {
+	return &LLMConfig{
+		Provider: p["provider"],
+		Model:    p["model"],
+		APIKey:   p["api-key"],
+		Output:   p["output"],
+	}
 }
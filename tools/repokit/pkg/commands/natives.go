@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"repokit/pkg/core"
+	"repokit/pkg/svg"
+)
+
+// ─── Native Commands ─────────────────────────────────────────────────────────
+
+// nativeCommand implements core.NativeCommand from plain values.
+type nativeCommand struct {
+	id          string
+	name        string
+	description string
+	params      []core.Param
+	run         func(ctx context.Context, p core.Params) (core.Result, error)
+}
+
+func (c nativeCommand) ID() string           { return c.id }
+func (c nativeCommand) Name() string         { return c.name }
+func (c nativeCommand) Description() string  { return c.description }
+func (c nativeCommand) Params() []core.Param { return c.params }
+
+func (c nativeCommand) Run(ctx context.Context, p core.Params) (core.Result, error) {
+	return c.run(ctx, p)
+}
+
+func init() {
+	core.RegisterNative(nativeCommand{
+		id:          "pack",
+		name:        "Pack",
+		description: "Bundle Go package documentation into a Markdown file",
+		params: []core.Param{
+			{Name: "dir", Description: "Directory to document (default: current directory)", Positional: true},
+		},
+		run: func(ctx context.Context, p core.Params) (core.Result, error) {
+			out := RunPack(p["dir"])
+			if out == "" {
+				return core.Result{}, fmt.Errorf("directory not found: %s", p["dir"])
+			}
+			return core.Result{Summary: "Documentation bundled", Outputs: []string{out}}, nil
+		},
+	})
+
+	core.RegisterNative(nativeCommand{
+		id:          "clean",
+		name:        "Clean",
+		description: "Removes untracked files from the repository",
+		params: []core.Param{
+			{Name: "force", Description: "Skip the check for uncommitted changes", Bool: true, Default: "false"},
+		},
+		run: func(ctx context.Context, p core.Params) (core.Result, error) {
+			RunClean(p.Bool("force"))
+			return core.Result{}, nil
+		},
+	})
+
+	core.RegisterNative(nativeCommand{
+		id:          "optimize_svg",
+		name:        "Optimize SVGs",
+		description: "Optimize SVG files using native minifier and LLM analysis",
+		params: append([]core.Param{
+			{Name: "pattern", Description: "Glob of the SVG files to optimize", Default: "src/assets/**/*.svg", Positional: true},
+			{Name: "tolerance", Description: "Maximum distance path simplification may move an outline, in user units (default: the svg config, else " + strconv.FormatFloat(svg.DefaultTolerance, 'f', -1, 64) + ")"},
+			{Name: "verify", Description: "Render files before and after, keeping originals that change visibly", Bool: true, Default: "false"},
+			{Name: "max-diff", Description: "Share of painted pixels allowed to change when verifying (0-1)", Default: strconv.FormatFloat(svg.DefaultMaxDiff, 'f', -1, 64)},
+			{Name: "check", Description: "Fail if any file is not optimized, without writing", Bool: true, Default: "false"},
+			{Name: "dry-run", Description: "Report per-file savings without writing", Bool: true, Default: "false"},
+			{Name: "diff", Description: "Print a unified diff of each change without writing", Bool: true, Default: "false"},
+			{Name: "keep-comments", Description: "Keep XML comments", Bool: true, Default: "false"},
+			{Name: "keep-metadata", Description: "Keep <metadata> elements such as licensing information", Bool: true, Default: "false"},
+			{Name: "out-dir", Description: "Write optimized copies to this directory instead of in place"},
+		}, llmParams("")...),
+		run: func(ctx context.Context, p core.Params) (core.Result, error) {
+			config, err := core.GetConfig()
+			if err != nil {
+				return core.Result{}, err
+			}
+			if p["tolerance"] != "" {
+				tolerance, err := strconv.ParseFloat(p["tolerance"], 64)
+				if err != nil || tolerance < 0 {
+					return core.Result{}, fmt.Errorf("invalid tolerance %q: expected a non-negative number", p["tolerance"])
+				}
+				// The command line wins over the config, overrides included.
+				config.SVG.Tolerance = &tolerance
+				config.SVG.Overrides = slices.Clone(config.SVG.Overrides)
+				for i := range config.SVG.Overrides {
+					config.SVG.Overrides[i].Tolerance = nil
+				}
+			}
+			opts := svg.DefaultOptions().WithConfig(config.SVG)
+			maxDiff, err := strconv.ParseFloat(p["max-diff"], 64)
+			if err != nil || maxDiff < 0 || maxDiff > 1 {
+				return core.Result{}, fmt.Errorf("invalid max-diff %q: expected a number between 0 and 1", p["max-diff"])
+			}
+			opts.Verify, opts.MaxDiff = p.Bool("verify"), maxDiff
+			opts.Check, opts.DryRun, opts.Diff = p.Bool("check"), p.Bool("dry-run"), p.Bool("diff")
+			opts.KeepComments, opts.KeepMetadata = p.Bool("keep-comments"), p.Bool("keep-metadata")
+			opts.OutDir = p["out-dir"]
+			if opts.OutDir != "" && (opts.Check || opts.DryRun || opts.Diff) {
+				return core.Result{}, fmt.Errorf("out-dir cannot be combined with check, dry-run or diff, which do not write files")
+			}
+
+			cfg := llmConfig(p)
+			provider, _ := NewProvider(cfg)
+			svg.SetLLMConfig(cfg, provider)
+			return core.Result{}, svg.Optimize(p["pattern"], opts)
+		},
+	})
+
+	core.RegisterNative(nativeCommand{
+		id:          "export_schema",
+		name:        "Export Schema",
+		description: "Generate JSON schema for tasks.yaml configuration",
+		params: []core.Param{
+			{Name: "out", Description: "Output file path", Default: "tools/eslint/schemas/tasks.schema.json", Shorthand: "o"},
+		},
+		run: func(ctx context.Context, p core.Params) (core.Result, error) {
+			if err := core.Export("tasks", p["out"]); err != nil {
+				return core.Result{}, fmt.Errorf("failed to export schema: %w", err)
+			}
+			return core.Result{
+				Summary: "Successfully generated JSON schema at " + p["out"],
+				Outputs: []string{p["out"]},
+			}, nil
+		},
+	})
+
+	core.RegisterNative(nativeCommand{
+		id:          "generate_readme",
+		name:        "Generate README",
+		description: "AI-powered README generation for your codebase",
+		params:      llmParams("README.md"),
+		run: func(ctx context.Context, p core.Params) (core.Result, error) {
+			RunGenerateReadme(ctx, llmConfig(p))
+			return core.Result{Outputs: []string{p["output"]}}, nil
+		},
+	})
+
+	core.RegisterNative(nativeCommand{
+		id:          "auto_commit",
+		name:        "Auto Commit",
+		description: "AI-assisted automatic commit message generation",
+		params:      llmParams(""),
+		run: func(ctx context.Context, p core.Params) (core.Result, error) {
+			RunAutocommit(ctx, llmConfig(p))
+			return core.Result{}, nil
+		},
+	})
+}
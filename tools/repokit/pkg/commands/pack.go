@@ -5,7 +5,6 @@ import (
 	"go/build"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"repokit/pkg/core"
 	"github.com/atotto/clipboard"
@@ -14,13 +13,13 @@ import (
 	"github.com/princjef/gomarkdoc/logger"
 )
 
-var slugifyRegexp = regexp.MustCompile(`[^a-z0-9]+`)
-
-// RunPack executes the pack command to bundle Go package documentation into a Markdown file.
-func RunPack(targetDir string) {
+// RunPack executes the pack command to bundle Go package documentation into
+// a Markdown file. It returns the path of the file, or "" when the target
+// directory does not exist.
+func RunPack(targetDir string) string {
 	cwd, targetDir, targetDirName := resolveTargetDir(targetDir)
 	if targetDir == "" {
-		return // error already logged
+		return "" // error already logged
 	}
 
 	core.Info("Generating Go documentation for: %s", targetDir)
@@ -32,7 +31,7 @@ func RunPack(targetDir string) {
 		core.Fatal("Failed to parse packages: %v", err)
 	}
 
-	outputFilename := fmt.Sprintf("pack_output_%s.md", slugifyStr(targetDirName))
+	outputFilename := fmt.Sprintf("pack_output_%s.md", core.Slugify(targetDirName))
 	outputPath := filepath.Join(cwd, "tools", "repokit", "dist", outputFilename)
 
 	writeOutput(outputPath, docContent)
@@ -46,6 +45,7 @@ func RunPack(targetDir string) {
 	core.Info("  • File Size:     %.2f KB", sizeKB)
 	core.Info("  • Output Saved:  %s", outputPath)
 	core.Info("Content copied to clipboard.")
+	return outputPath
 }
 
 func resolveTargetDir(targetDir string) (string, string, string) {
@@ -144,10 +144,3 @@ func writeOutput(outputPath, content string) {
 	}
 	_ = clipboard.WriteAll(content)
 }
-
-func slugifyStr(s string) string {
-	s = strings.ToLower(s)
-	s = slugifyRegexp.ReplaceAllString(s, "-")
-	return strings.Trim(s, "-")
-}
-
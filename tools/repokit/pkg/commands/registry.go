@@ -1,92 +1,213 @@
 package commands
 
 import (
+	"strconv"
+	"strings"
+
 	"repokit/pkg/core"
+	"repokit/pkg/runner"
 	"repokit/pkg/svg"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	osLLMConfig *LLMConfig
-	schemaOut   string
+	reportFormat string
+	reportRun    string
+	reportOut    string
+
+	problemsFormat string
+
+	analyzeRuns int
+
+	explainFormat string
+
+	spriteOut      string
+	spriteManifest string
+
+	componentsFramework string
+	componentsOut       string
+
+	paletteFile   string
+	paletteFix    bool
+	paletteVars   bool
+	paletteFormat string
 )
 
 // RegisterCommands adds all available commands to the provided root command.
 func RegisterCommands(rootCmd *cobra.Command) {
-	// 1. Pack Command
-	var packCmd = &cobra.Command{
-		Use:   "pack [dir]",
-		Short: "Bundle Go package documentation into a Markdown file",
-		Args:  cobra.MaximumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			target := ""
-			if len(args) > 0 {
-				target = args[0]
+	// 1. Native Commands. A task of the same ID in tasks.yaml takes
+	// precedence, as it does in the TUI, and the native command then only
+	// answers to its hyphenated form, which such tasks call it by.
+	config, _ := core.GetConfig()
+	for _, c := range core.NativeCommands() {
+		cmd := nativeCobraCommand(c)
+		if _, shadowed := config.Tasks[c.ID()]; shadowed {
+			if len(cmd.Aliases) == 0 {
+				continue
 			}
-			RunPack(target)
-		},
+			cmd.Use = cmd.Aliases[0] + strings.TrimPrefix(cmd.Use, c.ID())
+			cmd.Aliases = nil
+		}
+		rootCmd.AddCommand(cmd)
 	}
-	rootCmd.AddCommand(packCmd)
 
-	// 2. Clean Command
-	var cleanCmd = &cobra.Command{
-		Use:   "clean",
-		Short: "Removes untracked files from the repository",
+	// 2. Report Command
+	var reportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Generate reports from recorded runs",
+	}
+	var timelineCmd = &cobra.Command{
+		Use:   "timeline",
+		Short: "Render a Gantt chart of a recorded run as SVG or HTML",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			RunTimelineReport(reportRun, reportFormat, reportOut)
+		},
+	}
+	timelineCmd.Flags().StringVar(&reportFormat, "format", "svg", "Output format (svg or html)")
+	timelineCmd.Flags().StringVar(&reportRun, "run", "last", "Run ID (or unique prefix) from the run history")
+	timelineCmd.Flags().StringVarP(&reportOut, "out", "o", "", "Output file path (default stdout)")
+	reportCmd.AddCommand(timelineCmd)
+	var problemsCmd = &cobra.Command{
+		Use:   "problems",
+		Short: "List the diagnostics extracted from a recorded run, grouped by file",
+		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			RunClean(false) // Default to safe mode; --force logic can be added if needed
+			RunProblemsReport(reportRun, problemsFormat)
 		},
 	}
-	rootCmd.AddCommand(cleanCmd)
+	problemsCmd.Flags().StringVar(&problemsFormat, "format", "table", "Output format (table or json)")
+	problemsCmd.Flags().StringVar(&reportRun, "run", "last", "Run ID (or unique prefix) from the run history")
+	reportCmd.AddCommand(problemsCmd)
+	rootCmd.AddCommand(reportCmd)
 
-	// 3. Optimize SVG Command
-	var optimizeSvgCmd = &cobra.Command{
-		Use:   "optimize-svg <pattern>",
-		Short: "Optimize SVG files using native minifier and LLM analysis",
+	// 3. Analyze Command
+	var analyzeCmd = &cobra.Command{
+		Use:   "analyze <pipeline>",
+		Short: "Critical path, worker and ordering recommendations from run history",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			p, _ := NewProvider(osLLMConfig)
-			svg.SetLLMConfig(osLLMConfig, p)
-			svg.Optimize(args[0])
+			RunAnalyze(args[0], analyzeRuns)
 		},
 	}
-	osLLMConfig = AddLLMFlags(optimizeSvgCmd, "")
-	rootCmd.AddCommand(optimizeSvgCmd)
+	analyzeCmd.Flags().IntVar(&analyzeRuns, "runs", 20, "Number of most recent runs to learn durations from (0 for all)")
+	rootCmd.AddCommand(analyzeCmd)
 
-	// 4. Export Schema Command
-	var exportSchemaCmd = &cobra.Command{
-		Use:   "export_schema",
-		Short: "Generate JSON schema for tasks.yaml configuration",
+	// 4. Explain Command
+	var explainCmd = &cobra.Command{
+		Use:   "explain <task>",
+		Short: "Show the resolved execution plan of a task without running it",
+		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			err := core.Export("tasks", schemaOut)
-			if err != nil {
-				core.Fatal("Failed to export schema: %v", err)
-			}
-			core.Success("Successfully generated JSON schema at %s", schemaOut)
+			RunExplain(args[0], explainFormat)
 		},
 	}
-	exportSchemaCmd.Flags().StringVarP(&schemaOut, "out", "o", "tools/eslint/schemas/tasks.schema.json", "Output file path")
-	rootCmd.AddCommand(exportSchemaCmd)
+	explainCmd.Flags().StringVar(&explainFormat, "format", "text", "Output format (text or json)")
+	rootCmd.AddCommand(explainCmd)
 
-	// 5. Generate README Command
-	var readmeCmd = &cobra.Command{
-		Use:   "generate-readme",
-		Short: "AI-powered README generation for your codebase",
+	// 5. SVG Command
+	var svgCmd = &cobra.Command{
+		Use:   "svg",
+		Short: "Build assets from SVG files",
+	}
+	var spriteCmd = &cobra.Command{
+		Use:   "sprite <pattern>",
+		Short: "Combine optimized SVG files into a sprite sheet of <symbol> elements",
+		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			RunGenerateReadme(cmd.Context(), osLLMConfig)
+			RunSVGSprite(args[0], spriteOut, spriteManifest)
 		},
 	}
-	AddLLMFlags(readmeCmd, "README.md")
-	rootCmd.AddCommand(readmeCmd)
-
-	// 6. Auto Commit Command
-	var commitCmd = &cobra.Command{
-		Use:   "auto-commit",
-		Short: "AI-assisted automatic commit message generation",
+	spriteCmd.Flags().StringVarP(&spriteOut, "out", "o", "sprite.svg", "Output file path")
+	spriteCmd.Flags().StringVar(&spriteManifest, "manifest", "", "Also write the symbol IDs and viewBoxes to this .json or .ts file")
+	svgCmd.AddCommand(spriteCmd)
+	var componentsCmd = &cobra.Command{
+		Use:   "components <pattern>",
+		Short: "Generate an icon component for each optimized SVG file",
+		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			RunAutocommit(cmd.Context(), osLLMConfig)
+			RunSVGComponents(args[0], componentsFramework, componentsOut)
 		},
 	}
-	AddLLMFlags(commitCmd, "")
-	rootCmd.AddCommand(commitCmd)
+	componentsCmd.Flags().StringVar(&componentsFramework, "framework", "astro", "Component framework ("+strings.Join(svg.Frameworks, " or ")+")")
+	componentsCmd.Flags().StringVarP(&componentsOut, "out", "o", "src/components/icons", "Output directory")
+	svgCmd.AddCommand(componentsCmd)
+	var paletteCmd = &cobra.Command{
+		Use:   "palette <pattern>",
+		Short: "Check that SVG files only paint with the colors of the design tokens",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			fix := svg.FixNone
+			switch {
+			case paletteVars:
+				fix = svg.FixVar
+			case paletteFix:
+				fix = svg.FixColor
+			}
+			RunSVGPalette(args[0], paletteFile, fix, paletteFormat)
+		},
+	}
+	paletteCmd.Flags().StringVar(&paletteFile, "palette", "", "Palette file (.css, .yaml or .json), instead of the svg config's palette")
+	paletteCmd.Flags().BoolVar(&paletteFix, "fix", false, "Replace off-palette colors with the nearest token's color")
+	paletteCmd.Flags().BoolVar(&paletteVars, "css-vars", false, "Replace off-palette colors with var(--token, color) of the nearest token (implies --fix)")
+	paletteCmd.Flags().StringVar(&paletteFormat, "format", "table", "Output format (table or json)")
+	svgCmd.AddCommand(paletteCmd)
+	rootCmd.AddCommand(svgCmd)
+}
+
+// nativeCobraCommand exposes a native command on the command line under its
+// ID, with positional params as arguments and the others as flags. IDs with
+// underscores also answer to their hyphenated form (optimize-svg).
+func nativeCobraCommand(c core.NativeCommand) *cobra.Command {
+	use := c.ID()
+	var positional []string
+	for _, p := range c.Params() {
+		if p.Positional {
+			positional = append(positional, p.Name)
+			use += " [" + p.Name + "]"
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: c.Description(),
+		Args:  cobra.MaximumNArgs(len(positional)),
+	}
+	if alias := strings.ReplaceAll(c.ID(), "_", "-"); alias != c.ID() {
+		cmd.Aliases = []string{alias}
+	}
+
+	strs := make(map[string]*string)
+	bools := make(map[string]*bool)
+	for _, p := range c.Params() {
+		if p.Positional {
+			continue
+		}
+		usage := p.Description
+		if len(p.Options) > 0 {
+			usage += " (" + strings.Join(p.Options, ", ") + ")"
+		}
+		if p.Bool {
+			def, _ := strconv.ParseBool(p.Default)
+			bools[p.Name] = cmd.Flags().BoolP(p.Name, p.Shorthand, def, usage)
+		} else {
+			strs[p.Name] = cmd.Flags().StringP(p.Name, p.Shorthand, p.Default, usage)
+		}
+	}
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		params := make(map[string]string)
+		for i, arg := range args {
+			params[positional[i]] = arg
+		}
+		for name, v := range strs {
+			params[name] = *v
+		}
+		for name, v := range bools {
+			params[name] = strconv.FormatBool(*v)
+		}
+		RunHeadless(c.ID(), func() { runner.RunNative(c.ID(), params) })
+	}
+	return cmd
 }
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRegisterCommands_TaskPrecedence(t *testing.T) {
+	root := &cobra.Command{Use: "repokit"}
+	RegisterCommands(root)
+	names := make(map[string]bool)
+	for _, c := range root.Commands() {
+		names[c.Name()] = true
+		for _, alias := range c.Aliases {
+			names[alias] = true
+		}
+	}
+
+	// optimize_svg and export_schema are also tasks, which keep their IDs.
+	for _, id := range []string{"optimize_svg", "export_schema"} {
+		if names[id] {
+			t.Errorf("native command %s should give way to the task of the same ID", id)
+		}
+	}
+	for _, name := range []string{"optimize-svg", "export-schema", "pack", "clean"} {
+		if !names[name] {
+			t.Errorf("expected native command %s to be registered", name)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"repokit/pkg/core"
+	"repokit/pkg/report"
+)
+
+// RunTimelineReport renders the timeline of a recorded run as a standalone
+// SVG or HTML file, or to stdout when outPath is empty.
+func RunTimelineReport(runID, format, outPath string) {
+	run, err := core.FindRun(runID)
+	if err != nil {
+		core.Fatal("Failed to load run: %v", err)
+	}
+
+	tl := report.NewTimeline(run)
+	var buf bytes.Buffer
+	switch format {
+	case "svg":
+		err = tl.RenderSVG(&buf)
+	case "html":
+		err = tl.RenderHTML(&buf)
+	default:
+		err = fmt.Errorf("unknown format %q (expected svg or html)", format)
+	}
+	if err != nil {
+		core.Fatal("Failed to render timeline: %v", err)
+	}
+
+	if outPath == "" {
+		fmt.Print(buf.String())
+		return
+	}
+	if err := os.WriteFile(outPath, buf.Bytes(), 0644); err != nil {
+		core.Fatal("Failed to write timeline: %v", err)
+	}
+	core.Success("Timeline of %s (%s) written to %s", run.Task, run.ID, outPath)
+}
+
+// RunProblemsReport prints the diagnostics extracted from a recorded run,
+// as a table grouped by file or as JSON.
+func RunProblemsReport(runID, format string) {
+	run, err := core.FindRun(runID)
+	if err != nil {
+		core.Fatal("Failed to load run: %v", err)
+	}
+
+	problems := report.Problems(run)
+	switch format {
+	case "table":
+		if len(problems) == 0 {
+			core.Success("No problems recorded in %s (%s)", run.Task, run.ID)
+			return
+		}
+		PrintProblems(problems)
+	case "json":
+		if err := report.WriteProblemsJSON(os.Stdout, problems); err != nil {
+			core.Fatal("Failed to write problems: %v", err)
+		}
+	default:
+		core.Fatal("Unknown format %q (expected table or json)", format)
+	}
+}
+
+// PrintProblems prints a compact table of diagnostics grouped by file.
+func PrintProblems(problems []report.TaskProblem) {
+	if len(problems) == 0 {
+		return
+	}
+	errors, warnings := report.CountSeverities(problems)
+	fmt.Printf("\n%s %s\n", core.Bold.Render("Problems"),
+		core.Subtle.Render(fmt.Sprintf("(%d errors, %d warnings)", errors, warnings)))
+
+	for _, g := range report.GroupByFile(problems) {
+		file := g.File
+		if file == "" {
+			file = "(no location)"
+		}
+		fmt.Printf("  %s\n", core.Cyan.Render(file))
+		for _, p := range g.Problems {
+			pos := ""
+			if p.Line > 0 {
+				pos = fmt.Sprintf("%d:%d", p.Line, p.Column)
+			}
+			fmt.Printf("    %-8s %s %s %s\n", pos, severityLabel(p.Severity), p.Message, core.Subtle.Render(p.Task))
+		}
+	}
+	fmt.Println()
+}
+
+func severityLabel(severity string) string {
+	label := fmt.Sprintf("%-7s", severity)
+	switch severity {
+	case "error":
+		return core.Red.Render(label)
+	case "warning":
+		return core.Yellow.Render(label)
+	default:
+		return core.Subtle.Render(label)
+	}
+}
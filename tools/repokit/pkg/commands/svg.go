@@ -0,0 +1,241 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"repokit/pkg/core"
+	"repokit/pkg/svg"
+)
+
+// RunSVGSprite combines the SVG files matching pattern into a sprite sheet
+// at outPath, optimized with the svg config, and writes a manifest of its
+// symbols to manifestPath unless it is empty.
+func RunSVGSprite(pattern, outPath, manifestPath string) {
+	config, err := core.GetConfig()
+	if err != nil {
+		core.Fatal("Failed to load config: %v", err)
+	}
+	files, err := core.ResolveFiles(pattern)
+	if err != nil {
+		core.Fatal("Failed to resolve %s: %v", pattern, err)
+	}
+	if len(files) == 0 {
+		core.Fatal("No files match %s", pattern)
+	}
+
+	data, symbols, err := svg.BuildSprite(files, svg.DefaultOptions().WithConfig(config.SVG))
+	if err != nil {
+		core.Fatal("Failed to build sprite: %v", err)
+	}
+	if err := writeFile(outPath, data); err != nil {
+		core.Fatal("Failed to write sprite: %v", err)
+	}
+
+	if manifestPath != "" {
+		var buf bytes.Buffer
+		switch ext := filepath.Ext(manifestPath); ext {
+		case ".json":
+			err = svg.WriteManifestJSON(&buf, symbols)
+		case ".ts":
+			err = svg.WriteManifestTS(&buf, symbols)
+		default:
+			err = fmt.Errorf("unknown manifest format %q (expected .json or .ts)", ext)
+		}
+		if err == nil {
+			err = writeFile(manifestPath, buf.Bytes())
+		}
+		if err != nil {
+			core.Fatal("Failed to write manifest: %v", err)
+		}
+	}
+	core.Success("Sprite of %d symbols (%s) written to %s", len(symbols), core.FormatBytes(int64(len(data))), outPath)
+}
+
+// RunSVGComponents generates a component of the framework for each SVG file
+// matching pattern into outDir, optimized with the svg config. Files are
+// only written when their content changes, and components generated
+// earlier for files that no longer match are removed.
+func RunSVGComponents(pattern, framework, outDir string) {
+	config, err := core.GetConfig()
+	if err != nil {
+		core.Fatal("Failed to load config: %v", err)
+	}
+	files, err := core.ResolveFiles(pattern)
+	if err != nil {
+		core.Fatal("Failed to resolve %s: %v", pattern, err)
+	}
+	if len(files) == 0 {
+		core.Fatal("No files match %s", pattern)
+	}
+
+	generated, err := svg.GenerateComponents(files, framework, svg.DefaultOptions().WithConfig(config.SVG))
+	if err != nil {
+		core.Fatal("Failed to generate components: %v", err)
+	}
+
+	written := 0
+	keep := make(map[string]bool)
+	for _, f := range generated {
+		path := filepath.Join(outDir, f.Name)
+		keep[path] = true
+		if old, err := os.ReadFile(path); err == nil && string(old) == f.Content {
+			continue
+		}
+		if err := writeFile(path, []byte(f.Content)); err != nil {
+			core.Fatal("Failed to write %s: %v", path, err)
+		}
+		written++
+	}
+
+	removed := 0
+	entries, _ := os.ReadDir(outDir)
+	for _, e := range entries {
+		path := filepath.Join(outDir, e.Name())
+		if e.IsDir() || keep[path] {
+			continue
+		}
+		if data, err := os.ReadFile(path); err == nil && isGenerated(data) {
+			if err := os.Remove(path); err != nil {
+				core.Fatal("Failed to remove %s: %v", path, err)
+			}
+			removed++
+		}
+	}
+
+	core.Success("%d %s components in %s (%d written, %d removed)", len(generated)-1, framework, outDir, written, removed)
+}
+
+// RunSVGPalette checks the colors of the SVG files matching pattern against
+// the design tokens of paletteFile, or of the svg config's palette if it is
+// empty, and prints which files use which colors. It fails if colors are
+// off the palette, unless fix replaces them with the nearest token.
+func RunSVGPalette(pattern, paletteFile string, fix svg.PaletteFix, format string) {
+	config, err := core.GetConfig()
+	if err != nil {
+		core.Fatal("Failed to load config: %v", err)
+	}
+	if paletteFile == "" {
+		paletteFile = config.SVG.Palette
+	}
+	if paletteFile == "" {
+		core.Fatal("No palette: set svg.palette in the config or pass --palette")
+	}
+	palette, err := svg.LoadPalette(paletteFile)
+	if err != nil {
+		core.Fatal("Failed to load palette: %v", err)
+	}
+	files, err := core.ResolveFiles(pattern)
+	if err != nil {
+		core.Fatal("Failed to resolve %s: %v", pattern, err)
+	}
+	if len(files) == 0 {
+		core.Fatal("No files match %s", pattern)
+	}
+
+	report, err := svg.CheckPalette(files, palette, fix)
+	if err != nil {
+		core.Fatal("Failed to check colors: %v", err)
+	}
+	switch format {
+	case "table":
+		printPalette(report, len(palette), paletteFile)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(report); err != nil {
+			core.Fatal("Failed to write report: %v", err)
+		}
+	default:
+		core.Fatal("Unknown format %q (expected table or json)", format)
+	}
+
+	if off := report.OffPalette(); len(off) > 0 {
+		core.Fatal("%d colors are not on the palette", len(off))
+	}
+	if format != "table" {
+		return
+	}
+	replaced := 0
+	for _, u := range report.Colors {
+		if u.Replaced != "" {
+			replaced++
+		}
+	}
+	if replaced > 0 {
+		core.Success("All %d colors of %d SVG files are on the palette, %d replaced", len(report.Colors), len(files), replaced)
+	} else {
+		core.Success("All %d colors of %d SVG files are on the palette", len(report.Colors), len(files))
+	}
+}
+
+// printPalette prints the colors of a palette report, each with a swatch
+// and the token it is or is closest to, then the colors of each file.
+func printPalette(r svg.PaletteReport, tokens int, paletteFile string) {
+	fmt.Printf("\n%s %s\n", core.Bold.Render("Colors"),
+		core.Subtle.Render(fmt.Sprintf("(%d tokens from %s)", tokens, paletteFile)))
+	name := make(map[string]string)
+	for _, u := range r.Colors {
+		swatch := "  "
+		if c, ok := svg.ParseColor(u.Value); ok {
+			swatch = lipgloss.NewStyle().Foreground(lipgloss.Color(c.Hex()[:7])).Render("██")
+		}
+		var match string
+		switch {
+		case u.OnPalette():
+			match = core.Green.Render(u.Token)
+			name[u.Value] = u.Value + " " + core.Subtle.Render("("+u.Token+")")
+		case u.Replaced != "":
+			match = core.Yellow.Render("replaced") + core.Subtle.Render(fmt.Sprintf(" with %s (ΔE %.1f)", u.Replaced, u.Distance))
+			name[u.Value] = core.Yellow.Render(u.Value) + " " + core.Subtle.Render("→ "+u.Replaced)
+		case u.Nearest != "":
+			match = core.Red.Render("off palette") + core.Subtle.Render(fmt.Sprintf(", nearest %s (ΔE %.1f)", u.Nearest, u.Distance))
+			name[u.Value] = core.Red.Render(u.Value)
+		default:
+			match = core.Red.Render("not a color the palette can match")
+			name[u.Value] = core.Red.Render(u.Value)
+		}
+		files := fmt.Sprintf("· %d files", len(u.Files))
+		if len(u.Files) == 1 {
+			files = "· 1 file"
+		}
+		fmt.Printf("  %s %-24s %s %s\n", swatch, u.Value, match, core.Subtle.Render(files))
+	}
+	if len(r.Colors) == 0 {
+		fmt.Println("  " + core.Subtle.Render("no colors"))
+	}
+
+	fmt.Printf("\n%s\n", core.Bold.Render("Icons"))
+	for _, icon := range r.Icons {
+		colors := make([]string, len(icon.Colors))
+		for i, v := range icon.Colors {
+			colors[i] = name[v]
+		}
+		if len(colors) == 0 {
+			colors = []string{core.Subtle.Render("no colors")}
+		}
+		fmt.Printf("  %s %s\n", icon.File, strings.Join(colors, ", "))
+	}
+	fmt.Println()
+}
+
+// isGenerated reports whether a file was written by RunSVGComponents,
+// which puts a header comment at its top.
+func isGenerated(data []byte) bool {
+	return bytes.Contains(data[:min(len(data), 200)], []byte(svg.GeneratedHeader))
+}
+
+// writeFile writes data to path, creating its directory if needed.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
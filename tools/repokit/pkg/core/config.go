@@ -31,32 +31,91 @@ type TaskConfig struct {
 	PostRun         []string `yaml:"post_run,omitempty" json:"post_run,omitempty" description:"Tasks to run after this one."`
 	Parallel        bool     `yaml:"parallel,omitempty" json:"parallel,omitempty" default:"false" description:"Run child tasks in parallel."`
 	Workers         int      `yaml:"workers,omitempty" json:"workers,omitempty" default:"3" description:"Number of parallel workers."`
-	ContinueOnError bool     `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty" default:"false" description:"Continue execution even if child tasks fail."`
+	ContinueOnError bool     `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty" default:"false" description:"Continue execution even if child tasks fail. Shorthand for on_failure: continue."`
+	OnFailure       string   `yaml:"on_failure,omitempty" json:"on_failure,omitempty" enum:"fail_fast,finish_running,continue" default:"fail_fast" description:"What a parallel batch does when a child fails: terminate running siblings, let them finish, or keep starting new ones."`
+	LogFile         string   `yaml:"log_file,omitempty" json:"log_file,omitempty" description:"Capture the task's combined output to this file, relative to the run directory .repokit/runs/<run-id>."`
+	Artifacts       []string `yaml:"artifacts,omitempty" json:"artifacts,omitempty" description:"Globs, relative to cwd, of files or directories copied to .repokit/runs/<run-id>/artifacts after the task succeeds."`
+	Locks           []string `yaml:"locks,omitempty" json:"locks,omitempty" description:"Named resources this task holds while running. Tasks sharing a lock never run at the same time within a parallel batch."`
+	Slots           int      `yaml:"slots,omitempty" json:"slots,omitempty" default:"1" minimum:"1" description:"Number of worker slots the task occupies in a parallel batch (capped at the batch's workers)."`
+	ProblemMatchers []string `yaml:"problem_matchers,omitempty" json:"problem_matchers,omitempty" description:"Matchers extracting diagnostics from the task's output: built-ins (eslint, tsc, go_vet, go_test, knip) or names defined under problem_matchers."`
 	Interactive     bool     `yaml:"interactive,omitempty" json:"interactive,omitempty" default:"false" description:"Run in interactive mode (attaches stdin/stdout)."`
 }
 
+// Failure policies for parallel batches.
+const (
+	FailFast      = "fail_fast"
+	FinishRunning = "finish_running"
+	Continue      = "continue"
+)
+
+// FailurePolicy returns the effective on_failure policy of a batch.
+func (t TaskConfig) FailurePolicy() string {
+	switch {
+	case t.OnFailure != "":
+		return t.OnFailure
+	case t.ContinueOnError:
+		return Continue
+	default:
+		return FailFast
+	}
+}
+
+// IsPipeline reports whether a task runs other tasks rather than a command.
+func (t TaskConfig) IsPipeline() bool {
+	return t.Type == "batch" || t.Type == "sequential" || len(t.Tasks) > 0
+}
+
 type BatchConfig = TaskConfig
 
 type Config struct {
-	_     struct{}              `additionalProperties:"false"`
-	Vars  map[string]string     `yaml:"vars" json:"vars" description:"Global variables for command and path interpolation."`
-	Tasks map[string]TaskConfig `yaml:"tasks" json:"tasks" required:"true" description:"Task definitions (Atomic or Pipeline)."`
+	_               struct{}                  `additionalProperties:"false"`
+	Vars            map[string]string         `yaml:"vars" json:"vars" description:"Global variables for command and path interpolation."`
+	Notify          NotifyConfig              `yaml:"notify,omitempty" json:"notify,omitempty" description:"Notifications for long-running pipelines."`
+	ProblemMatchers map[string]ProblemMatcher `yaml:"problem_matchers,omitempty" json:"problem_matchers,omitempty" description:"Custom problem matchers, referenced by name from tasks. A matcher named like a built-in replaces it."`
+	SVG             SVGConfig                 `yaml:"svg,omitempty" json:"svg,omitempty" description:"Passes and settings of the SVG optimizer (optimize_svg)."`
+	Tasks           map[string]TaskConfig     `yaml:"tasks" json:"tasks" required:"true" description:"Task definitions (Atomic or Pipeline)."`
 }
 
 func (c *Config) Validate() error {
-	var NativeCommands = map[string]bool{
-		"export_schema": true,
-		"pack":          true,
-		"optimize_svg":  true,
-		"help":          true,
+	if _, err := c.Notify.ThresholdDuration(); err != nil {
+		return err
+	}
+
+	if err := c.SVG.Validate(); err != nil {
+		return err
+	}
+
+	for name, m := range c.ProblemMatchers {
+		if _, err := compileMatcher(m); err != nil {
+			return fmt.Errorf("problem matcher %q: %w", name, err)
+		}
 	}
 
 	for name := range c.Tasks {
 		task := c.Tasks[name]
+		for _, matcher := range task.ProblemMatchers {
+			if _, ok := c.LookupMatcher(matcher); !ok {
+				return fmt.Errorf("task %q uses unknown problem matcher %q", name, matcher)
+			}
+		}
+		switch task.OnFailure {
+		case "", FailFast, FinishRunning, Continue:
+		default:
+			return fmt.Errorf("task %q has invalid on_failure %q (expected %s, %s or %s)", name, task.OnFailure, FailFast, FinishRunning, Continue)
+		}
+		if task.Slots < 0 {
+			return fmt.Errorf("task %q has negative slots %d", name, task.Slots)
+		}
+		for _, lock := range task.Locks {
+			if lock == "" {
+				return fmt.Errorf("task %q has an empty lock name", name)
+			}
+		}
+
 		// Validating batch/sequential task dependencies
-		if task.Type == "batch" || task.Type == "sequential" {
+		if task.IsPipeline() {
 			for _, subTask := range task.Tasks {
-				if _, ok := c.Tasks[subTask]; !ok && !NativeCommands[subTask] {
+				if _, ok := c.Tasks[subTask]; !ok && !isNative(subTask) {
 					return fmt.Errorf("task %q depends on non-existent task %q", name, subTask)
 				}
 			}
@@ -64,12 +123,12 @@ func (c *Config) Validate() error {
 
 		// Validating hook dependencies
 		for _, hook := range task.PreRun {
-			if _, ok := c.Tasks[hook]; !ok && !NativeCommands[hook] {
+			if _, ok := c.Tasks[hook]; !ok && !isNative(hook) {
 				return fmt.Errorf("task %q has non-existent pre_run hook %q", name, hook)
 			}
 		}
 		for _, hook := range task.PostRun {
-			if _, ok := c.Tasks[hook]; !ok && !NativeCommands[hook] {
+			if _, ok := c.Tasks[hook]; !ok && !isNative(hook) {
 				return fmt.Errorf("task %q has non-existent post_run hook %q", name, hook)
 			}
 		}
@@ -103,11 +162,19 @@ func GetTaskByID(id string) (TaskConfig, error) {
 	}
 	task, ok := config.Tasks[id]
 	if !ok {
+		if c, ok := LookupNative(id); ok {
+			return NativeTask(c), nil
+		}
 		return TaskConfig{}, fmt.Errorf("task %q not found", id)
 	}
 	mapper := func(key string) string { return config.Vars[key] }
 	task.Command = os.Expand(task.Command, mapper)
 	task.Cwd = os.Expand(task.Cwd, mapper)
+	task.LogFile = os.Expand(task.LogFile, mapper)
+	task.Artifacts = append([]string(nil), task.Artifacts...)
+	for i, a := range task.Artifacts {
+		task.Artifacts[i] = os.Expand(a, mapper)
+	}
 	return task, nil
 }
 
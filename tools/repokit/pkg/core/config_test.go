@@ -84,3 +84,44 @@ func TestEnsureCommandExists(t *testing.T) {
 		t.Error("EnsureCommandExists('non-existent-command-xyz') returned true, expected false")
 	}
 }
+
+func TestFailurePolicy(t *testing.T) {
+	tests := []struct {
+		task TaskConfig
+		want string
+	}{
+		{TaskConfig{}, FailFast},
+		{TaskConfig{ContinueOnError: true}, Continue},
+		{TaskConfig{OnFailure: FinishRunning, ContinueOnError: true}, FinishRunning},
+	}
+	for _, tt := range tests {
+		if got := tt.task.FailurePolicy(); got != tt.want {
+			t.Errorf("FailurePolicy() of %+v = %q, want %q", tt.task, got, tt.want)
+		}
+	}
+
+	c := Config{Tasks: map[string]TaskConfig{"lint": {Type: "batch", OnFailure: "retry"}}}
+	if err := c.Validate(); err == nil {
+		t.Error("expected error for invalid on_failure")
+	}
+}
+
+func TestSVGConfig_Validate(t *testing.T) {
+	one, twelve := 1.0, 12.0
+	valid := SVGConfig{ViewBox: "0 0 24 24", Padding: &one}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := []SVGConfig{
+		{ViewBox: "0 0 24"},
+		{ViewBox: "0 0 24 -1"},
+		{ViewBox: "0 0 24 24", Padding: &twelve},
+		{Overrides: []SVGOverride{{Files: "*.svg", ViewBox: "a b c d"}}},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+}
@@ -7,24 +7,73 @@ type EventType string
 const (
 	EventTaskStart    EventType = "task_start"
 	EventTaskLog      EventType = "task_log"
+	EventTaskUsage    EventType = "task_usage"
 	EventTaskDone     EventType = "task_done"
 	EventTaskError    EventType = "task_error"
+	EventTaskLogFile  EventType = "task_log_file" // Data is the path of the task's captured output
+	EventTaskArtifact EventType = "task_artifact" // Data is the path of a collected artifact
+	EventTaskProblem  EventType = "task_problem"  // Problem is a diagnostic found in the output
 	EventPipelineDone EventType = "pipeline_done"
 )
 
 type Event struct {
-	Type   EventType
-	TaskID string
-	Data   string
-	Time   time.Time
+	Type    EventType
+	TaskID  string
+	Data    string
+	Usage   *ResourceUsage
+	Problem *Problem
+	Time    time.Time
+
+	// Set on EventTaskLog: the stream the line was written to, and whether it
+	// is an in-place update ending in \r that the next line of the stream replaces.
+	Stream  Stream
+	Partial bool
+}
+
+// Stream identifies the output stream of a log line.
+type Stream string
+
+const (
+	StreamStdout Stream = "stdout"
+	StreamStderr Stream = "stderr"
+)
+
+// ResourceUsage is a snapshot of the CPU and memory consumed by a task's whole process group.
+type ResourceUsage struct {
+	CPUPercent float64       // CPU utilisation since the previous sample (100 = one core).
+	RSS        uint64        // Resident memory of all group members, in bytes.
+	PeakRSS    uint64        // Highest RSS observed so far.
+	CPUTime    time.Duration // Cumulative user + system time.
 }
 
 var EventBus = make(chan Event, 5000)
 
 func PublishEvent(t EventType, taskID string, data string) {
+	publish(Event{Type: t, TaskID: taskID, Data: data, Time: time.Now()})
+}
+
+// PublishLog reports a line of task output from the given stream.
+func PublishLog(taskID string, stream Stream, line string, partial bool) {
+	publish(Event{Type: EventTaskLog, TaskID: taskID, Data: line, Stream: stream, Partial: partial, Time: time.Now()})
+}
+
+// PublishProblem reports a diagnostic extracted from a task's output.
+func PublishProblem(taskID string, p Problem) {
+	publish(Event{Type: EventTaskProblem, TaskID: taskID, Problem: &p, Time: time.Now()})
+}
+
+// PublishUsage reports a resource usage sample for a running task.
+func PublishUsage(taskID string, usage ResourceUsage) {
+	publish(Event{Type: EventTaskUsage, TaskID: taskID, Usage: &usage, Time: time.Now()})
+}
+
+func publish(e Event) {
+	if r := activeRecorder.Load(); r != nil {
+		r.Observe(e)
+	}
 	if TuiMode {
 		select {
-		case EventBus <- Event{Type: t, TaskID: taskID, Data: data, Time: time.Now()}:
+		case EventBus <- e:
 		default:
 			// Buffer full, drop to avoid blocking execution
 		}
@@ -51,17 +51,7 @@ func ResolveFiles(pattern string) ([]string, error) {
 	}
 	base = filepath.FromSlash(base) // Convert base to system-native path for filepath.Walk
 
-	// Build regex:
-	// Escape the whole pattern first
-	regStr := regexp.QuoteMeta(pattern)
-	// Replace escaped /**/ with (?:/.*/)? to make it truly optional
-	regStr = strings.ReplaceAll(regStr, "/\\*\\*/", "/(?:.*/)?")
-	// Cleanup any remaining ** or *
-	regStr = strings.ReplaceAll(regStr, "\\*\\*", ".*")
-	regStr = strings.ReplaceAll(regStr, "\\*", "[^/]*")
-	regStr = "^" + regStr + "$"
-
-	re, err := regexp.Compile(regStr)
+	re, err := globRegexp(pattern)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +73,43 @@ func ResolveFiles(pattern string) ([]string, error) {
 	return matches, err
 }
 
+// globRegexp compiles a slash-separated pattern containing double
+// asterisks into a regular expression matching whole paths.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	// Build regex:
+	// Escape the whole pattern first
+	regStr := regexp.QuoteMeta(pattern)
+	// Replace escaped /**/ with (?:/.*/)? to make it truly optional
+	regStr = strings.ReplaceAll(regStr, "/\\*\\*/", "/(?:.*/)?")
+	// Cleanup any remaining ** or *
+	regStr = strings.ReplaceAll(regStr, "\\*\\*", ".*")
+	regStr = strings.ReplaceAll(regStr, "\\*", "[^/]*")
+	regStr = "^" + regStr + "$"
+	return regexp.Compile(regStr)
+}
+
+// MatchGlob reports whether path matches a pattern as ResolveFiles would
+// find it. Relative patterns and paths are resolved against the working
+// directory.
+func MatchGlob(pattern, path string) (bool, error) {
+	absPattern, err := filepath.Abs(pattern)
+	if err != nil {
+		return false, err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	if !strings.Contains(absPattern, "**") {
+		return filepath.Match(absPattern, absPath)
+	}
+	re, err := globRegexp(filepath.ToSlash(absPattern))
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(filepath.ToSlash(absPath)), nil
+}
+
 var knownTextExts = map[string]bool{
 	".js": true, ".ts": true, ".jsx": true, ".tsx": true,
 	".json": true, ".html": true, ".css": true, ".scss": true,
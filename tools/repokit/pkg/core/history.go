@@ -0,0 +1,374 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const maxHistoryRuns = 200
+
+// HistoryDir is where run history and per-run data are stored. Like the
+// paths of tasks, it is relative to the working directory, which repokit
+// expects to be the repository root.
+var HistoryDir = ".repokit"
+
+// RunRecord is the persisted summary of one top-level run.
+type RunRecord struct {
+	ID       string        `json:"id"`
+	Task     string        `json:"task"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Tasks    []TaskRecord  `json:"tasks"`
+}
+
+// TaskRecord is the outcome of a single task within a run.
+type TaskRecord struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Status  string        `json:"status"`
+	Start   time.Time     `json:"start"`
+	Elapsed time.Duration `json:"elapsed"`
+	PeakRSS uint64        `json:"peak_rss,omitempty"`
+	CPUTime time.Duration `json:"cpu_time,omitempty"`
+
+	LogFile   string    `json:"log_file,omitempty"`
+	Artifacts []string  `json:"artifacts,omitempty"`
+	Problems  []Problem `json:"problems,omitempty"`
+}
+
+func historyPath() string {
+	return filepath.Join(HistoryDir, "history.json")
+}
+
+// RunIDEnv passes the current run ID to nested repokit processes so that
+// their logs and artifacts land in the same run directory.
+const RunIDEnv = "REPOKIT_RUN_ID"
+
+// CurrentRunID returns the ID of the run being recorded by this process or,
+// in a nested process, by its parent. It is empty outside of a recorded run.
+func CurrentRunID() string {
+	if r := activeRecorder.Load(); r != nil {
+		return r.run.ID
+	}
+	return os.Getenv(RunIDEnv)
+}
+
+// RunDir returns the directory holding the logs and artifacts of a run.
+func RunDir(id string) string {
+	return filepath.Join(HistoryDir, "runs", id)
+}
+
+// LoadHistory returns all recorded runs, oldest first.
+func LoadHistory() ([]RunRecord, error) {
+	data, err := os.ReadFile(historyPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var runs []RunRecord
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse run history: %w", err)
+	}
+	return runs, nil
+}
+
+// AppendHistory records a finished run, keeping only the most recent runs.
+// Concurrent repokit processes append in turn under a lock on the history,
+// which is replaced by a rename so that readers never see it half written.
+func AppendHistory(run RunRecord) error {
+	if err := os.MkdirAll(HistoryDir, 0755); err != nil {
+		return err
+	}
+	unlock, err := lockHistory()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	runs, err := LoadHistory()
+	if err != nil {
+		return err
+	}
+	runs = append(runs, run)
+	if len(runs) > maxHistoryRuns {
+		runs = runs[len(runs)-maxHistoryRuns:]
+	}
+
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(HistoryDir, "history-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), historyPath())
+}
+
+// lockHistory takes an exclusive lock on the history, waiting for other
+// processes to release theirs, and returns the function releasing it.
+func lockHistory() (func(), error) {
+	f, err := os.OpenFile(historyPath()+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock run history: %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// FindRun looks up a run by ID. An empty ID or "last" selects the most
+// recent run; otherwise the ID may be a unique prefix.
+func FindRun(id string) (RunRecord, error) {
+	runs, err := LoadHistory()
+	if err != nil {
+		return RunRecord{}, err
+	}
+	if len(runs) == 0 {
+		return RunRecord{}, fmt.Errorf("no runs recorded yet")
+	}
+	if id == "" || id == "last" {
+		return runs[len(runs)-1], nil
+	}
+
+	var found []RunRecord
+	for _, r := range runs {
+		if r.ID == id {
+			return r, nil
+		}
+		if strings.HasPrefix(r.ID, id) {
+			found = append(found, r)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return RunRecord{}, fmt.Errorf("run %q not found", id)
+	case 1:
+		return found[0], nil
+	default:
+		return RunRecord{}, fmt.Errorf("run ID %q is ambiguous (%d matches)", id, len(found))
+	}
+}
+
+// ─── Recorder ────────────────────────────────────────────────────────────────
+
+// Recorder builds a RunRecord from the events published during a run.
+type Recorder struct {
+	mu    sync.Mutex
+	run   RunRecord
+	index map[string]int
+	// joined is set for a process that a recorded run started, whose
+	// tasks are saved for that run to merge rather than as a run of their own.
+	joined bool
+}
+
+var activeRecorder atomic.Pointer[Recorder]
+
+// StartRecording begins recording the events of a top-level run of task.
+// Run IDs are the start time followed by a random suffix, so that runs
+// started in the same second by different processes get their own ID and
+// run directory.
+func StartRecording(task string) *Recorder {
+	now := time.Now()
+	id := fmt.Sprintf("%s-%04x", now.Format("20060102-150405"), rand.IntN(0x10000))
+	r := &Recorder{
+		run:   RunRecord{ID: id, Task: task, Start: now},
+		index: make(map[string]int),
+	}
+	activeRecorder.Store(r)
+	return r
+}
+
+// JoinRecording begins recording the events of a process started by the
+// tasks of a recorded run, whose ID it inherits through RunIDEnv. The
+// process saves its tasks with SaveNested, and the run that started it
+// merges them into its record when it finishes.
+func JoinRecording(task string) *Recorder {
+	r := &Recorder{
+		run:    RunRecord{ID: os.Getenv(RunIDEnv), Task: task, Start: time.Now()},
+		index:  make(map[string]int),
+		joined: true,
+	}
+	activeRecorder.Store(r)
+	return r
+}
+
+func nestedDir(id string) string {
+	return filepath.Join(RunDir(id), "nested")
+}
+
+// SaveNested writes the record of a joined process to its run's directory.
+// It is written under a temporary name and renamed, so that the run never
+// merges a record half written.
+func SaveNested(run RunRecord) error {
+	dir := nestedDir(run.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".record-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	name := strings.TrimPrefix(filepath.Base(tmp.Name()), ".") + ".json"
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+// Observe folds an event into the run record.
+func (r *Recorder) Observe(e Event) {
+	if e.TaskID == "" || e.TaskID == "pipeline" || e.Type == EventTaskLog {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx, ok := r.index[e.TaskID]
+	if !ok {
+		idx = len(r.run.Tasks)
+		r.index[e.TaskID] = idx
+		r.run.Tasks = append(r.run.Tasks, TaskRecord{ID: e.TaskID, Name: e.TaskID, Status: "running", Start: e.Time})
+	}
+	t := &r.run.Tasks[idx]
+
+	switch e.Type {
+	case EventTaskStart:
+		if e.Data != "" {
+			t.Name = e.Data
+		}
+	case EventTaskUsage:
+		if e.Usage != nil {
+			t.PeakRSS = max(t.PeakRSS, e.Usage.PeakRSS)
+			t.CPUTime = max(t.CPUTime, e.Usage.CPUTime)
+		}
+	case EventTaskDone:
+		t.Status = "done"
+		t.Elapsed = e.Time.Sub(t.Start)
+	case EventTaskError:
+		t.Status = "error"
+		if strings.HasPrefix(e.Data, "cancelled") {
+			t.Status = "cancelled"
+		}
+		t.Elapsed = e.Time.Sub(t.Start)
+	case EventTaskLogFile:
+		t.LogFile = e.Data
+	case EventTaskArtifact:
+		t.Artifacts = append(t.Artifacts, e.Data)
+	case EventTaskProblem:
+		if e.Problem != nil {
+			t.Problems = append(t.Problems, *e.Problem)
+		}
+	case EventTaskLog, EventPipelineDone:
+	}
+}
+
+// Finish stops recording and returns the completed run. A non-empty failure
+// marks the run as failed even if no individual task reported an error.
+func (r *Recorder) Finish(failure string) RunRecord {
+	activeRecorder.CompareAndSwap(r, nil)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.run.Duration = time.Since(r.run.Start)
+	r.run.Status = "done"
+	r.run.Error = failure
+	if failure != "" {
+		r.run.Status = "error"
+	}
+	if !r.joined {
+		r.mergeNested()
+	}
+	for _, t := range r.run.Tasks {
+		if t.Status == "error" {
+			r.run.Status = "error"
+		}
+	}
+	return r.run
+}
+
+// mergeNested adds the tasks recorded by the processes the run started.
+// Their log files, artifacts and problems go to the task of the same ID
+// if the run has one, such as the task a nested process ran; other tasks
+// are added after the run's own.
+func (r *Recorder) mergeNested() {
+	if r.run.ID == "" {
+		return
+	}
+	dir := nestedDir(r.run.ID)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	sort.Strings(files)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var nested RunRecord
+		if err := json.Unmarshal(data, &nested); err != nil {
+			continue
+		}
+		for _, t := range nested.Tasks {
+			idx, ok := r.index[t.ID]
+			if !ok {
+				r.index[t.ID] = len(r.run.Tasks)
+				r.run.Tasks = append(r.run.Tasks, t)
+				continue
+			}
+			own := &r.run.Tasks[idx]
+			if own.LogFile == "" {
+				own.LogFile = t.LogFile
+			}
+			for _, a := range t.Artifacts {
+				if !slices.Contains(own.Artifacts, a) {
+					own.Artifacts = append(own.Artifacts, a)
+				}
+			}
+			for _, p := range t.Problems {
+				if !slices.Contains(own.Problems, p) {
+					own.Problems = append(own.Problems, p)
+				}
+			}
+		}
+	}
+	_ = os.RemoveAll(dir)
+}
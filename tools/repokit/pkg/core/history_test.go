@@ -0,0 +1,153 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	r := StartRecording("all")
+	start := time.Now()
+	publish(Event{Type: EventTaskStart, TaskID: "lint", Data: "Lint", Time: start})
+	publish(Event{Type: EventTaskStart, TaskID: "test", Time: start})
+	publish(Event{Type: EventTaskUsage, TaskID: "lint", Usage: &ResourceUsage{PeakRSS: 1024, CPUTime: time.Second}, Time: start})
+	publish(Event{Type: EventTaskDone, TaskID: "lint", Time: start.Add(2 * time.Second)})
+	publish(Event{Type: EventTaskError, TaskID: "test", Data: "boom", Time: start.Add(3 * time.Second)})
+	run := r.Finish("")
+
+	// Events after Finish are no longer recorded.
+	publish(Event{Type: EventTaskStart, TaskID: "late", Time: start})
+
+	if run.Task != "all" || run.Status != "error" || len(run.Tasks) != 2 {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+	lint := run.Tasks[0]
+	if lint.Name != "Lint" || lint.Status != "done" || lint.Elapsed != 2*time.Second || lint.PeakRSS != 1024 {
+		t.Errorf("unexpected lint record: %+v", lint)
+	}
+	if run.Tasks[1].Status != "error" || run.Tasks[1].Elapsed != 3*time.Second {
+		t.Errorf("unexpected test record: %+v", run.Tasks[1])
+	}
+}
+
+func TestHistory(t *testing.T) {
+	original := HistoryDir
+	HistoryDir = t.TempDir()
+	defer func() { HistoryDir = original }()
+
+	if _, err := FindRun(""); err == nil {
+		t.Error("expected error with empty history")
+	}
+
+	for _, id := range []string{"20260101-120000", "20260101-130000", "20260102-090000"} {
+		if err := AppendHistory(RunRecord{ID: id, Task: "build"}); err != nil {
+			t.Fatalf("AppendHistory() error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"", "20260102-090000", false},
+		{"last", "20260102-090000", false},
+		{"20260101-13", "20260101-130000", false},
+		{"20260101", "", true},
+		{"2027", "", true},
+	}
+	for _, tt := range tests {
+		run, err := FindRun(tt.query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("FindRun(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if run.ID != tt.want {
+			t.Errorf("FindRun(%q) = %q, want %q", tt.query, run.ID, tt.want)
+		}
+	}
+}
+
+func TestAppendHistory_Concurrent(t *testing.T) {
+	original := HistoryDir
+	HistoryDir = t.TempDir()
+	defer func() { HistoryDir = original }()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := AppendHistory(RunRecord{ID: fmt.Sprintf("run-%02d", i), Task: "build"}); err != nil {
+				t.Errorf("AppendHistory() error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	runs, err := LoadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 20 {
+		t.Errorf("expected every concurrent append to be kept, got %d runs", len(runs))
+	}
+}
+
+func TestStartRecording_UniqueIDs(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 50 {
+		r := StartRecording("build")
+		id := r.Finish("").ID
+		if len(id) != len("20060102-150405-0000") {
+			t.Fatalf("unexpected run ID %q", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) < 45 {
+		t.Errorf("expected runs started in the same second to get distinct IDs, got %d of 50", len(seen))
+	}
+}
+
+func TestRecorder_MergesNested(t *testing.T) {
+	original := HistoryDir
+	HistoryDir = t.TempDir()
+	defer func() { HistoryDir = original }()
+
+	parent := StartRecording("all")
+	start := time.Now()
+	publish(Event{Type: EventTaskStart, TaskID: "build", Time: start})
+	publish(Event{Type: EventTaskDone, TaskID: "build", Time: start.Add(time.Second)})
+
+	// The nested process running build records what its tasks produced.
+	t.Setenv(RunIDEnv, parent.run.ID)
+	child := JoinRecording("build")
+	if CurrentRunID() != parent.run.ID {
+		t.Fatalf("expected the nested process to share run %s, got %s", parent.run.ID, CurrentRunID())
+	}
+	publish(Event{Type: EventTaskStart, TaskID: "build", Time: start})
+	publish(Event{Type: EventTaskArtifact, TaskID: "build", Data: "dist/app", Time: start})
+	publish(Event{Type: EventTaskStart, TaskID: "compile", Time: start})
+	publish(Event{Type: EventTaskLogFile, TaskID: "compile", Data: "logs/compile.log", Time: start})
+	publish(Event{Type: EventTaskDone, TaskID: "compile", Time: start})
+	if err := SaveNested(child.Finish("")); err != nil {
+		t.Fatal(err)
+	}
+
+	run := parent.Finish("")
+	if len(run.Tasks) != 2 {
+		t.Fatalf("expected build and the nested compile task, got %+v", run.Tasks)
+	}
+	if build := run.Tasks[0]; build.Status != "done" || len(build.Artifacts) != 1 || build.Artifacts[0] != "dist/app" {
+		t.Errorf("expected the nested artifact on build, got %+v", build)
+	}
+	if compile := run.Tasks[1]; compile.ID != "compile" || compile.LogFile != "logs/compile.log" {
+		t.Errorf("expected the nested compile task with its log, got %+v", compile)
+	}
+	if _, err := os.Stat(nestedDir(run.ID)); !os.IsNotExist(err) {
+		t.Errorf("expected the nested records to be removed once merged, got %v", err)
+	}
+}
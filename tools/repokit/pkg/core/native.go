@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ─── Native Commands ─────────────────────────────────────────────────────────
+
+// NativeCommand is a command implemented in Go rather than as a shell
+// command in tasks.yaml. Registered commands can be referenced as tasks, get
+// a CLI subcommand with flags generated from their params, and appear in the
+// TUI with a parameter form.
+type NativeCommand interface {
+	ID() string
+	Name() string
+	Description() string
+	Params() []Param
+	Run(ctx context.Context, params Params) (Result, error)
+}
+
+// Param describes one input of a native command.
+type Param struct {
+	Name        string
+	Description string
+	Default     string
+	// Options restricts the value to one of the listed choices.
+	Options []string
+	// Bool params take "true" or "false" and become boolean flags.
+	Bool bool
+	// Positional params are passed as arguments on the command line instead
+	// of flags. They follow the order in which they are declared.
+	Positional bool
+	// Shorthand is the one-letter flag alias.
+	Shorthand string
+}
+
+// Params holds the resolved values of a native command's params by name.
+type Params map[string]string
+
+// Bool returns the value of a boolean param.
+func (p Params) Bool(name string) bool {
+	b, _ := strconv.ParseBool(p[name])
+	return b
+}
+
+// Result is what a native command produced: a one-line summary for the
+// log and the files it wrote, which are collected as run artifacts.
+type Result struct {
+	Summary string
+	Outputs []string
+}
+
+var natives = struct {
+	mu   sync.RWMutex
+	byID map[string]NativeCommand
+}{byID: make(map[string]NativeCommand)}
+
+// RegisterNative adds a native command to the registry. It is meant to be
+// called from init functions and panics on an empty or duplicate ID.
+func RegisterNative(c NativeCommand) {
+	natives.mu.Lock()
+	defer natives.mu.Unlock()
+	id := c.ID()
+	if id == "" {
+		panic("native command with empty ID")
+	}
+	if _, dup := natives.byID[id]; dup {
+		panic(fmt.Sprintf("native command %q registered twice", id))
+	}
+	natives.byID[id] = c
+}
+
+// LookupNative returns the native command registered under id.
+func LookupNative(id string) (NativeCommand, bool) {
+	natives.mu.RLock()
+	defer natives.mu.RUnlock()
+	c, ok := natives.byID[id]
+	return c, ok
+}
+
+// NativeCommands returns every registered native command, sorted by ID.
+func NativeCommands() []NativeCommand {
+	natives.mu.RLock()
+	defer natives.mu.RUnlock()
+	list := make([]NativeCommand, 0, len(natives.byID))
+	for _, c := range natives.byID {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID() < list[j].ID() })
+	return list
+}
+
+// NativeTask describes a native command as a task, for code that looks
+// tasks up by ID. Its type is "native", which only exists at run time.
+func NativeTask(c NativeCommand) TaskConfig {
+	return TaskConfig{
+		Name:        c.Name(),
+		Type:        "native",
+		Description: c.Description(),
+		Cwd:         ".",
+	}
+}
+
+// ResolveParams validates the given values against a command's params and
+// fills in the defaults of the missing ones.
+func ResolveParams(c NativeCommand, given map[string]string) (Params, error) {
+	declared := c.Params()
+	resolved := make(Params, len(declared))
+	for name := range given {
+		if !slices.ContainsFunc(declared, func(p Param) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("%s has no parameter %q", c.ID(), name)
+		}
+	}
+	for _, p := range declared {
+		v, ok := given[p.Name]
+		if !ok || v == "" {
+			v = p.Default
+		}
+		if p.Bool && v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %q of %s must be true or false, got %q", p.Name, c.ID(), v)
+			}
+			v = strconv.FormatBool(b)
+		}
+		if len(p.Options) > 0 && v != "" && !slices.Contains(p.Options, v) {
+			return nil, fmt.Errorf("parameter %q of %s must be one of %v, got %q", p.Name, c.ID(), p.Options, v)
+		}
+		resolved[p.Name] = v
+	}
+	return resolved, nil
+}
+
+// isNative reports whether id names a registered native command.
+func isNative(id string) bool {
+	_, ok := LookupNative(id)
+	return ok
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+type fakeNative struct {
+	id     string
+	params []Param
+}
+
+func (f fakeNative) ID() string          { return f.id }
+func (f fakeNative) Name() string        { return "Fake " + f.id }
+func (f fakeNative) Description() string { return "A fake native command" }
+func (f fakeNative) Params() []Param     { return f.params }
+func (f fakeNative) Run(ctx context.Context, p Params) (Result, error) {
+	return Result{}, nil
+}
+
+// registerFake registers a fake command once, so that tests can run with
+// -count greater than one.
+func registerFake(t *testing.T, f fakeNative) {
+	t.Helper()
+	if _, ok := LookupNative(f.id); !ok {
+		RegisterNative(f)
+	}
+}
+
+func TestResolveParams(t *testing.T) {
+	cmd := fakeNative{id: "fake_resolve", params: []Param{
+		{Name: "dir", Positional: true},
+		{Name: "provider", Default: "gemini", Options: []string{"gemini", "groq"}},
+		{Name: "force", Bool: true, Default: "false"},
+	}}
+
+	got, err := ResolveParams(cmd, map[string]string{"dir": "tools", "force": "1"})
+	if err != nil {
+		t.Fatalf("ResolveParams() error: %v", err)
+	}
+	if got["dir"] != "tools" || got["provider"] != "gemini" || got["force"] != "true" || !got.Bool("force") {
+		t.Errorf("unexpected params: %v", got)
+	}
+
+	for _, given := range []map[string]string{
+		{"provider": "openai"},
+		{"force": "maybe"},
+		{"unknown": "x"},
+	} {
+		if _, err := ResolveParams(cmd, given); err == nil {
+			t.Errorf("ResolveParams(%v): expected an error", given)
+		}
+	}
+}
+
+func TestNativeRegistry(t *testing.T) {
+	registerFake(t, fakeNative{id: "fake_b"})
+	registerFake(t, fakeNative{id: "fake_a"})
+
+	var ids []string
+	for _, c := range NativeCommands() {
+		ids = append(ids, c.ID())
+	}
+	if a, b := slices.Index(ids, "fake_a"), slices.Index(ids, "fake_b"); a < 0 || b < 0 || a > b {
+		t.Errorf("expected registered commands sorted by ID, got %v", ids)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected registering a duplicate ID to panic")
+			}
+		}()
+		RegisterNative(fakeNative{id: "fake_a"})
+	}()
+
+	task, err := GetTaskByID("fake_a")
+	if err != nil || task.Type != "native" || task.Name != "Fake fake_a" {
+		t.Errorf("GetTaskByID(native) = %+v, %v", task, err)
+	}
+
+	c := Config{Tasks: map[string]TaskConfig{
+		"all": {Name: "All", Type: "sequential", Tasks: []string{"fake_a"}, PreRun: []string{"fake_b"}},
+	}}
+	if err := c.Validate(); err != nil {
+		t.Errorf("expected native commands to be valid task references: %v", err)
+	}
+}
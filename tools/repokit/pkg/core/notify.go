@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+const (
+	defaultNotifyThreshold = 30 * time.Second
+	notifyCommandTimeout   = 10 * time.Second
+)
+
+// NotifyConfig controls the notification emitted when a long-running pipeline finishes.
+type NotifyConfig struct {
+	_         struct{} `additionalProperties:"false"`
+	Threshold string   `yaml:"threshold,omitempty" json:"threshold,omitempty" default:"30s" description:"Minimum run duration (e.g. 30s, 2m) before a notification is sent."`
+	Command   string   `yaml:"command,omitempty" json:"command,omitempty" description:"Optional shell command run on completion. Supports {{.Task}}, {{.Status}} and {{.Duration}}."`
+}
+
+// ThresholdDuration parses the configured threshold, falling back to the default.
+func (c NotifyConfig) ThresholdDuration() (time.Duration, error) {
+	if c.Threshold == "" {
+		return defaultNotifyThreshold, nil
+	}
+	d, err := time.ParseDuration(c.Threshold)
+	if err != nil {
+		return 0, fmt.Errorf("invalid notify threshold %q: %w", c.Threshold, err)
+	}
+	return d, nil
+}
+
+// Notification describes a finished run.
+type Notification struct {
+	Task     string
+	Failed   bool
+	Duration time.Duration
+}
+
+// Status returns "passed" or "failed".
+func (n Notification) Status() string {
+	if n.Failed {
+		return "failed"
+	}
+	return "passed"
+}
+
+// Message renders a one-line summary suitable for a desktop notification.
+func (n Notification) Message() string {
+	return fmt.Sprintf("%s %s in %s", n.Task, n.Status(), n.Duration.Round(100*time.Millisecond))
+}
+
+// notifyOutput receives the terminal escapes. Stderr is used so that the
+// sequences never interleave with the TUI renderer on stdout.
+var notifyOutput io.Writer = os.Stderr
+
+// Notify alerts the user that a run has finished when it took longer than the
+// configured threshold: it rings the terminal bell, emits OSC 9 and OSC 777
+// desktop notification escapes, and runs the user's notify command, if any.
+func Notify(n Notification) error {
+	config, err := GetConfig()
+	if err != nil {
+		return err
+	}
+	return notifyWith(config.Notify, n)
+}
+
+func notifyWith(c NotifyConfig, n Notification) error {
+	threshold, err := c.ThresholdDuration()
+	if err != nil {
+		return err
+	}
+	if n.Duration < threshold {
+		return nil
+	}
+
+	msg := n.Message()
+	// BEL, OSC 9 (iTerm2, Windows Terminal, ConEmu) and OSC 777 (urxvt, foot, Ghostty).
+	_, _ = fmt.Fprintf(notifyOutput, "\a\x1b]9;repokit: %s\x07\x1b]777;notify;repokit;%s\x07", msg, msg)
+
+	if c.Command == "" {
+		return nil
+	}
+	cmdStr, err := EvaluateCommand(c.Command, map[string]string{
+		"Task":     n.Task,
+		"Status":   n.Status(),
+		"Duration": n.Duration.Round(100 * time.Millisecond).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("notify command template error: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyCommandTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "bash", "-c", cmdStr).CombinedOutput(); err != nil {
+		return fmt.Errorf("notify command failed: %w: %s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotifyThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	original := notifyOutput
+	notifyOutput = &buf
+	defer func() { notifyOutput = original }()
+
+	cfg := NotifyConfig{Threshold: "1m"}
+
+	if err := notifyWith(cfg, Notification{Task: "lint", Duration: 10 * time.Second}); err != nil {
+		t.Fatalf("notifyWith() error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no notification below threshold, got %q", buf.String())
+	}
+
+	if err := notifyWith(cfg, Notification{Task: "all", Failed: true, Duration: 2 * time.Minute}); err != nil {
+		t.Fatalf("notifyWith() error: %v", err)
+	}
+	out := buf.String()
+	for _, s := range []string{"\a", "\x1b]9;repokit: all failed in 2m0s\x07", "\x1b]777;notify;repokit;all failed in 2m0s\x07"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected output to contain %q, got %q", s, out)
+		}
+	}
+}
+
+func TestNotifyCommand(t *testing.T) {
+	var buf bytes.Buffer
+	original := notifyOutput
+	notifyOutput = &buf
+	defer func() { notifyOutput = original }()
+
+	cfg := NotifyConfig{Threshold: "0s", Command: `test "{{.Task}} {{.Status}}" = "build passed"`}
+	if err := notifyWith(cfg, Notification{Task: "build", Duration: time.Second}); err != nil {
+		t.Errorf("expected templated notify command to succeed, got %v", err)
+	}
+
+	cfg.Command = "exit 3"
+	if err := notifyWith(cfg, Notification{Task: "build", Duration: time.Second}); err == nil {
+		t.Error("expected error from failing notify command")
+	}
+}
+
+func TestNotifyInvalidThreshold(t *testing.T) {
+	if _, err := (NotifyConfig{Threshold: "soon"}).ThresholdDuration(); err == nil {
+		t.Error("expected error for invalid threshold")
+	}
+}
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxProblemsPerTask bounds the diagnostics kept for one task, so that a
+// tool reporting thousands of issues does not flood the history.
+const maxProblemsPerTask = 500
+
+// ProblemMatcher extracts diagnostics from lines of task output. The pattern
+// uses the named groups file, line, column, severity and message; a group may
+// appear in several alternatives, and the first one that matched is used.
+type ProblemMatcher struct {
+	_           struct{} `additionalProperties:"false"`
+	Pattern     string   `yaml:"pattern" json:"pattern" required:"true" description:"Regular expression matched against each line of output, with named groups file, line, column, severity and message."`
+	FilePattern string   `yaml:"file_pattern,omitempty" json:"file_pattern,omitempty" description:"Regular expression whose file group names the file for the following matches that have none, for tools that print the file on its own line."`
+	Severity    string   `yaml:"severity,omitempty" json:"severity,omitempty" enum:"error,warning,info" default:"error" description:"Severity of matches without a severity group."`
+}
+
+// Problem is a single diagnostic reported by a task.
+type Problem struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Location formats the problem's position as file:line:column.
+func (p Problem) Location() string {
+	switch {
+	case p.File == "":
+		return ""
+	case p.Line == 0:
+		return p.File
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+}
+
+// BuiltinMatchers can be referenced by name from a task's problem_matchers
+// without being defined in the config.
+var BuiltinMatchers = map[string]ProblemMatcher{
+	// Stylish formatter: the file on its own line, then indented
+	// "line:col  severity  message  rule" rows.
+	"eslint": {
+		FilePattern: `^(?P<file>(?:/|[A-Za-z]:\\)\S.*)$`,
+		Pattern:     `^\s+(?P<line>\d+):(?P<column>\d+)\s+(?P<severity>error|warning)\s+(?P<message>.+?)(?:\s{2,}[\w@/-]+)?\s*$`,
+	},
+	// Both "file(line,col): error TS1234: msg" and the pretty
+	// "file:line:col - error TS1234: msg" form, which astro check also uses.
+	"tsc": {
+		Pattern: `^(?P<file>[^\s:(]+\.\w+)(?:\((?P<line>\d+),(?P<column>\d+)\):|:(?P<line>\d+):(?P<column>\d+) -)\s*(?P<severity>error|warning)\s+(?P<message>(?:TS\d+|ts\(\d+\)):.+)$`,
+	},
+	"go_vet": {
+		Pattern: `^(?:vet: )?(?P<file>[^\s:]+\.go):(?P<line>\d+)(?::(?P<column>\d+))?: (?P<message>.+)$`,
+	},
+	// Failures logged by t.Error and friends, plus compile errors of the package.
+	"go_test": {
+		Pattern: `^\s*(?P<file>[^\s:]+\.go):(?P<line>\d+)(?::(?P<column>\d+))?: (?P<message>.+)$`,
+	},
+	// Rows of the unused exports, types and members sections: "name  kind  file:line:col".
+	"knip": {
+		Pattern:  `^(?P<message>\S+(?:\s{2,}\w+)?)\s{2,}(?P<file>[^\s:]+\.\w+):(?P<line>\d+)(?::(?P<column>\d+))?\s*$`,
+		Severity: "warning",
+	},
+}
+
+// LookupMatcher returns the matcher with the given name, preferring one
+// defined in the config over a built-in.
+func (c *Config) LookupMatcher(name string) (ProblemMatcher, bool) {
+	if m, ok := c.ProblemMatchers[name]; ok {
+		return m, true
+	}
+	m, ok := BuiltinMatchers[name]
+	return m, ok
+}
+
+type compiledMatcher struct {
+	pattern  *regexp.Regexp
+	file     *regexp.Regexp
+	severity string
+}
+
+func compileMatcher(m ProblemMatcher) (compiledMatcher, error) {
+	c := compiledMatcher{severity: m.Severity}
+	switch m.Severity {
+	case "":
+		c.severity = "error"
+	case "error", "warning", "info":
+	default:
+		return c, fmt.Errorf("invalid severity %q (expected error, warning or info)", m.Severity)
+	}
+	var err error
+	if c.pattern, err = regexp.Compile(m.Pattern); err != nil {
+		return c, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if c.pattern.SubexpIndex("message") < 0 {
+		return c, fmt.Errorf("pattern has no message group")
+	}
+	if m.FilePattern != "" {
+		if c.file, err = regexp.Compile(m.FilePattern); err != nil {
+			return c, fmt.Errorf("invalid file_pattern: %w", err)
+		}
+		if c.file.SubexpIndex("file") < 0 {
+			return c, fmt.Errorf("file_pattern has no file group")
+		}
+	}
+	return c, nil
+}
+
+// group returns the first non-empty capture of the named group.
+func group(re *regexp.Regexp, match []string, name string) string {
+	for i, n := range re.SubexpNames() {
+		if n == name && match[i] != "" {
+			return match[i]
+		}
+	}
+	return ""
+}
+
+// ProblemScanner runs a task's matchers over its output. A nil scanner
+// matches nothing.
+type ProblemScanner struct {
+	mu       sync.Mutex // stdout and stderr are scanned from separate readers
+	matchers []compiledMatcher
+	files    []string // current file of each matcher with a file_pattern
+	count    int
+}
+
+// NewProblemScanner compiles the named matchers. It returns nil when the
+// task declares none.
+func NewProblemScanner(names []string) (*ProblemScanner, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	config, err := GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	s := &ProblemScanner{files: make([]string, len(names))}
+	for _, name := range names {
+		m, ok := config.LookupMatcher(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown problem matcher %q", name)
+		}
+		c, err := compileMatcher(m)
+		if err != nil {
+			return nil, fmt.Errorf("problem matcher %q: %w", name, err)
+		}
+		s.matchers = append(s.matchers, c)
+	}
+	return s, nil
+}
+
+// Scan returns the problem reported on a line of output, if any. The first
+// matcher reporting a problem wins, but every matcher sees the line, so the
+// file headers of the others are tracked too.
+func (s *ProblemScanner) Scan(line string) (Problem, bool) {
+	if s == nil {
+		return Problem{}, false
+	}
+	line = CleanANSI(line)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found Problem
+	ok := false
+	for i, m := range s.matchers {
+		match := m.pattern.FindStringSubmatch(line)
+		if match == nil {
+			if m.file != nil {
+				if match := m.file.FindStringSubmatch(line); match != nil {
+					s.files[i] = group(m.file, match, "file")
+				}
+			}
+			continue
+		}
+		if ok {
+			continue
+		}
+		p := Problem{
+			File:     group(m.pattern, match, "file"),
+			Severity: strings.ToLower(group(m.pattern, match, "severity")),
+			Message:  strings.TrimSpace(group(m.pattern, match, "message")),
+		}
+		p.Line, _ = strconv.Atoi(group(m.pattern, match, "line"))
+		p.Column, _ = strconv.Atoi(group(m.pattern, match, "column"))
+		if p.File == "" {
+			p.File = s.files[i]
+		}
+		if p.Severity == "" {
+			p.Severity = m.severity
+		}
+		if p.Message == "" || s.count >= maxProblemsPerTask {
+			continue
+		}
+		p.File = relativePath(p.File)
+		found, ok = p, true
+	}
+	if ok {
+		s.count++
+	}
+	return found, ok
+}
+
+// relativePath shortens absolute paths under the working directory, as
+// printed by tools like ESLint.
+func relativePath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
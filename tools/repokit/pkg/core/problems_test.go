@@ -0,0 +1,176 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func scanAll(t *testing.T, matcher string, output []string) []Problem {
+	t.Helper()
+	s, err := NewProblemScanner([]string{matcher})
+	if err != nil {
+		t.Fatalf("NewProblemScanner(%q) error: %v", matcher, err)
+	}
+	var problems []Problem
+	for _, line := range output {
+		if p, ok := s.Scan(line); ok {
+			problems = append(problems, p)
+		}
+	}
+	return problems
+}
+
+func TestBuiltinMatchers(t *testing.T) {
+	wd, _ := os.Getwd()
+	tests := []struct {
+		matcher string
+		output  []string
+		want    []Problem
+	}{
+		{
+			matcher: "eslint",
+			output: []string{
+				"",
+				"\x1b[4m" + filepath.Join(wd, "src/app.ts") + "\x1b[24m",
+				"  12:5  \x1b[31merror\x1b[39m  Unexpected any. Specify a different type  @typescript-eslint/no-explicit-any",
+				"  30:1  warning  Unused eslint-disable directive",
+				"",
+				"✖ 2 problems (1 error, 1 warning)",
+			},
+			want: []Problem{
+				{File: "src/app.ts", Line: 12, Column: 5, Severity: "error", Message: "Unexpected any. Specify a different type"},
+				{File: "src/app.ts", Line: 30, Column: 1, Severity: "warning", Message: "Unused eslint-disable directive"},
+			},
+		},
+		{
+			matcher: "tsc",
+			output: []string{
+				"src/index.ts(4,7): error TS2322: Type 'string' is not assignable to type 'number'.",
+				"src/pages/index.astro:9:3 - error ts(2304): Cannot find name 'foo'.",
+				"Result (12 files): 2 errors",
+			},
+			want: []Problem{
+				{File: "src/index.ts", Line: 4, Column: 7, Severity: "error", Message: "TS2322: Type 'string' is not assignable to type 'number'."},
+				{File: "src/pages/index.astro", Line: 9, Column: 3, Severity: "error", Message: "ts(2304): Cannot find name 'foo'."},
+			},
+		},
+		{
+			matcher: "go_vet",
+			output: []string{
+				"# repokit/pkg/core",
+				"pkg/core/config.go:42:2: unreachable code",
+			},
+			want: []Problem{{File: "pkg/core/config.go", Line: 42, Column: 2, Severity: "error", Message: "unreachable code"}},
+		},
+		{
+			matcher: "go_test",
+			output: []string{
+				"=== RUN   TestParse",
+				"    parse_test.go:17: got 3, want 4",
+				"--- FAIL: TestParse (0.00s)",
+			},
+			want: []Problem{{File: "parse_test.go", Line: 17, Severity: "error", Message: "got 3, want 4"}},
+		},
+		{
+			matcher: "knip",
+			output: []string{
+				"Unused exports (1)",
+				"formatDate  function  src/utils/date.ts:14:17",
+			},
+			want: []Problem{{File: "src/utils/date.ts", Line: 14, Column: 17, Severity: "warning", Message: "formatDate  function"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.matcher, func(t *testing.T) {
+			got := scanAll(t, tt.matcher, tt.output)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d problems %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("problem %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProblemMatcherValidation(t *testing.T) {
+	base := Config{Tasks: map[string]TaskConfig{"lint": {ProblemMatchers: []string{"eslint"}}}}
+	if err := base.Validate(); err != nil {
+		t.Fatalf("built-in matcher rejected: %v", err)
+	}
+
+	unknown := Config{Tasks: map[string]TaskConfig{"lint": {ProblemMatchers: []string{"nope"}}}}
+	if err := unknown.Validate(); err == nil {
+		t.Error("expected an error for an unknown matcher")
+	}
+
+	for _, m := range []ProblemMatcher{
+		{Pattern: `(?P<message>`},
+		{Pattern: `^(?P<file>\S+)$`},
+		{Pattern: `(?P<message>.+)`, FilePattern: `^(\S+)$`},
+		{Pattern: `(?P<message>.+)`, Severity: "fatal"},
+	} {
+		c := Config{ProblemMatchers: map[string]ProblemMatcher{"custom": m}}
+		if err := c.Validate(); err == nil {
+			t.Errorf("expected matcher %+v to be rejected", m)
+		}
+	}
+}
+
+func TestProblemScannerLimit(t *testing.T) {
+	s := &ProblemScanner{files: []string{""}}
+	c, err := compileMatcher(ProblemMatcher{Pattern: `^(?P<message>.+)$`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.matchers = append(s.matchers, c)
+
+	found := 0
+	for range maxProblemsPerTask + 10 {
+		if _, ok := s.Scan("problem"); ok {
+			found++
+		}
+	}
+	if found != maxProblemsPerTask {
+		t.Errorf("found %d problems, want the cap of %d", found, maxProblemsPerTask)
+	}
+}
+
+func TestProblemScannerMatcherOrder(t *testing.T) {
+	s := &ProblemScanner{files: make([]string, 3)}
+	for _, m := range []ProblemMatcher{
+		// Matches every "error" line without capturing a message.
+		{Pattern: `^error(?P<message>)`},
+		{Pattern: `^error: (?P<message>.+)$`, Severity: "warning"},
+		{Pattern: `^  (?P<line>\d+) (?P<message>.+)$`, FilePattern: `^(?P<file>\S+\.go)$`},
+	} {
+		c, err := compileMatcher(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.matchers = append(s.matchers, c)
+	}
+
+	// An empty message from the first matcher leaves the line to the next.
+	p, ok := s.Scan("error: disk full")
+	if !ok || p.Severity != "warning" || p.Message != "disk full" {
+		t.Errorf("expected the second matcher to report the line, got %+v, %v", p, ok)
+	}
+	if _, ok := s.Scan("error:"); ok {
+		t.Error("expected an empty message to report nothing")
+	}
+
+	// A line another matcher reports still sets the file of the last one.
+	s.matchers[1].pattern = regexp.MustCompile(`^(?P<message>.+\.go)$`)
+	if p, ok := s.Scan("main.go"); !ok || p.Message != "main.go" {
+		t.Errorf("expected the line to be reported, got %+v, %v", p, ok)
+	}
+	if p, ok := s.Scan("  12 unused variable"); !ok || p.File != "main.go" || p.Line != 12 {
+		t.Errorf("expected the problem in main.go, got %+v, %v", p, ok)
+	}
+}
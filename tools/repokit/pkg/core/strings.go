@@ -1,6 +1,10 @@
 package core
 
-import "strings"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 // CleanANSI removes all terminal color and formatting escape codes from a string.
 // This is critical for calculating correct display lengths in CLI UI components.
@@ -28,3 +32,27 @@ func CleanANSI(s string) string {
 	}
 	return string(b.String())
 }
+
+// FormatBytes renders a byte count using binary units (e.g. "1.5 MB").
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+var slugifyRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify lowercases s and joins its runs of letters and digits with
+// hyphens, for use in file names and IDs.
+func Slugify(s string) string {
+	s = strings.ToLower(s)
+	s = slugifyRegexp.ReplaceAllString(s, "-")
+	return strings.Trim(s, "-")
+}
@@ -39,3 +39,33 @@ func TestCleanANSI(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{512, "512 B"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+	}
+
+	for _, tt := range tests {
+		if actual := FormatBytes(tt.input); actual != tt.expected {
+			t.Errorf("FormatBytes(%d) = %q, expected %q", tt.input, actual, tt.expected)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Arrow Left":    "arrow-left",
+		"--icon_v2--":   "icon-v2",
+		"tools/repokit": "tools-repokit",
+	}
+	for in, want := range tests {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
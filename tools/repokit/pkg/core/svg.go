@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SVGConfig configures the passes of the SVG optimizer and their settings.
+// Unset settings keep the optimizer's defaults.
+type SVGConfig struct {
+	_         struct{}      `additionalProperties:"false"`
+	Passes    []string      `yaml:"passes,omitempty" json:"passes,omitempty" description:"Optimization passes to run, in order. Defaults to every pass; an empty list runs none."`
+	Precision *int          `yaml:"precision,omitempty" json:"precision,omitempty" minimum:"0" maximum:"10" description:"Decimal places coordinates are rounded to (default 2)."`
+	Tolerance *float64      `yaml:"tolerance,omitempty" json:"tolerance,omitempty" minimum:"0" description:"Maximum distance, in user units, that simplification may move an outline (default 0.025)."`
+	SnapAngle *float64      `yaml:"snap_angle,omitempty" json:"snap_angle,omitempty" minimum:"0" description:"Angle, in radians, within which polygon edges are snapped to the axes (default 0.012)."`
+	ViewBox   string        `yaml:"view_box,omitempty" json:"view_box,omitempty" description:"viewBox every file is normalized to by the normalize_viewbox pass, such as \"0 0 24 24\". Unset leaves viewBoxes alone."`
+	Padding   *float64      `yaml:"padding,omitempty" json:"padding,omitempty" minimum:"0" description:"Space, in units of the normalized viewBox, kept between the content and each edge (default 0)."`
+	Palette   string        `yaml:"palette,omitempty" json:"palette,omitempty" description:"Design tokens svg palette checks colors against: a CSS file, whose color custom properties are the tokens, or a YAML or JSON map of token names to colors."`
+	Overrides []SVGOverride `yaml:"overrides,omitempty" json:"overrides,omitempty" description:"Settings for the files matching a glob. When several match, later entries win."`
+}
+
+// SVGOverride replaces the SVG optimizer settings for matching files.
+type SVGOverride struct {
+	_         struct{} `additionalProperties:"false"`
+	Files     string   `yaml:"files" json:"files" required:"true" description:"Glob of the files the settings apply to, relative to the working directory."`
+	Passes    []string `yaml:"passes,omitempty" json:"passes,omitempty" description:"Optimization passes to run, in order. An empty list runs none."`
+	Precision *int     `yaml:"precision,omitempty" json:"precision,omitempty" minimum:"0" maximum:"10" description:"Decimal places coordinates are rounded to."`
+	Tolerance *float64 `yaml:"tolerance,omitempty" json:"tolerance,omitempty" minimum:"0" description:"Maximum distance, in user units, that simplification may move an outline."`
+	SnapAngle *float64 `yaml:"snap_angle,omitempty" json:"snap_angle,omitempty" minimum:"0" description:"Angle, in radians, within which polygon edges are snapped to the axes."`
+	ViewBox   string   `yaml:"view_box,omitempty" json:"view_box,omitempty" description:"viewBox the files are normalized to by the normalize_viewbox pass."`
+	Padding   *float64 `yaml:"padding,omitempty" json:"padding,omitempty" minimum:"0" description:"Space, in units of the normalized viewBox, kept between the content and each edge."`
+}
+
+// Validate checks the settings. Pass names are checked by the optimizer,
+// which defines them.
+func (c SVGConfig) Validate() error {
+	if err := validateSVGSettings("svg", c.Precision, c.Tolerance, c.SnapAngle, c.ViewBox, c.Padding); err != nil {
+		return err
+	}
+	for i, o := range c.Overrides {
+		if o.Files == "" {
+			return fmt.Errorf("svg override %d has no files glob", i+1)
+		}
+		if err := validateSVGSettings(fmt.Sprintf("svg override %q", o.Files), o.Precision, o.Tolerance, o.SnapAngle, o.ViewBox, o.Padding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateSVGSettings(where string, precision *int, tolerance, snapAngle *float64, viewBox string, padding *float64) error {
+	if precision != nil && (*precision < 0 || *precision > 10) {
+		return fmt.Errorf("%s: precision %d is out of range (0-10)", where, *precision)
+	}
+	if tolerance != nil && *tolerance < 0 {
+		return fmt.Errorf("%s: tolerance must not be negative", where)
+	}
+	if snapAngle != nil && *snapAngle < 0 {
+		return fmt.Errorf("%s: snap_angle must not be negative", where)
+	}
+	if padding != nil && *padding < 0 {
+		return fmt.Errorf("%s: padding must not be negative", where)
+	}
+	if viewBox != "" {
+		box, err := ParseViewBox(viewBox)
+		if err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+		if padding != nil && 2**padding >= min(box[2], box[3]) {
+			return fmt.Errorf("%s: padding %g leaves no room in view_box %q", where, *padding, viewBox)
+		}
+	}
+	return nil
+}
+
+// ParseViewBox parses a viewBox, four numbers giving its x, y, width and
+// height. The width and height must be positive.
+func ParseViewBox(s string) ([4]float64, error) {
+	var box [4]float64
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	if len(fields) != 4 {
+		return box, fmt.Errorf("invalid view_box %q: expected four numbers", s)
+	}
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return box, fmt.Errorf("invalid view_box %q: %q is not a number", s, f)
+		}
+		box[i] = v
+	}
+	if box[2] <= 0 || box[3] <= 0 {
+		return box, fmt.Errorf("invalid view_box %q: width and height must be positive", s)
+	}
+	return box, nil
+}
@@ -0,0 +1,474 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"repokit/pkg/core"
+)
+
+const (
+	defaultWorkers = 3
+
+	// A worker count is recommended when it gets within this factor of the
+	// best achievable wall time.
+	workerTolerance = 1.05
+
+	cacheMinDuration = 5 * time.Second
+	cacheMinRuns     = 3
+	cacheMaxSpread   = 0.1 // standard deviation relative to the mean
+	splitMinShare    = 0.3 // share of the critical path
+)
+
+// ─── Task Statistics ─────────────────────────────────────────────────────────
+
+// TaskStats summarises the recorded executions of one task.
+type TaskStats struct {
+	ID       string
+	Runs     int
+	Failures int
+	Median   time.Duration
+	Mean     time.Duration
+	StdDev   time.Duration
+}
+
+// FailureRate estimates the probability that the next run fails. It is
+// smoothed so that a task with a short, clean record is not treated as
+// infallible.
+func (s TaskStats) FailureRate() float64 {
+	return float64(s.Failures+1) / float64(s.Runs+2)
+}
+
+// CollectStats aggregates finished task executions across runs. Cancelled
+// tasks never ran to completion and are ignored.
+func CollectStats(runs []core.RunRecord) map[string]TaskStats {
+	samples := make(map[string][]time.Duration)
+	failures := make(map[string]int)
+	for _, run := range runs {
+		for _, t := range run.Tasks {
+			switch t.Status {
+			case "error":
+				failures[t.ID]++
+			case "done":
+			default:
+				continue
+			}
+			samples[t.ID] = append(samples[t.ID], t.Elapsed)
+		}
+	}
+
+	stats := make(map[string]TaskStats, len(samples))
+	for id, s := range samples {
+		slices.Sort(s)
+		var sum time.Duration
+		for _, d := range s {
+			sum += d
+		}
+		mean := sum / time.Duration(len(s))
+		var variance float64
+		for _, d := range s {
+			variance += math.Pow(float64(d-mean), 2)
+		}
+		median := s[len(s)/2]
+		if len(s)%2 == 0 {
+			median = (s[len(s)/2-1] + s[len(s)/2]) / 2
+		}
+		stats[id] = TaskStats{
+			ID:       id,
+			Runs:     len(s),
+			Failures: failures[id],
+			Median:   median,
+			Mean:     mean,
+			StdDev:   time.Duration(math.Sqrt(variance / float64(len(s)))),
+		}
+	}
+	return stats
+}
+
+// ─── Pipeline Analysis ───────────────────────────────────────────────────────
+
+// WorkerEstimate is the estimated pipeline wall time for each worker count of
+// one parallel batch, with every other batch left as configured.
+type WorkerEstimate struct {
+	Batch       string
+	Configured  int
+	Walls       []time.Duration // Walls[i] is the estimate with i+1 workers
+	Recommended int
+}
+
+// Candidate is a task that would benefit from caching or splitting.
+type Candidate struct {
+	Task   string
+	Kind   string // "cache" or "split"
+	Reason string
+}
+
+// Ordering is a suggested order for the children of a sequential pipeline
+// that surfaces likely failures as early as possible.
+type Ordering struct {
+	Pipeline  string
+	Current   []string
+	Suggested []string
+
+	// Expected time until the pipeline either fails or completes.
+	CurrentExpected   time.Duration
+	SuggestedExpected time.Duration
+}
+
+// Analysis is the result of analysing a pipeline against its run history.
+type Analysis struct {
+	Pipeline     string
+	Runs         int
+	Estimate     time.Duration
+	CriticalPath []string
+	CriticalTime time.Duration
+	Unknown      []string // tasks without any recorded duration
+
+	Workers    []WorkerEstimate
+	Candidates []Candidate
+	Orderings  []Ordering
+}
+
+type analyzer struct {
+	config  core.Config
+	stats   map[string]TaskStats
+	workers map[string]int // per-batch worker overrides
+	unknown map[string]bool
+}
+
+// Analyze estimates the wall time and critical path of a pipeline from the
+// recorded durations of its tasks, and derives worker, caching, splitting and
+// ordering recommendations.
+func Analyze(id string, config core.Config, runs []core.RunRecord) (Analysis, error) {
+	if _, ok := config.Tasks[id]; !ok {
+		return Analysis{}, fmt.Errorf("task %q not found", id)
+	}
+
+	a := &analyzer{config: config, stats: CollectStats(runs), unknown: make(map[string]bool)}
+	result := Analysis{Pipeline: id, Runs: len(runs)}
+	result.Estimate, result.CriticalPath = a.estimate(id, make(map[string]bool))
+	for _, t := range result.CriticalPath {
+		result.CriticalTime += a.stats[t].Median
+	}
+	for t := range a.unknown {
+		result.Unknown = append(result.Unknown, t)
+	}
+	sort.Strings(result.Unknown)
+
+	for _, batch := range a.pipelines(id, true) {
+		result.Workers = append(result.Workers, a.workerEstimate(id, batch))
+	}
+	for _, seq := range a.pipelines(id, false) {
+		if o, ok := a.ordering(seq); ok {
+			result.Orderings = append(result.Orderings, o)
+		}
+	}
+	result.Candidates = a.candidates(result)
+	return result, nil
+}
+
+func isParallel(t core.TaskConfig) bool {
+	return t.Type == "batch" && t.Parallel
+}
+
+func (a *analyzer) workerCount(id string, t core.TaskConfig) int {
+	if w, ok := a.workers[id]; ok {
+		return w
+	}
+	if t.Workers > 0 {
+		return t.Workers
+	}
+	return defaultWorkers
+}
+
+// estimate mirrors RunTask: hooks and sequential children run one after the
+// other, each task at most once per run, while parallel batches run their
+// children as separate processes through a worker queue.
+func (a *analyzer) estimate(id string, visited map[string]bool) (time.Duration, []string) {
+	if visited[id] {
+		return 0, nil
+	}
+	visited[id] = true
+
+	task, ok := a.config.Tasks[id]
+	if !ok {
+		return a.leaf(id)
+	}
+
+	var total time.Duration
+	var path []string
+	add := func(d time.Duration, p []string) {
+		total += d
+		path = append(path, p...)
+	}
+
+	for _, pre := range task.PreRun {
+		add(a.estimate(pre, visited))
+	}
+	switch {
+	case isParallel(task):
+		add(a.queue(task.Tasks, a.workerCount(id, task)))
+	case task.IsPipeline():
+		for _, child := range task.Tasks {
+			add(a.estimate(child, visited))
+		}
+	default:
+		add(a.leaf(id))
+	}
+	for _, post := range task.PostRun {
+		add(a.estimate(post, visited))
+	}
+	return total, path
+}
+
+func (a *analyzer) leaf(id string) (time.Duration, []string) {
+	s, ok := a.stats[id]
+	if !ok {
+		a.unknown[id] = true
+		return 0, nil
+	}
+	return s.Median, []string{id}
+}
+
+// queue simulates RunQueue: tasks start in order as soon as their slots and
+// locks are free, with blocked tasks passed over. The critical path is the
+// chain of tasks that each started when the previous one released its slots,
+// ending with the task that finishes last.
+func (a *analyzer) queue(ids []string, workers int) (time.Duration, []string) {
+	type job struct {
+		duration time.Duration
+		path     []string
+		slots    int
+		locks    []string
+	}
+	type active struct {
+		job   job
+		end   time.Duration
+		chain []string
+	}
+
+	workers = max(workers, 1)
+	pending := make([]job, 0, len(ids))
+	for _, id := range ids {
+		// A child runs as its own repokit process, so a pipeline child
+		// is measured as a whole when it was recorded as one task.
+		j := job{slots: min(max(a.config.Tasks[id].Slots, 1), workers), locks: a.config.Tasks[id].Locks}
+		if s, ok := a.stats[id]; ok {
+			j.duration, j.path = s.Median, []string{id}
+		} else {
+			j.duration, j.path = a.estimate(id, make(map[string]bool))
+		}
+		pending = append(pending, j)
+	}
+
+	var now time.Duration
+	var running []active
+	var chain []string
+	var last active
+	free, held := workers, make(map[string]bool)
+	for len(pending) > 0 || len(running) > 0 {
+		for i := 0; i < len(pending); i++ {
+			j := pending[i]
+			blocked := j.slots > free
+			for _, lock := range j.locks {
+				blocked = blocked || held[lock]
+			}
+			if blocked {
+				continue
+			}
+			free -= j.slots
+			for _, lock := range j.locks {
+				held[lock] = true
+			}
+			running = append(running, active{job: j, end: now + j.duration, chain: append(slices.Clone(chain), j.path...)})
+			pending = append(pending[:i], pending[i+1:]...)
+			i--
+		}
+
+		// Advance to the next task to finish and release what it held.
+		first := 0
+		for i := range running {
+			if running[i].end < running[first].end {
+				first = i
+			}
+		}
+		done := running[first]
+		running = append(running[:first], running[first+1:]...)
+		now, chain = done.end, done.chain
+		free += done.job.slots
+		for _, lock := range done.job.locks {
+			delete(held, lock)
+		}
+		if done.end >= last.end {
+			last = done
+		}
+	}
+	return last.end, last.chain
+}
+
+// pipelines lists the parallel batches (or the sequential pipelines) reachable
+// from id, in depth-first order.
+func (a *analyzer) pipelines(id string, parallel bool) []string {
+	var found []string
+	seen := make(map[string]bool)
+	var walk func(string)
+	walk = func(id string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		task, ok := a.config.Tasks[id]
+		if !ok {
+			return
+		}
+		if task.IsPipeline() && isParallel(task) == parallel && len(task.Tasks) > 1 {
+			found = append(found, id)
+		}
+		for _, list := range [][]string{task.PreRun, task.Tasks, task.PostRun} {
+			for _, child := range list {
+				walk(child)
+			}
+		}
+	}
+	walk(id)
+	return found
+}
+
+func (a *analyzer) workerEstimate(root, batch string) WorkerEstimate {
+	task := a.config.Tasks[batch]
+	est := WorkerEstimate{Batch: batch, Configured: a.workerCount(batch, task)}
+
+	best := time.Duration(math.MaxInt64)
+	for w := 1; w <= len(task.Tasks); w++ {
+		a.workers = map[string]int{batch: w}
+		wall, _ := a.estimate(root, make(map[string]bool))
+		est.Walls = append(est.Walls, wall)
+		best = min(best, wall)
+	}
+	a.workers = nil
+
+	for i, wall := range est.Walls {
+		if float64(wall) <= float64(best)*workerTolerance {
+			est.Recommended = i + 1
+			break
+		}
+	}
+	return est
+}
+
+// ordering sorts the children of a sequential pipeline by failure
+// probability per second of runtime, the order that minimises the expected
+// time until the first failure.
+func (a *analyzer) ordering(id string) (Ordering, bool) {
+	children := a.config.Tasks[id].Tasks
+	durations := make(map[string]time.Duration, len(children))
+	rates := make(map[string]float64, len(children))
+	for _, child := range children {
+		durations[child], _ = a.estimate(child, make(map[string]bool))
+		if s, ok := a.stats[child]; ok {
+			rates[child] = s.FailureRate()
+		} else {
+			rates[child] = a.pipelineFailureRate(child)
+		}
+	}
+
+	suggested := slices.Clone(children)
+	score := func(id string) float64 {
+		return rates[id] / max(durations[id].Seconds(), 0.001)
+	}
+	sort.SliceStable(suggested, func(i, j int) bool { return score(suggested[i]) > score(suggested[j]) })
+	if slices.Equal(children, suggested) {
+		return Ordering{}, false
+	}
+
+	expected := func(order []string) time.Duration {
+		var total, pass float64 = 0, 1
+		for _, id := range order {
+			total += pass * float64(durations[id])
+			pass *= 1 - rates[id]
+		}
+		return time.Duration(total)
+	}
+	return Ordering{
+		Pipeline:          id,
+		Current:           children,
+		Suggested:         suggested,
+		CurrentExpected:   expected(children),
+		SuggestedExpected: expected(suggested),
+	}, true
+}
+
+// pipelineFailureRate combines the failure rates of a pipeline's leaf tasks.
+func (a *analyzer) pipelineFailureRate(id string) float64 {
+	task, ok := a.config.Tasks[id]
+	if !ok || !task.IsPipeline() {
+		return TaskStats{}.FailureRate()
+	}
+	pass := 1.0
+	for _, child := range task.Tasks {
+		if s, ok := a.stats[child]; ok {
+			pass *= 1 - s.FailureRate()
+		} else {
+			pass *= 1 - a.pipelineFailureRate(child)
+		}
+	}
+	return 1 - pass
+}
+
+func (a *analyzer) candidates(result Analysis) []Candidate {
+	var found []Candidate
+	for _, id := range result.CriticalPath {
+		s := a.stats[id]
+		if share := float64(s.Median) / float64(result.CriticalTime); result.CriticalTime > 0 && share >= splitMinShare {
+			found = append(found, Candidate{Task: id, Kind: "split",
+				Reason: fmt.Sprintf("%.0f%% of the critical path (%s); splitting it lets the work spread across workers", share*100, formatSeconds(s.Median))})
+		}
+	}
+
+	ids := make([]string, 0, len(a.stats))
+	for id := range a.stats {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		s := a.stats[id]
+		if !a.inPipeline(result.Pipeline, id) {
+			continue
+		}
+		if s.Median < cacheMinDuration || s.Runs < cacheMinRuns || s.Failures > 0 {
+			continue
+		}
+		if spread := float64(s.StdDev) / float64(s.Mean); spread <= cacheMaxSpread {
+			found = append(found, Candidate{Task: id, Kind: "cache",
+				Reason: fmt.Sprintf("always passes in a steady %s (±%.0f%% over %d runs); likely repeats the same work", formatSeconds(s.Median), spread*100, s.Runs)})
+		}
+	}
+	return found
+}
+
+func (a *analyzer) inPipeline(root, id string) bool {
+	seen := make(map[string]bool)
+	var walk func(string) bool
+	walk = func(t string) bool {
+		if t == id {
+			return true
+		}
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		task := a.config.Tasks[t]
+		for _, list := range [][]string{task.PreRun, task.Tasks, task.PostRun} {
+			for _, child := range list {
+				if walk(child) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return walk(root)
+}
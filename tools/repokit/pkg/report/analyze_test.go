@@ -0,0 +1,118 @@
+package report
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"repokit/pkg/core"
+)
+
+func analyzeFixture() (core.Config, []core.RunRecord) {
+	config := core.Config{Tasks: map[string]core.TaskConfig{
+		"lint":   {Type: "single"},
+		"test":   {Type: "single"},
+		"build":  {Type: "single"},
+		"docs":   {Type: "single"},
+		"deploy": {Type: "single"},
+		"checks": {Type: "batch", Parallel: true, Workers: 1, Tasks: []string{"lint", "test", "docs"}},
+		"all":    {Type: "sequential", Tasks: []string{"build", "checks"}, PostRun: []string{"deploy"}},
+	}}
+
+	durations := map[string]time.Duration{
+		"lint": 2 * time.Second, "test": 8 * time.Second, "build": 10 * time.Second,
+		"docs": 3 * time.Second, "deploy": time.Second,
+	}
+	var runs []core.RunRecord
+	for i := range 4 {
+		run := core.RunRecord{Task: "all"}
+		for _, id := range []string{"build", "lint", "test", "docs", "deploy"} {
+			status := "done"
+			if id == "lint" && i%2 == 0 {
+				status = "error"
+			}
+			run.Tasks = append(run.Tasks, core.TaskRecord{ID: id, Status: status, Elapsed: durations[id]})
+		}
+		runs = append(runs, run)
+	}
+	return config, runs
+}
+
+func TestCollectStats(t *testing.T) {
+	_, runs := analyzeFixture()
+	stats := CollectStats(runs)
+
+	lint := stats["lint"]
+	if lint.Runs != 4 || lint.Failures != 2 || lint.Median != 2*time.Second || lint.StdDev != 0 {
+		t.Errorf("unexpected lint stats: %+v", lint)
+	}
+	if got := lint.FailureRate(); got != 0.5 {
+		t.Errorf("FailureRate() = %v, want 0.5", got)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	config, runs := analyzeFixture()
+	a, err := Analyze("all", config, runs)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	// build (10s) + checks on a single worker (13s) + deploy (1s).
+	if a.Estimate != 24*time.Second {
+		t.Errorf("Estimate = %v, want 24s", a.Estimate)
+	}
+	if want := []string{"build", "lint", "test", "docs", "deploy"}; !slices.Equal(a.CriticalPath, want) {
+		t.Errorf("CriticalPath = %v, want %v", a.CriticalPath, want)
+	}
+
+	if len(a.Workers) != 1 {
+		t.Fatalf("expected one worker estimate, got %+v", a.Workers)
+	}
+	w := a.Workers[0]
+	want := []time.Duration{24 * time.Second, 19 * time.Second, 19 * time.Second}
+	if w.Batch != "checks" || w.Configured != 1 || !slices.Equal(w.Walls, want) || w.Recommended != 2 {
+		t.Errorf("unexpected worker estimate: %+v", w)
+	}
+
+	found := make(map[string]bool)
+	for _, c := range a.Candidates {
+		found[c.Kind+":"+c.Task] = true
+	}
+	if !found["split:build"] || !found["cache:test"] || found["cache:lint"] || found["split:docs"] {
+		t.Errorf("unexpected candidates: %+v", a.Candidates)
+	}
+
+	// checks fails far more often per second than build, so it should go first.
+	if len(a.Orderings) != 1 || !slices.Equal(a.Orderings[0].Suggested, []string{"checks", "build"}) {
+		t.Fatalf("unexpected orderings: %+v", a.Orderings)
+	}
+	if o := a.Orderings[0]; o.SuggestedExpected >= o.CurrentExpected {
+		t.Errorf("suggested order should surface failures sooner: %+v", o)
+	}
+}
+
+func TestAnalyzeUnknownTask(t *testing.T) {
+	config, runs := analyzeFixture()
+	if _, err := Analyze("missing", config, runs); err == nil {
+		t.Error("expected error for unknown pipeline")
+	}
+}
+
+func TestAnalyzeLocksAndSlots(t *testing.T) {
+	config, runs := analyzeFixture()
+	lint, test, docs := config.Tasks["lint"], config.Tasks["test"], config.Tasks["docs"]
+	lint.Locks, test.Locks = []string{"files"}, []string{"files"}
+	docs.Slots = 3
+	config.Tasks["lint"], config.Tasks["test"], config.Tasks["docs"] = lint, test, docs
+	config.Tasks["checks"] = core.TaskConfig{Type: "batch", Parallel: true, Workers: 3, Tasks: []string{"lint", "test", "docs"}}
+
+	a, err := Analyze("checks", config, runs)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+	// lint and test share a lock and docs needs every slot: 2s + 8s + 3s.
+	if a.Estimate != 13*time.Second {
+		t.Errorf("Estimate = %v, want 13s", a.Estimate)
+	}
+}
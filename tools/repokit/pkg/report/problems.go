@@ -0,0 +1,92 @@
+package report
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"slices"
+
+	"repokit/pkg/core"
+)
+
+// ─── Problems ────────────────────────────────────────────────────────────────
+
+// TaskProblem is a diagnostic together with the task that reported it.
+type TaskProblem struct {
+	Task string `json:"task"`
+	core.Problem
+}
+
+// FileProblems holds the diagnostics of one file, ordered by position.
+// Problems without a location are collected under an empty File.
+type FileProblems struct {
+	File     string
+	Problems []TaskProblem
+}
+
+// Problems returns every diagnostic recorded in a run, in task order.
+func Problems(run core.RunRecord) []TaskProblem {
+	var problems []TaskProblem
+	for _, t := range run.Tasks {
+		for _, p := range t.Problems {
+			problems = append(problems, TaskProblem{Task: t.ID, Problem: p})
+		}
+	}
+	return problems
+}
+
+// GroupByFile groups diagnostics by file, sorted by path, with problems
+// that name no file last.
+func GroupByFile(problems []TaskProblem) []FileProblems {
+	index := make(map[string]int)
+	var groups []FileProblems
+	for _, p := range problems {
+		i, ok := index[p.File]
+		if !ok {
+			i = len(groups)
+			index[p.File] = i
+			groups = append(groups, FileProblems{File: p.File})
+		}
+		groups[i].Problems = append(groups[i].Problems, p)
+	}
+
+	slices.SortFunc(groups, func(a, b FileProblems) int {
+		if (a.File == "") != (b.File == "") {
+			if a.File == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(a.File, b.File)
+	})
+	for _, g := range groups {
+		slices.SortStableFunc(g.Problems, func(a, b TaskProblem) int {
+			return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+		})
+	}
+	return groups
+}
+
+// CountSeverities returns the number of errors and of warnings; other
+// severities are counted in neither.
+func CountSeverities(problems []TaskProblem) (errors, warnings int) {
+	for _, p := range problems {
+		switch p.Severity {
+		case "error":
+			errors++
+		case "warning":
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// WriteProblemsJSON writes the diagnostics as a JSON array.
+func WriteProblemsJSON(w io.Writer, problems []TaskProblem) error {
+	if problems == nil {
+		problems = []TaskProblem{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(problems)
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"repokit/pkg/core"
+)
+
+func TestGroupByFile(t *testing.T) {
+	run := core.RunRecord{Tasks: []core.TaskRecord{
+		{ID: "lint", Problems: []core.Problem{
+			{File: "src/b.ts", Line: 9, Severity: "warning", Message: "unused"},
+			{Severity: "error", Message: "config not found"},
+			{File: "src/a.ts", Line: 3, Column: 2, Severity: "error", Message: "any"},
+		}},
+		{ID: "check", Problems: []core.Problem{
+			{File: "src/b.ts", Line: 1, Severity: "error", Message: "type"},
+		}},
+	}}
+
+	problems := Problems(run)
+	if len(problems) != 4 || problems[3].Task != "check" {
+		t.Fatalf("Problems() = %+v", problems)
+	}
+
+	groups := GroupByFile(problems)
+	var files []string
+	for _, g := range groups {
+		files = append(files, g.File)
+	}
+	if want := []string{"src/a.ts", "src/b.ts", ""}; !slices.Equal(files, want) {
+		t.Fatalf("files = %q, want %q", files, want)
+	}
+	if b := groups[1].Problems; b[0].Line != 1 || b[1].Line != 9 {
+		t.Errorf("problems of src/b.ts not ordered by line: %+v", b)
+	}
+
+	if errors, warnings := CountSeverities(problems); errors != 3 || warnings != 1 {
+		t.Errorf("CountSeverities() = %d, %d, want 3, 1", errors, warnings)
+	}
+}
+
+func TestWriteProblemsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteProblemsJSON(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("empty list written as %q", got)
+	}
+
+	buf.Reset()
+	in := []TaskProblem{{Task: "lint", Problem: core.Problem{File: "a.ts", Line: 1, Column: 2, Severity: "error", Message: "bad"}}}
+	if err := WriteProblemsJSON(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	var out []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if out[0]["task"] != "lint" || out[0]["file"] != "a.ts" || out[0]["message"] != "bad" {
+		t.Errorf("unexpected JSON object %v", out[0])
+	}
+}
@@ -0,0 +1,244 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"repokit/pkg/core"
+)
+
+// Bar is one task's span on the timeline, relative to the start of the run.
+type Bar struct {
+	Task     core.TaskRecord
+	Offset   time.Duration
+	Length   time.Duration
+	Critical bool
+}
+
+// End returns the offset at which the task finished.
+func (b Bar) End() time.Duration { return b.Offset + b.Length }
+
+// Timeline is a Gantt view of a recorded run.
+type Timeline struct {
+	Run  core.RunRecord
+	Bars []Bar
+
+	Wall    time.Duration // start of the first task to the end of the run
+	Busy    time.Duration // sum of all task durations
+	Workers int           // peak number of tasks running at once
+	Idle    time.Duration // worker time spent waiting: Workers×Wall − Busy
+
+	CriticalPath []string // task IDs, in execution order
+	CriticalTime time.Duration
+}
+
+// NewTimeline lays out the tasks of a run and derives its concurrency and
+// critical path. Runs record no dependency graph, so the critical path is
+// reconstructed as the chain that ends with the last task to finish, where
+// each step is the latest task to finish before the next one started.
+func NewTimeline(run core.RunRecord) Timeline {
+	t := Timeline{Run: run, Wall: run.Duration}
+
+	for _, task := range run.Tasks {
+		b := Bar{Task: task, Offset: max(task.Start.Sub(run.Start), 0), Length: max(task.Elapsed, 0)}
+		t.Bars = append(t.Bars, b)
+		t.Busy += b.Length
+		t.Wall = max(t.Wall, b.End())
+	}
+	sort.SliceStable(t.Bars, func(i, j int) bool { return t.Bars[i].Offset < t.Bars[j].Offset })
+
+	for _, c := range t.Concurrency(t.Wall, 0) {
+		t.Workers = max(t.Workers, c)
+	}
+	t.Idle = max(time.Duration(t.Workers)*t.Wall-t.Busy, 0)
+
+	t.markCriticalPath()
+	return t
+}
+
+func (t *Timeline) markCriticalPath() {
+	current := -1
+	for i, b := range t.Bars {
+		if b.Length > 0 && (current < 0 || b.End() > t.Bars[current].End()) {
+			current = i
+		}
+	}
+
+	var path []int
+	for current >= 0 {
+		path = append(path, current)
+		start, next := t.Bars[current].Offset, -1
+		for i, b := range t.Bars {
+			if i == current || b.Length == 0 || b.End() > start {
+				continue
+			}
+			if next < 0 || b.End() > t.Bars[next].End() {
+				next = i
+			}
+		}
+		current = next
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		b := &t.Bars[path[i]]
+		b.Critical = true
+		t.CriticalPath = append(t.CriticalPath, b.Task.ID)
+		t.CriticalTime += b.Length
+	}
+}
+
+// Concurrency samples the number of running tasks in equal steps across the
+// run. A zero step samples at every task start and end instead.
+func (t Timeline) Concurrency(span, step time.Duration) []int {
+	at := func(x time.Duration) int {
+		n := 0
+		for _, b := range t.Bars {
+			if b.Length > 0 && b.Offset <= x && x < b.End() {
+				n++
+			}
+		}
+		return n
+	}
+
+	var samples []int
+	if step <= 0 {
+		for _, b := range t.Bars {
+			samples = append(samples, at(b.Offset))
+		}
+		return samples
+	}
+	for x := time.Duration(0); x < span; x += step {
+		samples = append(samples, at(x+step/2))
+	}
+	return samples
+}
+
+// Utilization returns the share of worker time spent running tasks.
+func (t Timeline) Utilization() float64 {
+	if t.Workers == 0 || t.Wall == 0 {
+		return 0
+	}
+	return float64(t.Busy) / float64(time.Duration(t.Workers)*t.Wall)
+}
+
+// ─── SVG / HTML Rendering ────────────────────────────────────────────────────
+
+const (
+	svgLabelWidth = 220
+	svgChartWidth = 760
+	svgRowHeight  = 24
+	svgBarHeight  = 16
+	svgHeader     = 30
+	svgFooter     = 60
+)
+
+var barColors = map[string]string{
+	"done":      "#10b981",
+	"error":     "#e11d48",
+	"cancelled": "#6b7280",
+	"running":   "#3b82f6",
+}
+
+// RenderSVG writes the timeline as a standalone SVG image.
+func (t Timeline) RenderSVG(w io.Writer) error {
+	width := svgLabelWidth + svgChartWidth + 20
+	height := svgHeader + len(t.Bars)*svgRowHeight + svgFooter
+	scale := 0.0
+	if t.Wall > 0 {
+		scale = svgChartWidth / t.Wall.Seconds()
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="ui-monospace, monospace" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" fill="#0f172a"/>`+"\n")
+	fmt.Fprintf(&sb, `<text x="10" y="20" fill="#e2e8f0" font-weight="bold">%s · %s · %s</text>`+"\n",
+		html.EscapeString(t.Run.Task), t.Run.ID, formatSeconds(t.Wall))
+
+	// Second gridlines, at a step that keeps roughly ten labels on the axis.
+	tick := niceTick(t.Wall)
+	for x := time.Duration(0); tick > 0 && x <= t.Wall; x += tick {
+		px := svgLabelWidth + x.Seconds()*scale
+		fmt.Fprintf(&sb, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#1e293b"/>`+"\n", px, svgHeader, px, svgHeader+len(t.Bars)*svgRowHeight)
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%d" fill="#64748b" text-anchor="middle">%s</text>`+"\n", px, svgHeader+len(t.Bars)*svgRowHeight+14, formatSeconds(x))
+	}
+
+	for i, b := range t.Bars {
+		y := svgHeader + i*svgRowHeight
+		fmt.Fprintf(&sb, `<text x="10" y="%d" fill="#cbd5e1">%s</text>`+"\n", y+svgBarHeight-3, html.EscapeString(b.Task.ID))
+
+		stroke := ""
+		if b.Critical {
+			stroke = ` stroke="#fbbf24" stroke-width="2"`
+		}
+		color, ok := barColors[b.Task.Status]
+		if !ok {
+			color = barColors["running"]
+		}
+		fmt.Fprintf(&sb, `<rect x="%.1f" y="%d" width="%.1f" height="%d" rx="3" fill="%s"%s><title>%s %s</title></rect>`+"\n",
+			svgLabelWidth+b.Offset.Seconds()*scale, y+2, max(b.Length.Seconds()*scale, 1), svgBarHeight, color, stroke,
+			html.EscapeString(b.Task.ID), formatSeconds(b.Length))
+	}
+
+	fmt.Fprintf(&sb, `<text x="10" y="%d" fill="#94a3b8">%s</text>`+"\n", height-16, html.EscapeString(t.Summary()))
+	sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// RenderHTML writes a self-contained HTML page embedding the SVG chart and a
+// per-task table.
+func (t Timeline) RenderHTML(w io.Writer) error {
+	var chart strings.Builder
+	if err := t.RenderSVG(&chart); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<!doctype html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n<title>repokit timeline · %s</title>\n", html.EscapeString(t.Run.Task))
+	sb.WriteString("<style>body{background:#0f172a;color:#e2e8f0;font:13px ui-monospace,monospace;margin:24px}" +
+		"table{border-collapse:collapse;margin-top:16px}td,th{padding:4px 12px;text-align:left;border-bottom:1px solid #1e293b}" +
+		"td.num{text-align:right}tr.critical td:first-child{color:#fbbf24}</style>\n</head>\n<body>\n")
+	sb.WriteString(chart.String())
+	sb.WriteString("<table>\n<tr><th>Task</th><th>Status</th><th>Start</th><th>Duration</th></tr>\n")
+	for _, b := range t.Bars {
+		class := ""
+		if b.Critical {
+			class = ` class="critical"`
+		}
+		fmt.Fprintf(&sb, "<tr%s><td>%s</td><td>%s</td><td class=\"num\">%s</td><td class=\"num\">%s</td></tr>\n",
+			class, html.EscapeString(b.Task.ID), b.Task.Status, formatSeconds(b.Offset), formatSeconds(b.Length))
+	}
+	sb.WriteString("</table>\n</body>\n</html>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Summary is a one-line description of the run's shape.
+func (t Timeline) Summary() string {
+	return fmt.Sprintf("wall %s · busy %s · peak %d workers · idle %s (%.0f%% utilized) · critical path %s",
+		formatSeconds(t.Wall), formatSeconds(t.Busy), t.Workers, formatSeconds(t.Idle), t.Utilization()*100, formatSeconds(t.CriticalTime))
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// niceTick picks a 1/2/5×10ⁿ second interval giving at most ten ticks.
+func niceTick(span time.Duration) time.Duration {
+	if span <= 0 {
+		return 0
+	}
+	for unit := 100 * time.Millisecond; ; unit *= 10 {
+		for _, m := range []time.Duration{1, 2, 5} {
+			if span/(m*unit) <= 10 {
+				return m * unit
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"repokit/pkg/core"
+)
+
+func testRun() core.RunRecord {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	task := func(id string, offset, elapsed int) core.TaskRecord {
+		return core.TaskRecord{ID: id, Name: id, Status: "done", Start: start.Add(time.Duration(offset) * time.Second), Elapsed: time.Duration(elapsed) * time.Second}
+	}
+	return core.RunRecord{
+		ID:       "20260101-120000",
+		Task:     "all",
+		Start:    start,
+		Duration: 10 * time.Second,
+		Status:   "done",
+		Tasks: []core.TaskRecord{
+			task("install", 0, 3),
+			task("lint", 3, 2),
+			task("test", 3, 6),
+			task("build", 3, 4),
+			task("deploy", 9, 1),
+		},
+	}
+}
+
+func TestNewTimeline(t *testing.T) {
+	tl := NewTimeline(testRun())
+
+	if tl.Wall != 10*time.Second {
+		t.Errorf("Wall = %v, want 10s", tl.Wall)
+	}
+	if tl.Busy != 16*time.Second {
+		t.Errorf("Busy = %v, want 16s", tl.Busy)
+	}
+	if tl.Workers != 3 {
+		t.Errorf("Workers = %d, want 3", tl.Workers)
+	}
+	if tl.Idle != 14*time.Second {
+		t.Errorf("Idle = %v, want 14s", tl.Idle)
+	}
+
+	want := []string{"install", "test", "deploy"}
+	if strings.Join(tl.CriticalPath, ",") != strings.Join(want, ",") {
+		t.Errorf("CriticalPath = %v, want %v", tl.CriticalPath, want)
+	}
+	if tl.CriticalTime != 10*time.Second {
+		t.Errorf("CriticalTime = %v, want 10s", tl.CriticalTime)
+	}
+}
+
+func TestTimelineRender(t *testing.T) {
+	tl := NewTimeline(testRun())
+
+	var svg bytes.Buffer
+	if err := tl.RenderSVG(&svg); err != nil {
+		t.Fatalf("RenderSVG() error: %v", err)
+	}
+	if !strings.HasPrefix(svg.String(), "<svg") || strings.Count(svg.String(), "<rect x=") != 5 {
+		t.Errorf("unexpected SVG output:\n%s", svg.String())
+	}
+
+	var page bytes.Buffer
+	if err := tl.RenderHTML(&page); err != nil {
+		t.Fatalf("RenderHTML() error: %v", err)
+	}
+	if !strings.Contains(page.String(), "<svg") || strings.Count(page.String(), `class="critical"`) != 3 {
+		t.Errorf("unexpected HTML output:\n%s", page.String())
+	}
+}
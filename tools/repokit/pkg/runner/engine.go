@@ -48,8 +48,10 @@ func RunTask(id string, data any, visited map[string]bool) {
 		RunTask(preID, data, visited)
 	}
 
-	// 2. Main Execution: Route pipelines vs single commands properly
-	if task.Type == "batch" || task.Type == "sequential" || len(task.Tasks) > 0 {
+	// 2. Main Execution: Route natives, pipelines and single commands properly
+	if task.Type == "native" {
+		RunNative(id, nil)
+	} else if task.IsPipeline() {
 		RunPipeline(id, &task, visited)
 	} else {
 		cmdStr, err := core.EvaluateCommand(task.Command, data)
@@ -58,9 +60,9 @@ func RunTask(id string, data any, visited map[string]bool) {
 		}
 
 		if task.Interactive {
-			RunInteractive(task.Name, cmdStr, task.Cwd)
+			RunInteractive(id, task.Name, cmdStr, task.Cwd)
 		} else {
-			runCommand(task.Name, cmdStr, task.Cwd)
+			runCommand(id, task, cmdStr)
 		}
 	}
 
@@ -70,6 +72,12 @@ func RunTask(id string, data any, visited map[string]bool) {
 	}
 }
 
+// runsNested reports whether processTask runs a task as a nested repokit
+// process rather than as a shell command.
+func runsNested(task core.TaskConfig) bool {
+	return task.Type == "native" || task.IsPipeline()
+}
+
 // RunPipeline executes a set of tasks based on the TaskConfig type.
 func RunPipeline(id string, cfg *core.TaskConfig, visited map[string]bool) {
 	if os.Getenv("REPOKIT_NESTED") != "1" && !core.TuiMode {
@@ -81,7 +89,7 @@ func RunPipeline(id string, cfg *core.TaskConfig, visited map[string]bool) {
 		if workers <= 0 {
 			workers = 3
 		}
-		RunQueue(cfg.Tasks, workers, cfg.ContinueOnError)
+		RunQueue(cfg.Tasks, workers, cfg.FailurePolicy())
 	} else {
 		// Sequential Pipeline (or batch with parallel: false)
 		for _, taskID := range cfg.Tasks {
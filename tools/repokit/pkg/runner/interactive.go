@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+
+	"repokit/pkg/core"
+)
+
+// ─── Interactive Sessions ────────────────────────────────────────────────────
+
+// ExecInteractive hands an interactive command the terminal and waits for
+// it. It runs the command directly by default; the TUI replaces it to
+// suspend itself for the duration of the session.
+var ExecInteractive = func(c *InteractiveCmd) error { return c.Run() }
+
+// InteractiveCmd is a command attached to the terminal. It runs in its own
+// process group, which is made the terminal's foreground group so that the
+// command can read input and receives Ctrl+C and window size changes itself.
+// It satisfies bubbletea's ExecCommand interface.
+type InteractiveCmd struct {
+	cmd  *exec.Cmd
+	stop context.CancelFunc
+}
+
+func newInteractiveCmd(command, cwd string) *InteractiveCmd {
+	ctx, stop := context.WithCancel(context.Background())
+	cmd := createCmd(ctx, command, cwd)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return &InteractiveCmd{cmd: cmd, stop: stop}
+}
+
+// The terminal is only replaced by another file, never by a pipe, so that
+// the command keeps seeing a TTY.
+func (c *InteractiveCmd) SetStdin(r io.Reader) {
+	if f, ok := r.(*os.File); ok {
+		c.cmd.Stdin = f
+	}
+}
+
+func (c *InteractiveCmd) SetStdout(w io.Writer) {
+	if f, ok := w.(*os.File); ok {
+		c.cmd.Stdout = f
+	}
+}
+
+func (c *InteractiveCmd) SetStderr(w io.Writer) {
+	if f, ok := w.(*os.File); ok {
+		c.cmd.Stderr = f
+	}
+}
+
+// Run starts the command and waits for it, forwarding signals sent to
+// repokit to the command's process group. SIGTERM and SIGHUP terminate the
+// group like a cancelled task, with a grace period before SIGKILL.
+func (c *InteractiveCmd) Run() error {
+	defer c.stop()
+
+	tty := -1
+	if f, ok := c.cmd.Stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		tty = int(f.Fd())
+		c.cmd.SysProcAttr.Foreground = true
+		c.cmd.SysProcAttr.Ctty = tty
+		// Taking the terminal back from the child's group raises SIGTTOU.
+		signal.Ignore(syscall.SIGTTOU)
+		defer signal.Reset(syscall.SIGTTOU)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGWINCH)
+	defer signal.Stop(sigs)
+
+	if err := c.cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				switch sig {
+				case syscall.SIGTERM, syscall.SIGHUP:
+					c.stop()
+				default:
+					_ = syscall.Kill(-c.cmd.Process.Pid, sig.(syscall.Signal))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := c.cmd.Wait()
+	close(done)
+	if tty >= 0 {
+		_ = unix.IoctlSetPointerInt(tty, unix.TIOCSPGRP, syscall.Getpgrp())
+	}
+	return err
+}
+
+// RunInteractive runs a command with the terminal attached, recording its
+// start and exit status as task events.
+func RunInteractive(id, name, command, cwd string) {
+	isNested := os.Getenv("REPOKIT_NESTED") == "1"
+	if !isNested && !core.TuiMode {
+		core.Info("Interactive Session: %s", name)
+	}
+
+	core.PublishEvent(core.EventTaskStart, id, name)
+	err := ExecInteractive(newInteractiveCmd(command, cwd))
+	if err == nil {
+		core.PublishEvent(core.EventTaskDone, id, "")
+		return
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && !exitErr.Exited() {
+		err = fmt.Errorf("terminated by %v", exitErr.Sys().(syscall.WaitStatus).Signal())
+	}
+	core.PublishEvent(core.EventTaskError, id, err.Error())
+	if !core.TuiMode {
+		core.Error("%s failed: %v", name, err)
+		core.OSExit(1)
+	}
+	panic(fmt.Sprintf("%s failed: %v", name, err))
+}
@@ -0,0 +1,19 @@
+package runner_test
+
+import (
+	"os"
+	"testing"
+
+	"repokit/cmd"
+)
+
+// TestMain runs the test binary as the repokit CLI when a test sets
+// REPOKIT_TEST_CLI, so that the nested processes a queue starts with
+// os.Executable go through the real command line.
+func TestMain(m *testing.M) {
+	if os.Getenv("REPOKIT_TEST_CLI") == "1" {
+		cmd.Execute()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"repokit/pkg/core"
+)
+
+// ─── Native Commands ─────────────────────────────────────────────────────────
+
+// RunNative runs a registered native command with the given params, missing
+// ones taking their defaults. Like a shell task it publishes start, log and
+// completion events, and the files it reports writing are collected as
+// artifacts of the run.
+func RunNative(id string, given map[string]string) {
+	c, ok := core.LookupNative(id)
+	if !ok {
+		core.Fatal("native command %q not found", id)
+	}
+	name := c.Name()
+	params, err := core.ResolveParams(c, given)
+	if err != nil {
+		core.Fatal("%v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	core.PublishEvent(core.EventTaskStart, id, name)
+	result, err := runNative(ctx, id, c, params)
+	if err == nil && ctx.Err() != nil {
+		err = fmt.Errorf("cancelled")
+	}
+	if err != nil {
+		core.PublishEvent(core.EventTaskError, id, err.Error())
+		if !core.TuiMode {
+			core.Error("%s failed: %v", name, err)
+			core.OSExit(1)
+		}
+		panic(fmt.Sprintf("%s failed: %v", name, err))
+	}
+
+	if result.Summary != "" {
+		core.PublishLog(id, core.StreamStdout, result.Summary, false)
+	}
+	artifacts := collectOutputs(id, result.Outputs)
+	core.PublishEvent(core.EventTaskDone, id, "")
+	if !core.TuiMode && !core.Quiet {
+		fmt.Printf(" %s  %s %s\n", core.Green.Render("•"), name, core.Subtle.Render(result.Summary))
+		for _, a := range artifacts {
+			fmt.Printf("    %s %s\n", core.Subtle.Render("artifact"), a)
+		}
+	}
+}
+
+// runNative calls the command, turning a panic into an error. In the TUI
+// the core loggers buffer their output instead of printing it, so what the
+// command logged is forwarded as task output.
+func runNative(ctx context.Context, id string, c core.NativeCommand, params core.Params) (result core.Result, err error) {
+	defer func() {
+		if core.TuiMode {
+			for _, line := range core.GetLogLines() {
+				core.PublishLog(id, core.StreamStdout, line, false)
+			}
+		}
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return c.Run(ctx, params)
+}
+
+// collectOutputs copies the files a native command wrote into the run's
+// artifacts directory.
+func collectOutputs(id string, outputs []string) []string {
+	runID := core.CurrentRunID()
+	if runID == "" {
+		return nil
+	}
+	dest := filepath.Join(core.RunDir(runID), "artifacts")
+	var collected []string
+	for _, src := range outputs {
+		target := filepath.Join(dest, filepath.Base(src))
+		if err := copyPath(src, target); err != nil {
+			core.Warning("Failed to collect artifact %s of %s: %v", src, id, err)
+			continue
+		}
+		core.PublishEvent(core.EventTaskArtifact, id, target)
+		collected = append(collected, target)
+	}
+	return collected
+}
@@ -0,0 +1,161 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"repokit/pkg/core"
+)
+
+// ─── Log Capture & Artifacts ─────────────────────────────────────────────────
+
+// taskLog captures a task's combined output to its log_file. A nil taskLog
+// discards everything, so callers need not check whether capture is enabled.
+type taskLog struct {
+	mu   sync.Mutex // stdout and stderr are written from separate readers
+	file *os.File
+}
+
+// openTaskLog creates the task's log file inside the current run directory.
+// Capture is skipped when the task declares no log_file or when no run is
+// being recorded.
+func openTaskLog(id string, task core.TaskConfig) *taskLog {
+	runID := core.CurrentRunID()
+	if task.LogFile == "" || runID == "" {
+		return nil
+	}
+
+	path := task.LogFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(core.RunDir(runID), path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		core.Warning("Failed to create log directory for %s: %v", id, err)
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		core.Warning("Failed to create log file for %s: %v", id, err)
+		return nil
+	}
+	core.PublishEvent(core.EventTaskLogFile, id, path)
+	return &taskLog{file: f}
+}
+
+// WriteLine appends a line of output with ANSI codes removed.
+func (l *taskLog) WriteLine(line string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = fmt.Fprintln(l.file, core.CleanANSI(line))
+}
+
+func (l *taskLog) Close() {
+	if l == nil {
+		return
+	}
+	_ = l.file.Close()
+}
+
+// problemScanner compiles the task's problem matchers. Configuration errors
+// are reported once and disable matching rather than failing the task.
+func problemScanner(id string, task core.TaskConfig) *core.ProblemScanner {
+	scanner, err := core.NewProblemScanner(task.ProblemMatchers)
+	if err != nil {
+		core.Warning("Problem matching disabled for %s: %v", id, err)
+	}
+	return scanner
+}
+
+// collectArtifacts copies the files and directories matching the task's
+// artifact globs, resolved against its cwd, into the run's artifacts
+// directory, preserving their paths relative to the cwd.
+func collectArtifacts(id string, task core.TaskConfig) []string {
+	runID := core.CurrentRunID()
+	if len(task.Artifacts) == 0 || runID == "" {
+		return nil
+	}
+
+	base, err := filepath.Abs(task.Cwd)
+	if err != nil {
+		core.Warning("Failed to resolve cwd of %s: %v", id, err)
+		return nil
+	}
+	dest := filepath.Join(core.RunDir(runID), "artifacts")
+
+	var collected []string
+	for _, pattern := range task.Artifacts {
+		matches, err := core.ResolveFiles(filepath.Join(base, pattern))
+		if err != nil {
+			core.Warning("Invalid artifact pattern %q in %s: %v", pattern, id, err)
+			continue
+		}
+		if len(matches) == 0 {
+			core.Warning("Artifact %q of %s matched no files", pattern, id)
+		}
+		for _, src := range matches {
+			rel, err := filepath.Rel(base, src)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				rel = filepath.Base(src)
+			}
+			target := filepath.Join(dest, rel)
+			if err := copyPath(src, target); err != nil {
+				core.Warning("Failed to collect artifact %s of %s: %v", rel, id, err)
+				continue
+			}
+			core.PublishEvent(core.EventTaskArtifact, id, target)
+			collected = append(collected, target)
+		}
+	}
+	return collected
+}
+
+// copyPath copies a file, or a directory tree, to dst.
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,173 @@
+package runner
+
+import (
+	"slices"
+
+	"repokit/pkg/core"
+)
+
+// ─── Execution Plan ──────────────────────────────────────────────────────────
+
+// PlanNode describes how one task would be executed, mirroring the decisions
+// RunTask, RunPipeline and RunQueue make at run time.
+type PlanNode struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Command     string   `json:"command,omitempty"`
+	Cwd         string   `json:"cwd,omitempty"`
+	Env         []string `json:"env,omitempty"`
+	Interactive bool     `json:"interactive,omitempty"`
+
+	// Set on native commands: the params they run with.
+	Params map[string]string `json:"params,omitempty"`
+
+	// Set on pipelines.
+	Parallel  bool   `json:"parallel,omitempty"`
+	Workers   int    `json:"workers,omitempty"`
+	OnFailure string `json:"on_failure,omitempty"`
+
+	// Set on children of a parallel batch.
+	Slots int      `json:"slots,omitempty"`
+	Locks []string `json:"locks,omitempty"`
+
+	// Nested children of a parallel batch run as a separate repokit process.
+	Nested bool `json:"nested,omitempty"`
+
+	// Skipped explains why the task does not execute here; Error why it
+	// cannot be planned.
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+
+	PreRun  []*PlanNode `json:"pre_run,omitempty"`
+	Tasks   []*PlanNode `json:"tasks,omitempty"`
+	PostRun []*PlanNode `json:"post_run,omitempty"`
+}
+
+// Plan resolves the execution plan of a task without running anything: the
+// expanded command and cwd, hooks, and pipeline children in the order and
+// with the parallelism they would run with. data is passed to the command
+// template as in RunTask.
+func Plan(id string, data any) *PlanNode {
+	p := planner{}
+	return p.task(id, data, make(map[string]bool))
+}
+
+type planner struct {
+	ancestors []string // pipelines being expanded, to stop nested cycles
+}
+
+// task follows RunTask: hooks around either a pipeline or a single command.
+// visited is shared the same way RunTask shares it, so a task that already
+// ran in this process is reported as skipped.
+func (p *planner) task(id string, data any, visited map[string]bool) *PlanNode {
+	node := &PlanNode{ID: id}
+	if visited[id] {
+		node.Skipped = "already ran earlier in this process"
+		return node
+	}
+	visited[id] = true
+
+	task, err := lookupTask(id)
+	if err != nil {
+		node.Error = err.Error()
+		return node
+	}
+	p.describe(node, task)
+
+	for _, pre := range task.PreRun {
+		node.PreRun = append(node.PreRun, p.task(pre, data, visited))
+	}
+	if task.Type == "native" {
+		p.native(node)
+	} else if task.IsPipeline() {
+		p.pipeline(node, task, visited)
+	} else {
+		p.command(node, task, data)
+		node.Interactive = task.Interactive
+		node.Env = []string{core.RunIDEnv + "=<run-id>"}
+	}
+	for _, post := range task.PostRun {
+		node.PostRun = append(node.PostRun, p.task(post, data, visited))
+	}
+	return node
+}
+
+// pipeline follows RunPipeline and RunQueue.
+func (p *planner) pipeline(node *PlanNode, task core.TaskConfig, visited map[string]bool) {
+	if slices.Contains(p.ancestors, node.ID) {
+		node.Skipped = "cycle: the pipeline contains itself"
+		return
+	}
+	p.ancestors = append(p.ancestors, node.ID)
+	defer func() { p.ancestors = p.ancestors[:len(p.ancestors)-1] }()
+
+	if !(task.Type == "batch" && task.Parallel) {
+		for _, child := range task.Tasks {
+			node.Tasks = append(node.Tasks, p.task(child, nil, visited))
+		}
+		return
+	}
+
+	node.Parallel = true
+	node.Workers = task.Workers
+	if node.Workers <= 0 {
+		node.Workers = 3
+	}
+	node.OnFailure = task.FailurePolicy()
+	for _, id := range task.Tasks {
+		node.Tasks = append(node.Tasks, p.queued(id, node.Workers))
+	}
+}
+
+// native follows RunNative, which runs the command with its default params.
+func (p *planner) native(node *PlanNode) {
+	c, ok := core.LookupNative(node.ID)
+	if !ok {
+		node.Error = "native command not registered"
+		return
+	}
+	params, err := core.ResolveParams(c, nil)
+	if err != nil {
+		node.Error = err.Error()
+		return
+	}
+	node.Params = params
+}
+
+// queued follows processTask: single tasks run their command directly,
+// without hooks, while pipelines and native commands run as a nested
+// repokit process that plans from scratch.
+func (p *planner) queued(id string, workers int) *PlanNode {
+	task, err := lookupTask(id)
+	if err != nil {
+		return &PlanNode{ID: id, Error: err.Error()}
+	}
+	if runsNested(task) {
+		node := p.task(id, nil, make(map[string]bool))
+		node.Nested = true
+		node.Env = []string{core.RunIDEnv + "=<run-id>", "REPOKIT_NESTED=1"}
+		node.Slots, node.Locks = min(max(task.Slots, 1), workers), task.Locks
+		return node
+	}
+
+	node := &PlanNode{ID: id}
+	p.describe(node, task)
+	p.command(node, task, nil)
+	node.Env = []string{core.RunIDEnv + "=<run-id>"}
+	node.Slots, node.Locks = min(max(task.Slots, 1), workers), task.Locks
+	return node
+}
+
+func (p *planner) describe(node *PlanNode, task core.TaskConfig) {
+	node.Name, node.Type, node.Cwd = task.Name, task.Type, task.Cwd
+}
+
+func (p *planner) command(node *PlanNode, task core.TaskConfig, data any) {
+	cmd, err := core.EvaluateCommand(task.Command, data)
+	if err != nil {
+		node.Error = "template error: " + err.Error()
+		return
+	}
+	node.Command = cmd
+}
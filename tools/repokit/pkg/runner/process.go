@@ -1,10 +1,8 @@
 package runner
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -14,11 +12,18 @@ import (
 	"repokit/pkg/core"
 )
 
+// killGracePeriod is how long a cancelled process group gets to exit after
+// SIGTERM before it is killed.
+const killGracePeriod = 2 * time.Second
+
 func createCmd(ctx context.Context, command, cwd string) *exec.Cmd {
 	cmd := exec.CommandContext(ctx, "bash", "-c", command)
 	if cwd != "" && cwd != "." {
 		cmd.Dir = cwd
 	}
+	if runID := core.CurrentRunID(); runID != "" {
+		cmd.Env = append(os.Environ(), core.RunIDEnv+"="+runID)
+	}
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 	cmd.Cancel = func() error {
 		if cmd.Process == nil {
@@ -27,7 +32,7 @@ func createCmd(ctx context.Context, command, cwd string) *exec.Cmd {
 		pgid, err := syscall.Getpgid(cmd.Process.Pid)
 		if err == nil {
 			_ = syscall.Kill(-pgid, syscall.SIGTERM)
-			time.AfterFunc(2*time.Second, func() { _ = syscall.Kill(-pgid, syscall.SIGKILL) })
+			time.AfterFunc(killGracePeriod, func() { _ = syscall.Kill(-pgid, syscall.SIGKILL) })
 			return nil
 		}
 		return cmd.Process.Kill()
@@ -35,73 +40,66 @@ func createCmd(ctx context.Context, command, cwd string) *exec.Cmd {
 	return cmd
 }
 
-func runCommand(name, command, cwd string) {
+func runCommand(id string, task core.TaskConfig, command string) {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	core.PublishEvent(core.EventTaskStart, name, command)
+	name := task.Name
+	core.PublishEvent(core.EventTaskStart, id, name)
 
-	cmd := createCmd(ctx, command, cwd)
+	cmd := createCmd(ctx, command, task.Cwd)
 
-	stdout, _ := cmd.StdoutPipe()
-	stderr, _ := cmd.StderrPipe()
+	log := openTaskLog(id, task)
+	defer log.Close()
+	problems := problemScanner(id, task)
 
-	go func() {
-		scanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
-		for scanner.Scan() {
-			line := scanner.Text()
-			core.PublishEvent(core.EventTaskLog, name, line)
-			if !core.TuiMode && !core.Quiet {
-				clean := core.CleanANSI(line)
-				if len(clean) > 85 {
-					clean = clean[:82] + "..."
-				}
-				fmt.Printf("  │ %s\n", clean)
+	output, err := attachOutput(cmd, func(line string, stream core.Stream, partial bool) {
+		core.PublishLog(id, stream, line, partial)
+		if partial {
+			return
+		}
+		log.WriteLine(line)
+		if p, ok := problems.Scan(line); ok {
+			core.PublishProblem(id, p)
+		}
+		if !core.TuiMode && !core.Quiet {
+			gutter := "│"
+			if stream == core.StreamStderr {
+				gutter = core.Red.Render("│")
 			}
+			fmt.Printf("  %s %s\n", gutter, trimPrintable(line, 85))
 		}
-	}()
+	})
+	if err != nil {
+		core.Fatal("Failed to capture output of %s: %v", name, err)
+	}
 
-	err := cmd.Run()
+	usage, err := runMonitored(cmd, id)
+	output.finish()
 
 	if err != nil {
 		if ctx.Err() != nil {
-			core.PublishEvent(core.EventTaskError, name, "cancelled")
+			core.PublishEvent(core.EventTaskError, id, "cancelled")
 			if !core.TuiMode {
 				fmt.Println("\n" + core.Yellow.Render(fmt.Sprintf("⏹️  %s cancelled.", name)))
-				os.Exit(1)
+				core.OSExit(1)
 			}
 			panic(fmt.Sprintf("%s cancelled", name))
 		}
-		core.PublishEvent(core.EventTaskError, name, err.Error())
+		core.PublishEvent(core.EventTaskError, id, err.Error())
 		if !core.TuiMode {
 			core.Error("%s failed: %v", name, err)
-			os.Exit(1)
+			core.OSExit(1)
 		}
 		panic(fmt.Sprintf("%s failed: %v", name, err))
 	}
 
-	core.PublishEvent(core.EventTaskDone, name, "")
+	artifacts := collectArtifacts(id, task)
+	core.PublishEvent(core.EventTaskDone, id, "")
 	if !core.TuiMode && !core.Quiet {
-		fmt.Printf(" %s  %s\n", core.Green.Render("•"), name)
-	}
-}
-
-func RunInteractive(name, command, cwd string) {
-	isNested := os.Getenv("REPOKIT_NESTED") == "1"
-	if !isNested && !core.TuiMode {
-		core.Info("Interactive Session: %s", name)
-	}
-
-	cmd := exec.Command("bash", "-c", command)
-	if cwd != "" && cwd != "." {
-		cmd.Dir = cwd
-	}
-	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
-	if err := cmd.Run(); err != nil {
-		if !core.TuiMode {
-			core.Error("%s failed: %v", name, err)
-			os.Exit(1)
+		fmt.Printf(" %s  %s %s\n", core.Green.Render("•"), name, core.Subtle.Render(formatUsage(usage)))
+		for _, a := range artifacts {
+			fmt.Printf("    %s %s\n", core.Subtle.Render("artifact"), a)
 		}
-		panic(fmt.Sprintf("%s failed: %v", name, err))
 	}
 }
@@ -1,7 +1,6 @@
 package runner
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
@@ -13,57 +12,92 @@ import (
 	"repokit/pkg/core"
 )
 
+// lookupTask resolves task IDs; tests replace it to run ad-hoc tasks.
+var lookupTask = core.GetTaskByID
+
+// notifyContext returns the context a queue runs under, cancelled by SIGINT
+// or SIGTERM; tests replace it to interrupt a queue.
+var notifyContext = func() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
 type queueContext struct {
-	ids             []string
-	workers         int
-	continueOnError bool
-	failed          bool
-	mu              sync.Mutex
-	wg              sync.WaitGroup
-	ctx             context.Context
+	ids     []string
+	tasks   []core.TaskConfig
+	workers int
+	policy  string
+	failed  bool
+	cause   string // first task to fail under fail_fast or finish_running
+	// interrupted is set when a signal cancelled tasks, which fails the
+	// queue like a failed task so that an enclosing pipeline stops too.
+	interrupted bool
+	mu          sync.Mutex
+	wg          sync.WaitGroup
+	ctx         context.Context
+
+	// Scheduling state, guarded by mu: free worker slots and held locks.
+	// slotFreed is signalled whenever a task releases its slots and locks.
+	free      int
+	held      map[string]bool
+	slotFreed *sync.Cond
+
+	// batch is cancelled by the first failure under the fail_fast policy,
+	// terminating every running sibling.
+	batch  context.Context
+	cancel context.CancelFunc
 }
 
-// RunQueue executes a list of task IDs in parallel
-func RunQueue(ids []string, workers int, continueOnError bool) {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+// RunQueue executes a list of task IDs in parallel. The policy (one of
+// core.FailFast, core.FinishRunning or core.Continue) decides what happens to
+// the rest of the batch once a task fails.
+func RunQueue(ids []string, workers int, policy string) {
+	ctx, stop := notifyContext()
+	defer stop()
+	batch, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	q := &queueContext{
-		ids:             ids,
-		workers:         workers,
-		continueOnError: continueOnError,
-		ctx:             ctx,
+		ids:     ids,
+		tasks:   make([]core.TaskConfig, len(ids)),
+		workers: workers,
+		policy:  policy,
+		ctx:     ctx,
+		batch:   batch,
+		cancel:  cancel,
+		free:    workers,
+		held:    make(map[string]bool),
 	}
-
-	taskChan := make(chan int, len(ids))
-	for i := range ids {
-		taskChan <- i
+	q.slotFreed = sync.NewCond(&q.mu)
+	for i, id := range ids {
+		q.tasks[i], _ = lookupTask(id)
 	}
-	close(taskChan)
-
-	q.startWorkers(taskChan)
 
 	startPipeline := time.Now()
+	q.schedule()
 	q.wg.Wait()
 
 	if !core.TuiMode && os.Getenv("REPOKIT_NESTED") != "1" {
 		totalDur := time.Since(startPipeline).Seconds()
-		if q.failed {
+		switch {
+		case q.interrupted:
+			fmt.Printf("\n  %s Pipeline interrupted | %s %.1fs\n\n", core.Yellow.Render("●"), core.Subtle.Render("⏱"), totalDur)
+		case q.failed:
 			fmt.Printf("\n  %s Pipeline completed with failures | %s %.1fs\n\n", core.Red.Render("●"), core.Subtle.Render("⏱"), totalDur)
-		} else {
+		default:
 			fmt.Printf("\n  %s Pipeline completed successfully | %s %.1fs\n\n", core.Green.Render("●"), core.Subtle.Render("⏱"), totalDur)
 		}
 	}
 
-	core.PublishEvent(core.EventPipelineDone, "pipeline", "")
-
-	if q.failed {
+	if q.failed || q.interrupted {
 		if os.Getenv("REPOKIT_NESTED") != "1" && !core.TuiMode {
+			if q.cause != "" && q.policy != core.Continue {
+				fmt.Printf("  %s Aborted by %s (%s)\n", core.Yellow.Render("⏹"), core.Bold.Render(q.cause), q.policy)
+			}
 			fmt.Println("\n" + core.Bold.Render("PIPELINE FAILED"))
 		}
 		// If in TUI mode, we rely on the TUI to handle the error presentation and exit gracefully
 		if !core.TuiMode {
-			os.Exit(1)
+			core.OSExit(1)
 		} else {
 			// Trigger a panic so our TUI's runner recovery grabs it
 			panic("pipeline failed")
@@ -71,33 +105,105 @@ func RunQueue(ids []string, workers int, continueOnError bool) {
 	}
 }
 
-func (q *queueContext) startWorkers(taskChan <-chan int) {
-	for i := 0; i < q.workers; i++ {
+// slots returns how much of the worker budget a task occupies.
+func (q *queueContext) slots(idx int) int {
+	return min(max(q.tasks[idx].Slots, 1), q.workers)
+}
+
+// runnable reports whether a task fits in the free slots without contending
+// for a lock held by a running task. The caller must hold q.mu.
+func (q *queueContext) runnable(idx int) bool {
+	if q.slots(idx) > q.free {
+		return false
+	}
+	for _, lock := range q.tasks[idx].Locks {
+		if q.held[lock] {
+			return false
+		}
+	}
+	return true
+}
+
+// schedule starts tasks in order as slots free up. A task that is blocked by
+// its weight or a lock is passed over so that later tasks can fill the free
+// workers. Once the batch is aborted the remaining tasks are dispatched
+// immediately so that they report as cancelled.
+func (q *queueContext) schedule() {
+	pending := make([]int, len(q.ids))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(pending) > 0 {
+		aborted := q.ctx.Err() != nil || (q.failed && q.policy != core.Continue)
+		next := -1
+		for i, idx := range pending {
+			if aborted || q.runnable(idx) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			q.slotFreed.Wait()
+			continue
+		}
+
+		idx := pending[next]
+		pending = append(pending[:next], pending[next+1:]...)
+		if !aborted {
+			q.acquire(idx)
+		}
+
 		q.wg.Add(1)
 		go func() {
 			defer q.wg.Done()
-			for idx := range taskChan {
-				q.processTask(idx)
+			q.processTask(idx)
+			if !aborted {
+				q.mu.Lock()
+				q.release(idx)
+				q.slotFreed.Broadcast()
+				q.mu.Unlock()
 			}
 		}()
 	}
 }
 
+// acquire and release claim and return a task's slots and locks. The
+// caller must hold q.mu.
+func (q *queueContext) acquire(idx int) {
+	q.free -= q.slots(idx)
+	for _, lock := range q.tasks[idx].Locks {
+		q.held[lock] = true
+	}
+}
+
+func (q *queueContext) release(idx int) {
+	q.free += q.slots(idx)
+	for _, lock := range q.tasks[idx].Locks {
+		delete(q.held, lock)
+	}
+}
+
 func (q *queueContext) processTask(idx int) {
-	id := q.ids[idx]
-	task, _ := core.GetTaskByID(id)
+	id, task := q.ids[idx], q.tasks[idx]
 
 	select {
 	case <-q.ctx.Done():
+		q.mu.Lock()
+		q.interrupted = true
+		q.mu.Unlock()
 		core.PublishEvent(core.EventTaskError, id, "cancelled")
 		return
 	default:
 	}
 
 	q.mu.Lock()
-	if q.failed && !q.continueOnError {
+	if q.failed && q.policy != core.Continue {
+		reason := q.cancelReason()
 		q.mu.Unlock()
-		core.PublishEvent(core.EventTaskError, id, "cancelled due to previous failure")
+		core.PublishEvent(core.EventTaskError, id, reason)
 		return
 	}
 	q.mu.Unlock()
@@ -105,45 +211,87 @@ func (q *queueContext) processTask(idx int) {
 	core.PublishEvent(core.EventTaskStart, id, task.Name)
 
 	var cmdStr string
-	if task.Type == "batch" || task.Type == "sequential" || len(task.Tasks) > 0 {
+	if runsNested(task) {
+		// Without the TUI the child joins this run's recording, which
+		// merges what it records instead of keeping a history of its own.
 		executable, _ := os.Executable()
 		if executable == "" {
 			executable = os.Args[0]
 		}
-		cmdStr = fmt.Sprintf("%q %q", executable, id)
+		cmdStr = fmt.Sprintf("%q --no-tui %q", executable, id)
 	} else {
 		cmdStr, _ = core.EvaluateCommand(task.Command, nil)
 	}
 
-	cmd := createCmd(q.ctx, cmdStr, task.Cwd)
-	cmd.Env = os.Environ()
-	if task.Type == "batch" || task.Type == "sequential" || len(task.Tasks) > 0 {
-		cmd.Env = append(cmd.Env, "REPOKIT_NESTED=1")
+	cmd := createCmd(q.batch, cmdStr, task.Cwd)
+	if runsNested(task) {
+		cmd.Env = append(cmd.Environ(), "REPOKIT_NESTED=1")
 	}
 
-	pr, pw, _ := os.Pipe()
-	cmd.Stdout, cmd.Stderr = pw, pw
+	log := openTaskLog(id, task)
+	defer log.Close()
+	problems := problemScanner(id, task)
 
-	go func() {
-		scanner := bufio.NewScanner(pr)
-		for scanner.Scan() {
-			line := scanner.Text()
-			core.PublishEvent(core.EventTaskLog, id, line)
-			if !core.TuiMode && !core.Quiet {
-				fmt.Printf("[%s] %s\n", task.Name, line)
+	output, err := attachOutput(cmd, func(line string, stream core.Stream, partial bool) {
+		core.PublishLog(id, stream, line, partial)
+		if partial {
+			return
+		}
+		log.WriteLine(line)
+		if p, ok := problems.Scan(line); ok {
+			core.PublishProblem(id, p)
+		}
+		if !core.TuiMode && !core.Quiet {
+			prefix := fmt.Sprintf("[%s]", task.Name)
+			if stream == core.StreamStderr {
+				prefix = core.Red.Render(prefix)
 			}
+			fmt.Printf("%s %s\n", prefix, line)
 		}
-	}()
-
-	err := cmd.Run()
-	_ = pw.Close()
+	})
+	if err == nil {
+		_, err = runMonitored(cmd, id)
+		output.finish()
+	}
 
-	if err != nil {
-		q.mu.Lock()
-		q.failed = true
-		q.mu.Unlock()
-		core.PublishEvent(core.EventTaskError, id, err.Error())
-	} else {
+	if err == nil {
+		artifacts := collectArtifacts(id, task)
 		core.PublishEvent(core.EventTaskDone, id, "")
+		if !core.TuiMode && !core.Quiet {
+			for _, a := range artifacts {
+				fmt.Printf("[%s] %s %s\n", task.Name, core.Subtle.Render("artifact"), a)
+			}
+		}
+		return
 	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.batch.Err() != nil {
+		// Terminated by an interrupt or a sibling's failure, not a failure of its own.
+		reason := "cancelled"
+		if q.ctx.Err() != nil {
+			q.interrupted = true
+		} else {
+			reason = q.cancelReason()
+		}
+		core.PublishEvent(core.EventTaskError, id, reason)
+		return
+	}
+
+	q.failed = true
+	if q.cause == "" {
+		q.cause = id
+	}
+	core.PublishEvent(core.EventTaskError, id, err.Error())
+	if q.policy == core.FailFast {
+		q.cancel()
+	}
+}
+
+// cancelReason describes why a task was skipped or terminated. It names the
+// task that caused the abort so that it can be shown next to every casualty.
+// The caller must hold q.mu.
+func (q *queueContext) cancelReason() string {
+	return fmt.Sprintf("cancelled: %s failed", q.cause)
 }
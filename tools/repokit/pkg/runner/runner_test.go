@@ -1,8 +1,20 @@
 package runner
 
 import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"repokit/pkg/core"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"testing/iotest"
+	"time"
+	"unicode/utf8"
 )
 
 func TestRunTask_NonExistent(t *testing.T) {
@@ -19,5 +31,479 @@ func TestRunTask_NonExistent(t *testing.T) {
 func TestRunQueue_Basic(t *testing.T) {
 	// Mock some tasks in the config if needed
 	// For now, test empty queue
-	RunQueue([]string{}, 1, false)
+	RunQueue([]string{}, 1, core.FailFast)
+}
+
+func TestParseProcStat(t *testing.T) {
+	line := "4242 (node (astro)) S 4200 4242 4242 0 -1 4194304 100 0 0 0 250 50 10 5 20 0 12 0 1000 123456789 2048 18446744073709551615"
+	st, err := parseProcStat(line)
+	if err != nil {
+		t.Fatalf("parseProcStat() error: %v", err)
+	}
+	if st.pgrp != 4242 {
+		t.Errorf("expected pgrp 4242, got %d", st.pgrp)
+	}
+	if st.cpuTime != 3150*time.Millisecond {
+		t.Errorf("expected cpu time 3.15s, got %v", st.cpuTime)
+	}
+	if st.rssPage != 2048 {
+		t.Errorf("expected 2048 RSS pages, got %d", st.rssPage)
+	}
+
+	if _, err := parseProcStat("garbage"); err == nil {
+		t.Error("expected error for malformed stat line")
+	}
+}
+
+func TestRunQueue_FailurePolicy(t *testing.T) {
+	originalExit, originalLookup := core.OSExit, lookupTask
+	defer func() { core.OSExit, lookupTask = originalExit, originalLookup }()
+	core.Quiet = true
+	defer func() { core.Quiet = false }()
+
+	exited := false
+	core.OSExit = func(int) { exited = true }
+	lookupTask = func(id string) (core.TaskConfig, error) {
+		commands := map[string]string{
+			"fail":  "sleep 0.2; exit 1",
+			"slow":  "sleep 2",
+			"quick": "true",
+		}
+		return core.TaskConfig{Name: id, Type: "single", Command: commands[id]}, nil
+	}
+
+	tests := []struct {
+		policy  string
+		want    map[string]string
+		maxTime time.Duration
+	}{
+		{core.FailFast, map[string]string{"fail": "error", "slow": "cancelled", "quick": "cancelled"}, 1500 * time.Millisecond},
+		{core.FinishRunning, map[string]string{"fail": "error", "slow": "done", "quick": "cancelled"}, 10 * time.Second},
+		{core.Continue, map[string]string{"fail": "error", "slow": "done", "quick": "done"}, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			exited = false
+			rec := core.StartRecording("batch")
+			start := time.Now()
+			RunQueue([]string{"fail", "slow", "quick"}, 2, tt.policy)
+			elapsed := time.Since(start)
+			run := rec.Finish("")
+
+			if !exited {
+				t.Error("expected the failed batch to exit non-zero")
+			}
+			if elapsed > tt.maxTime {
+				t.Errorf("batch took %v, want under %v", elapsed, tt.maxTime)
+			}
+			got := make(map[string]string)
+			for _, task := range run.Tasks {
+				got[task.ID] = task.Status
+			}
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("%s status = %q, want %q", id, got[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunQueue_Interrupted(t *testing.T) {
+	originalExit, originalLookup, originalNotify := core.OSExit, lookupTask, notifyContext
+	defer func() { core.OSExit, lookupTask, notifyContext = originalExit, originalLookup, originalNotify }()
+	core.Quiet = true
+	defer func() { core.Quiet = false }()
+
+	code := -1
+	core.OSExit = func(c int) { code = c }
+	lookupTask = func(id string) (core.TaskConfig, error) {
+		return core.TaskConfig{Name: id, Type: "single", Command: "sleep 2"}, nil
+	}
+	notifyContext = func() (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(200*time.Millisecond, cancel)
+		return ctx, cancel
+	}
+
+	rec := core.StartRecording("batch")
+	start := time.Now()
+	RunQueue([]string{"running", "queued"}, 1, core.Continue)
+	elapsed := time.Since(start)
+	run := rec.Finish("")
+
+	if code != 1 {
+		t.Errorf("expected the interrupted batch to exit 1, got %d", code)
+	}
+	if elapsed > 1500*time.Millisecond {
+		t.Errorf("batch took %v, want the interrupt to stop it", elapsed)
+	}
+	if len(run.Tasks) != 2 {
+		t.Fatalf("expected 2 recorded tasks, got %+v", run.Tasks)
+	}
+	for _, task := range run.Tasks {
+		if task.Status != "cancelled" {
+			t.Errorf("%s status = %q, want cancelled", task.ID, task.Status)
+		}
+	}
+}
+
+func TestRunQueue_LocksAndSlots(t *testing.T) {
+	originalLookup := lookupTask
+	defer func() { lookupTask = originalLookup }()
+
+	tasks := map[string]core.TaskConfig{
+		"prettier": {Command: "sleep 0.3", Locks: []string{"files"}},
+		"eslint":   {Command: "sleep 0.3", Locks: []string{"files"}},
+		"knip":     {Command: "sleep 0.3"},
+		"astro":    {Command: "sleep 0.3", Slots: 5},
+		"go_vet":   {Command: "sleep 0.3"},
+	}
+	lookupTask = func(id string) (core.TaskConfig, error) {
+		task := tasks[id]
+		task.Name, task.Type = id, "single"
+		return task, nil
+	}
+
+	overlap := func(run core.RunRecord, a, b string) bool {
+		spans := make(map[string][2]time.Time)
+		for _, task := range run.Tasks {
+			spans[task.ID] = [2]time.Time{task.Start, task.Start.Add(task.Elapsed)}
+		}
+		return spans[a][0].Before(spans[b][1]) && spans[b][0].Before(spans[a][1])
+	}
+
+	rec := core.StartRecording("lint")
+	RunQueue([]string{"prettier", "eslint", "knip"}, 3, core.FailFast)
+	run := rec.Finish("")
+	if overlap(run, "prettier", "eslint") {
+		t.Error("tasks sharing a lock ran concurrently")
+	}
+	if !overlap(run, "prettier", "knip") {
+		t.Error("expected an unlocked task to fill the free worker")
+	}
+
+	// astro's weight is capped at the worker budget, so it runs alone.
+	rec = core.StartRecording("check")
+	RunQueue([]string{"astro", "go_vet", "knip"}, 2, core.FailFast)
+	run = rec.Finish("")
+	if overlap(run, "astro", "go_vet") || overlap(run, "astro", "knip") {
+		t.Error("a task occupying every slot ran alongside another task")
+	}
+	if !overlap(run, "go_vet", "knip") {
+		t.Error("expected single-slot tasks to share the workers")
+	}
+}
+
+func TestRunQueue_LogFileAndArtifacts(t *testing.T) {
+	originalLookup, originalDir := lookupTask, core.HistoryDir
+	defer func() { lookupTask, core.HistoryDir = originalLookup, originalDir }()
+	core.HistoryDir = t.TempDir()
+	core.Quiet = true
+	defer func() { core.Quiet = false }()
+
+	cwd := t.TempDir()
+	lookupTask = func(id string) (core.TaskConfig, error) {
+		return core.TaskConfig{
+			Name:      id,
+			Type:      "single",
+			Cwd:       cwd,
+			Command:   "echo building; mkdir -p out/sub && echo report > out/sub/report.txt && echo bin > app",
+			LogFile:   "logs/" + id + ".log",
+			Artifacts: []string{"out", "app", "missing.*"},
+		}, nil
+	}
+
+	rec := core.StartRecording("build")
+	RunQueue([]string{"build"}, 1, core.FailFast)
+	run := rec.Finish("")
+
+	dir := core.RunDir(run.ID)
+	log, err := os.ReadFile(filepath.Join(dir, "logs", "build.log"))
+	if err != nil || string(log) != "building\n" {
+		t.Errorf("log file = %q, %v; want %q", log, err, "building\n")
+	}
+	for _, f := range []string{"artifacts/out/sub/report.txt", "artifacts/app"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("expected artifact %s: %v", f, err)
+		}
+	}
+
+	task := run.Tasks[0]
+	if task.LogFile != filepath.Join(dir, "logs", "build.log") || len(task.Artifacts) != 2 {
+		t.Errorf("unexpected task record: %+v", task)
+	}
+}
+
+func TestRunQueue_NestedJoinsRun(t *testing.T) {
+	originalExit, originalLookup := core.OSExit, lookupTask
+	defer func() { core.OSExit, lookupTask = originalExit, originalLookup }()
+	core.OSExit = func(int) {}
+	core.Quiet = true
+	defer func() { core.Quiet = false }()
+
+	// generate_schema is a pipeline, so the queue runs it as a nested
+	// repokit process: see TestMain. Its tasks fail in the empty directory,
+	// which does not matter to what is recorded.
+	t.Setenv("REPOKIT_TEST_CLI", "1")
+	t.Chdir(t.TempDir())
+	lookupTask = core.GetTaskByID
+
+	rec := core.StartRecording("batch")
+	RunQueue([]string{"generate_schema"}, 1, core.Continue)
+	run := rec.Finish("")
+
+	if _, err := os.Stat(filepath.Join(core.HistoryDir, "history.json")); !os.IsNotExist(err) {
+		t.Errorf("expected the nested process not to record a run of its own, got %v", err)
+	}
+	var ids []string
+	for _, task := range run.Tasks {
+		ids = append(ids, task.ID)
+	}
+	if len(ids) < 2 || ids[0] != "generate_schema" || ids[1] != "export_schema" {
+		t.Errorf("expected the nested tasks to be merged into the run, got %v", ids)
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	long := strings.Repeat("x", maxLineLength+10)
+	input := "one\r\ntwo\n10%\r50%\r100%\nno newline" + "\n" + long
+	type line struct {
+		text    string
+		partial bool
+	}
+	var got []line
+	err := splitLines(iotest.OneByteReader(strings.NewReader(input)), func(text string, partial bool) {
+		got = append(got, line{text, partial})
+	})
+	if err != nil {
+		t.Fatalf("splitLines() error: %v", err)
+	}
+
+	want := []line{
+		{"one", false}, {"two", false},
+		{"10%", true}, {"50%", true}, {"100%", false},
+		{"no newline", false},
+		{strings.Repeat("x", maxLineLength), false}, {"xxxxxxxxxx", false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %.20q (partial %v), want %.20q (partial %v)", i, got[i].text, got[i].partial, want[i].text, want[i].partial)
+		}
+	}
+}
+
+func TestSplitLines_RuneBoundary(t *testing.T) {
+	// The 3-byte rune starts two bytes before the limit.
+	prefix := strings.Repeat("x", maxLineLength-2)
+	input := prefix + "€yz\n"
+	var got []string
+	err := splitLines(iotest.OneByteReader(strings.NewReader(input)), func(text string, partial bool) {
+		got = append(got, text)
+	})
+	if err != nil {
+		t.Fatalf("splitLines() error: %v", err)
+	}
+	if len(got) != 2 || got[0] != prefix || got[1] != "€yz" {
+		t.Fatalf("got %d lines ending %.10q, want the rune moved whole to the second line", len(got), got[len(got)-1])
+	}
+	for i, line := range got {
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d is not valid UTF-8", i)
+		}
+	}
+}
+
+func TestAttachOutput(t *testing.T) {
+	cmd := createCmd(context.Background(), "echo out1; echo err1 >&2; echo out2; printf 'err2' >&2", "")
+	var mu sync.Mutex
+	lines := make(map[core.Stream][]string)
+	output, err := attachOutput(cmd, func(line string, stream core.Stream, partial bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines[stream] = append(lines[stream], line)
+	})
+	if err != nil {
+		t.Fatalf("attachOutput() error: %v", err)
+	}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	output.finish()
+
+	if got := strings.Join(lines[core.StreamStdout], ","); got != "out1,out2" {
+		t.Errorf("stdout = %q, want %q", got, "out1,out2")
+	}
+	if got := strings.Join(lines[core.StreamStderr], ","); got != "err1,err2" {
+		t.Errorf("stderr = %q, want %q", got, "err1,err2")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	originalLookup := lookupTask
+	defer func() { lookupTask = originalLookup }()
+
+	tasks := map[string]core.TaskConfig{
+		"all":     {Name: "All", Type: "batch", Tasks: []string{"build", "checks", "build"}},
+		"build":   {Name: "Build", Type: "single", Command: "make {{.Target}}", Cwd: "src", PreRun: []string{"deps"}},
+		"deps":    {Name: "Deps", Type: "single", Command: "install"},
+		"checks":  {Name: "Checks", Type: "batch", Parallel: true, Workers: 2, OnFailure: core.FinishRunning, Tasks: []string{"lint", "nested", "missing"}},
+		"lint":    {Name: "Lint", Type: "single", Command: "lint", PreRun: []string{"deps"}, Slots: 5, Locks: []string{"src"}},
+		"nested":  {Name: "Nested", Type: "sequential", Tasks: []string{"deps", "checks"}},
+		"badtmpl": {Name: "Bad", Type: "single", Command: "{{.Nope"},
+	}
+	lookupTask = func(id string) (core.TaskConfig, error) {
+		task, ok := tasks[id]
+		if !ok {
+			return core.TaskConfig{}, os.ErrNotExist
+		}
+		return task, nil
+	}
+
+	plan := Plan("all", nil)
+	if len(plan.Tasks) != 3 || plan.Parallel {
+		t.Fatalf("expected 3 sequential children, got %+v", plan)
+	}
+
+	build := plan.Tasks[0]
+	if build.Cwd != "src" || len(build.PreRun) != 1 || build.PreRun[0].ID != "deps" {
+		t.Errorf("build should run in src after its deps hook, got %+v", build)
+	}
+	if build.Command != "make {{.Target}}" {
+		t.Errorf("without data the command is used verbatim, got %q", build.Command)
+	}
+	if got := Plan("build", map[string]string{"Target": "dist"}).Command; got != "make dist" {
+		t.Errorf("command template rendered as %q", got)
+	}
+	if again := plan.Tasks[2]; again.Skipped == "" || again.Command != "" {
+		t.Errorf("the second build should be skipped as already run, got %+v", again)
+	}
+
+	checks := plan.Tasks[1]
+	if !checks.Parallel || checks.Workers != 2 || checks.OnFailure != core.FinishRunning {
+		t.Fatalf("checks should be a parallel batch, got %+v", checks)
+	}
+	lint, nested, missing := checks.Tasks[0], checks.Tasks[1], checks.Tasks[2]
+	if len(lint.PreRun) != 0 || lint.Slots != 2 || len(lint.Locks) != 1 {
+		t.Errorf("queued tasks run without hooks and with capped slots, got %+v", lint)
+	}
+	if !nested.Nested || !slices.Contains(nested.Env, "REPOKIT_NESTED=1") {
+		t.Errorf("pipelines in a batch run as a nested process, got %+v", nested)
+	}
+	// The nested process starts with a fresh visited set, so deps runs again,
+	// but expanding checks inside itself is a cycle.
+	if nested.Tasks[0].Skipped != "" || nested.Tasks[1].Skipped == "" {
+		t.Errorf("unexpected nested plan: deps %+v, checks %+v", nested.Tasks[0], nested.Tasks[1])
+	}
+	if missing.Error == "" {
+		t.Error("expected an error for a missing task")
+	}
+
+	if bad := Plan("badtmpl", struct{}{}); !strings.HasPrefix(bad.Error, "template error") {
+		t.Errorf("expected a template error, got %+v", bad)
+	}
+}
+
+func TestRunInteractive_ExitStatus(t *testing.T) {
+	originalExit, originalDir := core.OSExit, core.HistoryDir
+	defer func() { core.OSExit, core.HistoryDir = originalExit, originalDir }()
+	core.HistoryDir = t.TempDir()
+	core.Quiet = true
+	defer func() { core.Quiet = false }()
+
+	exitCode := -1
+	core.OSExit = func(code int) { exitCode = code }
+
+	rec := core.StartRecording("interactive")
+	RunInteractive("ok", "OK", "true", ".")
+	func() {
+		// The mocked exit returns, so the failure continues into a panic.
+		defer func() { _ = recover() }()
+		RunInteractive("bad", "Bad", "exit 3", ".")
+	}()
+	run := rec.Finish("")
+
+	if exitCode != 1 {
+		t.Errorf("expected a failed session to exit with 1, got %d", exitCode)
+	}
+	if len(run.Tasks) != 2 || run.Tasks[0].Status != "done" || run.Tasks[1].Status != "error" {
+		t.Fatalf("unexpected task records: %+v", run.Tasks)
+	}
+}
+
+func TestInteractiveCmd_ForwardsSignals(t *testing.T) {
+	c := newInteractiveCmd("trap 'exit 7' INT; while true; do sleep 0.1; done", ".")
+	c.SetStdin(strings.NewReader("")) // not a file: keeps the original stdin
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+	}()
+	err := c.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 7 {
+		t.Fatalf("expected the forwarded SIGINT to exit with 7, got %v", err)
+	}
+}
+
+type testNative struct {
+	id  string
+	run func(p core.Params) (core.Result, error)
+}
+
+func (c testNative) ID() string          { return c.id }
+func (c testNative) Name() string        { return "Test " + c.id }
+func (c testNative) Description() string { return "" }
+func (c testNative) Params() []core.Param {
+	return []core.Param{{Name: "out", Default: "default.txt"}}
+}
+func (c testNative) Run(ctx context.Context, p core.Params) (core.Result, error) {
+	return c.run(p)
+}
+
+func TestRunNative(t *testing.T) {
+	originalExit, originalDir := core.OSExit, core.HistoryDir
+	defer func() { core.OSExit, core.HistoryDir = originalExit, originalDir }()
+	core.HistoryDir = t.TempDir()
+	core.Quiet = true
+	defer func() { core.Quiet = false }()
+
+	exitCode := -1
+	core.OSExit = func(code int) { exitCode = code }
+
+	if _, ok := core.LookupNative("test_native_write"); !ok {
+		core.RegisterNative(testNative{id: "test_native_write", run: func(p core.Params) (core.Result, error) {
+			return core.Result{Outputs: []string{p["out"]}}, os.WriteFile(p["out"], []byte("ok"), 0644)
+		}})
+		core.RegisterNative(testNative{id: "test_native_fail", run: func(p core.Params) (core.Result, error) {
+			return core.Result{}, errors.New("boom")
+		}})
+	}
+
+	rec := core.StartRecording("native")
+	RunNative("test_native_write", map[string]string{"out": filepath.Join(t.TempDir(), "result.txt")})
+	func() {
+		// The mocked exit returns, so the failure continues into a panic.
+		defer func() { _ = recover() }()
+		RunTask("test_native_fail", nil, nil)
+	}()
+	run := rec.Finish("")
+
+	if exitCode != 1 {
+		t.Errorf("expected a failed command to exit with 1, got %d", exitCode)
+	}
+	if len(run.Tasks) != 2 || run.Tasks[0].Status != "done" || run.Tasks[1].Status != "error" {
+		t.Fatalf("unexpected task records: %+v", run.Tasks)
+	}
+	artifact := filepath.Join(core.RunDir(run.ID), "artifacts", "result.txt")
+	if _, err := os.Stat(artifact); err != nil {
+		t.Errorf("expected the output to be collected: %v", err)
+	}
+	if got := run.Tasks[0].Artifacts; len(got) != 1 || got[0] != artifact {
+		t.Errorf("artifacts = %v, want [%s]", got, artifact)
+	}
 }
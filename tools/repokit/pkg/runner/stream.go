@@ -0,0 +1,159 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"repokit/pkg/core"
+)
+
+// ─── Output Streaming ────────────────────────────────────────────────────────
+
+const (
+	// maxLineLength bounds a single line of output. Longer lines, such as
+	// minified JSON, are split rather than dropped, at a rune boundary.
+	maxLineLength = 1 << 20
+
+	readChunkSize = 32 * 1024
+
+	// drainTimeout is how long to wait for the readers after the command has
+	// exited. A background process left behind by the task may hold the pipes
+	// open, so they are only given a moment to drain what was written.
+	drainTimeout = time.Second
+)
+
+// lineHandler receives each line of output. partial marks an in-place update
+// terminated by a bare \r, which the next line of the same stream replaces.
+type lineHandler func(line string, stream core.Stream, partial bool)
+
+// splitLines reads r to EOF and calls emit for every line. Lines end at \n,
+// \r\n or a bare \r; the trailing terminator is removed.
+func splitLines(r io.Reader, emit func(line string, partial bool)) error {
+	var line []byte
+	pendingCR := false
+	flush := func(partial bool) {
+		emit(string(line), partial)
+		line = line[:0]
+	}
+
+	buf := make([]byte, readChunkSize)
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			if pendingCR {
+				pendingCR = false
+				if b == '\n' {
+					flush(false)
+					continue
+				}
+				flush(true)
+			}
+			switch b {
+			case '\n':
+				flush(false)
+			case '\r':
+				pendingCR = true
+			default:
+				line = append(line, b)
+				if len(line) >= maxLineLength {
+					cut := runeCut(line)
+					rest := append([]byte(nil), line[cut:]...)
+					line = line[:cut]
+					flush(false)
+					line = append(line, rest...)
+				}
+			}
+		}
+		if err != nil {
+			if pendingCR || len(line) > 0 {
+				flush(false)
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// runeCut returns where to split an overlong line so that a multi-byte rune
+// whose bytes have not all been read yet moves to the next line whole.
+func runeCut(line []byte) int {
+	start := len(line) - 1
+	for start > 0 && len(line)-start < utf8.UTFMax && !utf8.RuneStart(line[start]) {
+		start--
+	}
+	if start > 0 && !utf8.FullRune(line[start:]) {
+		return start
+	}
+	return len(line)
+}
+
+// outputPipes connects a command's stdout and stderr to concurrent readers.
+type outputPipes struct {
+	readers []*os.File
+	writers []*os.File
+	wg      sync.WaitGroup
+}
+
+// attachOutput gives cmd separate stdout and stderr pipes and starts a
+// reader for each, so that lines keep their order within a stream and
+// neither stream blocks the other.
+func attachOutput(cmd *exec.Cmd, handle lineHandler) (*outputPipes, error) {
+	p := &outputPipes{}
+	for _, stream := range []core.Stream{core.StreamStdout, core.StreamStderr} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			p.close()
+			return nil, err
+		}
+		p.readers = append(p.readers, r)
+		p.writers = append(p.writers, w)
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			_ = splitLines(r, func(line string, partial bool) { handle(line, stream, partial) })
+		}()
+	}
+	cmd.Stdout, cmd.Stderr = p.writers[0], p.writers[1]
+	return p, nil
+}
+
+// finish closes this process's copy of the write ends once the command has
+// exited and waits for the readers to consume the remaining output.
+func (p *outputPipes) finish() {
+	for _, w := range p.writers {
+		_ = w.Close()
+	}
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(drainTimeout):
+	}
+	p.close()
+}
+
+func (p *outputPipes) close() {
+	for _, f := range append(p.readers, p.writers...) {
+		_ = f.Close()
+	}
+}
+
+// trimPrintable shortens a line for the headless log view.
+func trimPrintable(line string, width int) string {
+	clean := core.CleanANSI(line)
+	if len(clean) > width {
+		clean = clean[:width-3] + "..."
+	}
+	return string(bytes.ToValidUTF8([]byte(clean), nil))
+}
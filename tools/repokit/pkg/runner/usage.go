@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"repokit/pkg/core"
+)
+
+const (
+	usageInterval = 500 * time.Millisecond
+	clockTicks    = 100 // USER_HZ, the unit of the time fields in /proc/<pid>/stat
+)
+
+// procStat holds the fields of /proc/<pid>/stat the usage monitor cares about.
+type procStat struct {
+	pgrp    int
+	cpuTime time.Duration // utime + stime + cutime + cstime
+	rssPage int64
+}
+
+// parseProcStat extracts the process group, CPU time and RSS from a /proc/<pid>/stat line.
+func parseProcStat(line string) (procStat, error) {
+	// The command name is wrapped in parentheses and may itself contain spaces or ')'.
+	end := strings.LastIndexByte(line, ')')
+	if end < 0 {
+		return procStat{}, fmt.Errorf("malformed stat line")
+	}
+	fields := strings.Fields(line[end+1:])
+	// fields[0] is field 3 (state) of proc(5).
+	if len(fields) < 22 {
+		return procStat{}, fmt.Errorf("malformed stat line: %d fields", len(fields))
+	}
+
+	pgrp, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return procStat{}, err
+	}
+	var ticks int64
+	for _, f := range fields[11:15] {
+		n, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return procStat{}, err
+		}
+		ticks += n
+	}
+	rss, err := strconv.ParseInt(fields[21], 10, 64)
+	if err != nil {
+		return procStat{}, err
+	}
+
+	return procStat{
+		pgrp:    pgrp,
+		cpuTime: time.Duration(ticks) * time.Second / clockTicks,
+		rssPage: rss,
+	}, nil
+}
+
+// usageMonitor periodically samples a process group and publishes usage events.
+type usageMonitor struct {
+	key   string
+	pgid  int
+	usage core.ResourceUsage
+	mu    sync.Mutex
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func startUsageMonitor(key string, pgid int) *usageMonitor {
+	m := &usageMonitor{key: key, pgid: pgid, done: make(chan struct{})}
+	m.wg.Add(1)
+	go m.loop()
+	return m
+}
+
+func (m *usageMonitor) loop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(usageInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-m.done:
+			return
+		case now := <-ticker.C:
+			cpu, rss, ok := sampleProcessGroup(m.pgid)
+			if !ok {
+				continue
+			}
+			m.mu.Lock()
+			if cpu > m.usage.CPUTime {
+				m.usage.CPUPercent = 100 * float64(cpu-m.usage.CPUTime) / float64(now.Sub(last))
+				m.usage.CPUTime = cpu
+			} else {
+				m.usage.CPUPercent = 0
+			}
+			m.usage.RSS = rss
+			m.usage.PeakRSS = max(m.usage.PeakRSS, rss)
+			usage := m.usage
+			m.mu.Unlock()
+
+			last = now
+			core.PublishUsage(m.key, usage)
+		}
+	}
+}
+
+// stop ends sampling and returns the final usage, folding in the kernel's
+// accounting for the group leader and its reaped children.
+func (m *usageMonitor) stop(cmd *exec.Cmd) core.ResourceUsage {
+	close(m.done)
+	m.wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ps := cmd.ProcessState; ps != nil {
+		m.usage.CPUTime = max(m.usage.CPUTime, ps.UserTime()+ps.SystemTime())
+		m.usage.PeakRSS = max(m.usage.PeakRSS, maxRSS(ps))
+	}
+	m.usage.CPUPercent, m.usage.RSS = 0, 0
+	core.PublishUsage(m.key, m.usage)
+	return m.usage
+}
+
+// runMonitored starts cmd and samples its process group until it exits.
+// The command must have been created by createCmd so that it leads its own group.
+func runMonitored(cmd *exec.Cmd, key string) (core.ResourceUsage, error) {
+	if err := cmd.Start(); err != nil {
+		return core.ResourceUsage{}, err
+	}
+	mon := startUsageMonitor(key, cmd.Process.Pid)
+	err := cmd.Wait()
+	return mon.stop(cmd), err
+}
+
+// formatUsage renders a short "peak / cpu" summary for headless output.
+func formatUsage(u core.ResourceUsage) string {
+	if u.PeakRSS == 0 && u.CPUTime == 0 {
+		return ""
+	}
+	return fmt.Sprintf("peak %s · cpu %.1fs", core.FormatBytes(int64(u.PeakRSS)), u.CPUTime.Seconds())
+}
@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// sampleProcessGroup sums CPU time and RSS of every live process in the group.
+func sampleProcessGroup(pgid int) (cpu time.Duration, rss uint64, ok bool) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, 0, false
+	}
+
+	pageSize := uint64(os.Getpagesize())
+	for _, e := range entries {
+		if _, err := strconv.Atoi(e.Name()); err != nil {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join("/proc", e.Name(), "stat"))
+		if err != nil {
+			continue // process exited between ReadDir and ReadFile
+		}
+		st, err := parseProcStat(string(raw))
+		if err != nil || st.pgrp != pgid {
+			continue
+		}
+		cpu += st.cpuTime
+		rss += uint64(st.rssPage) * pageSize
+		ok = true
+	}
+	return cpu, rss, ok
+}
+
+// maxRSS returns the peak RSS reported by wait4, which Linux reports in kilobytes.
+func maxRSS(ps *os.ProcessState) uint64 {
+	if ru, ok := ps.SysUsage().(*syscall.Rusage); ok && ru.Maxrss > 0 {
+		return uint64(ru.Maxrss) * 1024
+	}
+	return 0
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package runner
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// sampleProcessGroup is only implemented on Linux, where /proc is available.
+func sampleProcessGroup(int) (time.Duration, uint64, bool) {
+	return 0, 0, false
+}
+
+// maxRSS returns the peak RSS reported by wait4; BSD-derived systems report bytes.
+func maxRSS(ps *os.ProcessState) uint64 {
+	if ru, ok := ps.SysUsage().(*syscall.Rusage); ok && ru.Maxrss > 0 {
+		return uint64(ru.Maxrss)
+	}
+	return 0
+}
@@ -0,0 +1,433 @@
+package svg
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"repokit/pkg/core"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/tdewolff/minify/v2"
+	svg_minifier "github.com/tdewolff/minify/v2/svg"
+)
+
+// ─── Components ─────────────────────────────────────────────────────────────
+
+// Frameworks are the component frameworks GenerateComponents supports.
+var Frameworks = []string{"astro", "react"}
+
+// GeneratedHeader starts every generated component, which tells them apart
+// from hand-written files in the same directory.
+const GeneratedHeader = "Generated by repokit svg components"
+
+// GeneratedFile is a file to write, its name relative to the output
+// directory.
+type GeneratedFile struct {
+	Name    string
+	Content string
+}
+
+// GenerateComponents optimizes SVG files and turns each into a component
+// of the framework, taking size, class, title and color props, along with
+// an index.ts re-exporting them all. Colors of monochrome icons become
+// currentColor, so that the color prop, which sets the SVG color attribute,
+// and CSS colors apply. The IDs of each icon are prefixed with its name so
+// that icons inlined into the same page cannot collide. The output only
+// depends on the files, so generating again gives the same files.
+func GenerateComponents(files []string, framework string, opts Options) ([]GeneratedFile, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	var write func(c component) string
+	var ext, export string
+	switch framework {
+	case "astro":
+		write, ext, export = astroComponent, ".astro", "export { default as %s } from \"./%s.astro\";\n"
+	case "react":
+		write, ext, export = reactComponent, ".tsx", "export { %s } from \"./%s\";\n"
+	default:
+		return nil, fmt.Errorf("unknown framework %q (expected %s)", framework, strings.Join(Frameworks, " or "))
+	}
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+
+	m := minify.New()
+	m.Add("image/svg+xml", &svg_minifier.Minifier{})
+
+	var out []GeneratedFile
+	var index strings.Builder
+	fmt.Fprintf(&index, "// %s. Do not edit.\n\n", GeneratedHeader)
+	owners := make(map[string]string)
+	for _, path := range files {
+		slug := core.Slugify(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		if slug == "" {
+			return nil, fmt.Errorf("%s: no component name can be derived from the file name", path)
+		}
+		if other, taken := owners[slug]; taken {
+			return nil, fmt.Errorf("%s and %s would both be component %q", other, path, slug)
+		}
+		owners[slug] = path
+
+		root, err := optimizedRoot(path, opts)
+		if err != nil {
+			return nil, err
+		}
+		if hasElement(root, "style", "script") {
+			return nil, fmt.Errorf("%s: style sheets and scripts cannot be inlined into a component", path)
+		}
+		// Minifying once more shortens what the passes leave, such as
+		// colors and path data.
+		data, err := m.Bytes("image/svg+xml", root.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to minify: %w", path, err)
+		}
+		doc, err := ParseDocument(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		root = rootElement(doc)
+
+		prefixIDs(root, slug+"_")
+		if isMonochrome(root) {
+			useCurrentColor(root)
+		}
+		c := component{
+			Name:   componentName(slug),
+			Source: filepath.ToSlash(displayPath(path)),
+			Root:   root,
+		}
+		out = append(out, GeneratedFile{Name: slug + ext, Content: write(c)})
+		fmt.Fprintf(&index, export, c.Name, slug)
+	}
+	out = append(out, GeneratedFile{"index.ts", index.String()})
+	return out, nil
+}
+
+// component is an icon to generate a component for.
+type component struct {
+	Name   string // identifier, such as ArrowLeftIcon
+	Source string // path of the SVG file
+	Root   *Node
+}
+
+// componentName turns a slug into a PascalCase identifier ending in Icon.
+func componentName(slug string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(slug, "-") {
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	name := sb.String()
+	if unicode.IsDigit(rune(name[0])) {
+		name = "Icon" + name
+	}
+	return name + "Icon"
+}
+
+// ─── Colors ─────────────────────────────────────────────────────────────────
+
+// paintAttrs are the attributes that set colors.
+var paintAttrs = []string{"fill", "stroke", "color", "stop-color", "flood-color", "lighting-color"}
+
+// isMonochrome reports whether an icon paints with at most one color, in
+// attributes the generator can rewrite: no gradients, patterns or inline
+// styles. Shapes without a fill paint with the default black.
+func isMonochrome(root *Node) bool {
+	colors := make(map[string]bool)
+	mono := true
+	root.Walk(func(n *Node) bool {
+		if _, styled := n.Attr("style"); styled {
+			mono = false
+		}
+		for _, name := range paintAttrs {
+			v, ok := n.Attr(name)
+			if !ok {
+				continue
+			}
+			switch c := normalizeColor(v); {
+			case strings.HasPrefix(c, "url("):
+				mono = false
+			case c != "none" && c != "currentcolor" && c != "inherit" && c != "transparent":
+				colors[c] = true
+			}
+		}
+		return mono
+	})
+	defaultFilled(root, func(*Node) {
+		colors["#000000"] = true
+	})
+	return mono && len(colors) <= 1
+}
+
+// filledShapes are the elements that paint a fill.
+var filledShapes = map[string]bool{
+	"path": true, "rect": true, "circle": true, "ellipse": true, "polyline": true,
+	"polygon": true, "text": true,
+}
+
+var fillDeclRegex = regexp.MustCompile(`(?i)(^|;)\s*fill\s*:`)
+
+// defaultFilled calls fn for every shape below root that neither sets a
+// fill nor inherits one, and so paints with the default black. The content
+// of clip paths, whose fill is never painted, is skipped. Documents with
+// style sheets are skipped entirely, since their rules may set the fill.
+func defaultFilled(root *Node, fn func(n *Node)) {
+	if hasElement(root, "style") {
+		return
+	}
+	var walk func(n *Node, filled bool)
+	walk = func(n *Node, filled bool) {
+		name, _ := svgName(n)
+		if name == "clipPath" {
+			return
+		}
+		if v, ok := n.Attr("fill"); ok && normalizeColor(v) != "inherit" {
+			filled = true
+		}
+		if style, ok := n.Attr("style"); ok && fillDeclRegex.MatchString(style) {
+			filled = true
+		}
+		if !filled && filledShapes[name] {
+			fn(n)
+		}
+		for _, c := range n.Children {
+			if c.Type == ElementNode {
+				walk(c, filled)
+			}
+		}
+	}
+	walk(root, false)
+}
+
+// useCurrentColor replaces the color of a monochrome icon with
+// currentColor. Icons that rely on the default black fill get a fill of
+// currentColor on the root.
+func useCurrentColor(root *Node) {
+	root.Walk(func(n *Node) bool {
+		for i, a := range n.Attrs {
+			if !containsString(paintAttrs, a.Name) {
+				continue
+			}
+			switch normalizeColor(a.Value) {
+			case "none", "currentcolor", "inherit", "transparent":
+			default:
+				n.Attrs[i].Value = "currentColor"
+			}
+		}
+		return true
+	})
+	if _, ok := root.Attr("fill"); !ok {
+		root.SetAttr("fill", "currentColor")
+	}
+}
+
+// normalizeColor lowercases a color and expands #rgb to #rrggbb, so that
+// spellings of the same color compare equal.
+func normalizeColor(v string) string {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if len(v) == 4 && v[0] == '#' {
+		v = string([]byte{'#', v[1], v[1], v[2], v[2], v[3], v[3]})
+	}
+	switch v {
+	case "black":
+		return "#000000"
+	case "white":
+		return "#ffffff"
+	}
+	return v
+}
+
+func containsString(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ─── Astro ──────────────────────────────────────────────────────────────────
+
+func astroComponent(c component) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "---\n// %s from %s. Do not edit.\nimport type { HTMLAttributes } from \"astro/types\";\n\n", GeneratedHeader, c.Source)
+	sb.WriteString(`interface Props extends HTMLAttributes<"svg"> {
+  color?: string;
+  size?: number | string;
+  title?: string;
+}
+
+const { size = 24, title, ...props } = Astro.props;
+---
+
+<svg
+`)
+	for _, a := range rootAttrs(c.Root) {
+		fmt.Fprintf(&sb, "  %s=%s\n", a.Name, quoteAttr(a.Value))
+	}
+	sb.WriteString(`  width={size}
+  height={size}
+  role={title ? "img" : undefined}
+  aria-hidden={title ? undefined : "true"}
+  {...props}
+>
+  {title && <title>{title}</title>}
+`)
+	for _, n := range content(c.Root) {
+		sb.WriteString("  ")
+		writeMarkup(&sb, n, false)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// ─── React ──────────────────────────────────────────────────────────────────
+
+func reactComponent(c component) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// %s from %s. Do not edit.\nimport type { SVGProps } from \"react\";\n\n", GeneratedHeader, c.Source)
+	fmt.Fprintf(&sb, `export interface %[1]sProps extends SVGProps<SVGSVGElement> {
+  color?: string;
+  size?: number | string;
+  title?: string;
+}
+
+export const %[1]s = ({ size = 24, title, ...props }: %[1]sProps) => (
+  <svg
+`, c.Name)
+	for _, a := range rootAttrs(c.Root) {
+		fmt.Fprintf(&sb, "    %s=%s\n", jsxAttrName(a.Name), jsxAttrValue(a))
+	}
+	sb.WriteString(`    width={size}
+    height={size}
+    role={title ? "img" : undefined}
+    aria-hidden={title ? undefined : true}
+    {...props}
+  >
+    {title && <title>{title}</title>}
+`)
+	for _, n := range content(c.Root) {
+		sb.WriteString("    ")
+		writeMarkup(&sb, n, true)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("  </svg>\n);\n")
+	return sb.String()
+}
+
+// jsxAttrName returns the React prop name of an SVG attribute: camelCase,
+// except for ARIA and data attributes.
+func jsxAttrName(name string) string {
+	switch {
+	case name == "class":
+		return "className"
+	case strings.HasPrefix(name, "aria-"), strings.HasPrefix(name, "data-"):
+		return name
+	}
+	var sb strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '-' || r == ':' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// jsxAttrValue writes an attribute value as a JSX prop value. Inline styles
+// become objects.
+func jsxAttrValue(a Attr) string {
+	if a.Name != "style" {
+		return quoteAttr(a.Value)
+	}
+	var props []string
+	for _, decl := range strings.Split(a.Value, ";") {
+		prop, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		key, _ := json.Marshal(jsxAttrName(strings.TrimSpace(prop)))
+		val, _ := json.Marshal(strings.TrimSpace(value))
+		props = append(props, string(key)+": "+string(val))
+	}
+	return "{{ " + strings.Join(props, ", ") + " }}"
+}
+
+// ─── Markup ─────────────────────────────────────────────────────────────────
+
+// rootAttrs returns the attributes of an icon's root element that its
+// component keeps: the viewBox, derived from a plain width and height if
+// there is none, and the attributes its content inherits.
+func rootAttrs(root *Node) []Attr {
+	attrs := []Attr{{Name: "xmlns", Value: "http://www.w3.org/2000/svg"}}
+	if viewBox, ok := viewBoxOf(root); ok {
+		attrs = append(attrs, Attr{Name: "viewBox", Value: viewBox})
+	}
+	for _, a := range root.Attrs {
+		if inheritedAttrs[a.Name] || a.Name == "preserveAspectRatio" {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// content returns the elements of an icon's root, without the title and
+// description the title prop replaces.
+func content(root *Node) []*Node {
+	var out []*Node
+	for _, c := range root.Children {
+		if name, _ := svgName(c); isBlank(c) || name == "title" || name == "desc" {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// quoteAttr writes an attribute value in double quotes, or as a JavaScript
+// string expression if it contains characters the templates would read
+// differently.
+func quoteAttr(v string) string {
+	if strings.ContainsAny(v, "\"{}&") {
+		s, _ := json.Marshal(v)
+		return "{" + string(s) + "}"
+	}
+	return `"` + v + `"`
+}
+
+// writeMarkup writes an element of an icon on one line, as Astro markup or,
+// with jsx set, as JSX. Braces in text, which both read as expressions,
+// are escaped.
+func writeMarkup(sb *strings.Builder, n *Node, jsx bool) {
+	switch n.Type {
+	case ElementNode:
+		sb.WriteString("<" + n.Name)
+		for _, a := range n.Attrs {
+			if jsx {
+				sb.WriteString(" " + jsxAttrName(a.Name) + "=" + jsxAttrValue(a))
+			} else {
+				sb.WriteString(" " + a.Name + "=" + quoteAttr(a.Value))
+			}
+		}
+		if len(n.Children) == 0 {
+			sb.WriteString(" />")
+			return
+		}
+		sb.WriteString(">")
+		for _, c := range n.Children {
+			writeMarkup(sb, c, jsx)
+		}
+		sb.WriteString("</" + n.Name + ">")
+	case TextNode:
+		sb.WriteString(strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "{", "&#123;", "}", "&#125;").Replace(n.Text))
+	}
+}
@@ -0,0 +1,141 @@
+package svg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// ─── Diff Output ────────────────────────────────────────────────────────────
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// prettyPrint lays an SVG document out one element per line and one
+// attribute per line, so that diffs point at the attribute that changed
+// rather than at a single minified line. Documents that do not parse are
+// returned as they are.
+func prettyPrint(data []byte) string {
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return string(data)
+	}
+	var sb strings.Builder
+	for _, c := range doc.Children {
+		writePretty(&sb, c, 0)
+	}
+	return sb.String()
+}
+
+func writePretty(sb *strings.Builder, n *Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch n.Type {
+	case ElementNode:
+		sb.WriteString(indent + "<" + n.Name + "\n")
+		for _, a := range n.Attrs {
+			var v strings.Builder
+			_ = xml.EscapeText(&v, []byte(a.Value))
+			sb.WriteString(indent + "    " + a.Name + `="` + v.String() + "\"\n")
+		}
+		if len(n.Children) == 0 {
+			sb.WriteString(indent + "/>\n")
+			return
+		}
+		sb.WriteString(indent + ">\n")
+		for _, c := range n.Children {
+			writePretty(sb, c, depth+1)
+		}
+		sb.WriteString(indent + "</" + n.Name + ">\n")
+	case TextNode:
+		if text := strings.TrimSpace(n.Text); text != "" {
+			t := &Node{Type: TextNode, Text: text}
+			sb.WriteString(indent + string(t.Bytes()) + "\n")
+		}
+	default:
+		sb.WriteString(indent + string(n.Bytes()) + "\n")
+	}
+}
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// unifiedDiff returns the differences between two texts in unified diff
+// format, or an empty string when they are equal.
+func unifiedDiff(name, before, after string) string {
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(before, after)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+
+	var all []diffLine
+	for _, d := range diffs {
+		op := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+		for _, l := range strings.SplitAfter(d.Text, "\n") {
+			if l != "" {
+				all = append(all, diffLine{op, strings.TrimSuffix(l, "\n")})
+			}
+		}
+	}
+
+	var sb strings.Builder
+	aLine, bLine := 0, 0 // lines of each side before index i
+	for i := 0; i < len(all); {
+		if all[i].op == ' ' {
+			aLine, bLine = aLine+1, bLine+1
+			i++
+			continue
+		}
+
+		// Extend the hunk while the next change is close enough for
+		// their context lines to touch.
+		start := max(0, i-diffContext)
+		end := i
+		for j := i; j < len(all) && j <= end+2*diffContext; j++ {
+			if all[j].op != ' ' {
+				end = j
+			}
+		}
+		end = min(len(all), end+diffContext+1)
+
+		aStart, bStart := aLine-(i-start), bLine-(i-start)
+		var aCount, bCount int
+		var body strings.Builder
+		for _, l := range all[start:end] {
+			if l.op != '+' {
+				aCount++
+			}
+			if l.op != '-' {
+				bCount++
+			}
+			body.WriteString(string(l.op) + l.text + "\n")
+		}
+
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", name, name)
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		sb.WriteString(body.String())
+
+		aLine, bLine = aStart+aCount, bStart+bCount
+		i = end
+	}
+	return sb.String()
+}
+
+// hunkRange formats the line range of one side of a hunk. An empty range
+// names the line after which it would be.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,257 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ─── Document Model ─────────────────────────────────────────────────────────
+
+// NodeType identifies what a Node holds.
+type NodeType int
+
+const (
+	DocumentNode NodeType = iota
+	ElementNode
+	TextNode
+	CommentNode
+	ProcInstNode
+	DirectiveNode
+)
+
+// Attr is an attribute as written in the source, its name including any
+// namespace prefix ("xlink:href").
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// Node is an element, text, comment, processing instruction or directive of
+// an SVG document. Names keep their prefixes rather than being resolved to
+// namespace URIs, so that a document serializes back the way it was written.
+type Node struct {
+	Type     NodeType
+	Name     string // element name or processing instruction target
+	Attrs    []Attr
+	Children []*Node
+	Text     string // content of text, comment, instruction and directive nodes
+}
+
+// ParseDocument parses an SVG document into a tree rooted at a DocumentNode.
+func ParseDocument(data []byte) (*Node, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	root := &Node{Type: DocumentNode}
+	stack := []*Node{root}
+
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			el := &Node{Type: ElementNode, Name: xmlName(t.Name)}
+			for _, a := range t.Attr {
+				el.Attrs = append(el.Attrs, Attr{Name: xmlName(a.Name), Value: a.Value})
+			}
+			parent.Children = append(parent.Children, el)
+			stack = append(stack, el)
+		case xml.EndElement:
+			if name := xmlName(t.Name); len(stack) == 1 || parent.Name != name {
+				return nil, fmt.Errorf("unexpected closing tag </%s> at offset %d", name, dec.InputOffset())
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.Children = append(parent.Children, &Node{Type: TextNode, Text: string(t)})
+		case xml.Comment:
+			parent.Children = append(parent.Children, &Node{Type: CommentNode, Text: string(t)})
+		case xml.ProcInst:
+			parent.Children = append(parent.Children, &Node{Type: ProcInstNode, Name: t.Target, Text: string(t.Inst)})
+		case xml.Directive:
+			parent.Children = append(parent.Children, &Node{Type: DirectiveNode, Text: string(t)})
+		}
+	}
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("unclosed element <%s>", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// xmlName joins a raw token name with its prefix.
+func xmlName(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
+// Attr returns the value of the named attribute.
+func (n *Node) Attr(name string) (string, bool) {
+	for _, a := range n.Attrs {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetAttr sets the named attribute, appending it if it is not present.
+func (n *Node) SetAttr(name, value string) {
+	for i := range n.Attrs {
+		if n.Attrs[i].Name == name {
+			n.Attrs[i].Value = value
+			return
+		}
+	}
+	n.Attrs = append(n.Attrs, Attr{Name: name, Value: value})
+}
+
+// RemoveAttr removes the named attribute if it is present.
+func (n *Node) RemoveAttr(name string) {
+	for i := range n.Attrs {
+		if n.Attrs[i].Name == name {
+			n.Attrs = append(n.Attrs[:i], n.Attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Walk calls fn for n and every node below it, parents before children.
+// Returning false from fn skips the children of that node.
+func (n *Node) Walk(fn func(*Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+// Bytes serializes the tree. Attributes are always double-quoted.
+func (n *Node) Bytes() []byte {
+	var buf bytes.Buffer
+	n.write(&buf)
+	return buf.Bytes()
+}
+
+func (n *Node) write(buf *bytes.Buffer) {
+	switch n.Type {
+	case DocumentNode:
+		for _, c := range n.Children {
+			c.write(buf)
+		}
+	case ElementNode:
+		buf.WriteString("<" + n.Name)
+		for _, a := range n.Attrs {
+			buf.WriteString(" " + a.Name + `="`)
+			_ = xml.EscapeText(buf, []byte(a.Value))
+			buf.WriteByte('"')
+		}
+		if len(n.Children) == 0 {
+			buf.WriteString("/>")
+			return
+		}
+		buf.WriteByte('>')
+		for _, c := range n.Children {
+			c.write(buf)
+		}
+		buf.WriteString("</" + n.Name + ">")
+	case TextNode:
+		// Only & and < must be escaped, which leaves CSS in <style> intact.
+		buf.WriteString(strings.NewReplacer("&", "&amp;", "<", "&lt;").Replace(n.Text))
+	case CommentNode:
+		buf.WriteString("<!--" + n.Text + "-->")
+	case ProcInstNode:
+		buf.WriteString("<?" + n.Name)
+		if n.Text != "" {
+			buf.WriteString(" " + n.Text)
+		}
+		buf.WriteString("?>")
+	case DirectiveNode:
+		buf.WriteString("<!" + n.Text + ">")
+	}
+}
+
+// removeComments drops every comment below n.
+func removeComments(n *Node) {
+	prune(n, func(c *Node) bool { return c.Type == CommentNode })
+}
+
+// metadataPlaceholder marks where a <metadata> element was taken out of a
+// document.
+const metadataPlaceholder = "repokit:metadata:%d"
+
+// protectMetadata takes the <metadata> elements out of a document, which
+// the minifier would drop, leaving placeholder comments in their place. The
+// returned function puts them back into the minified output. Namespace
+// prefixes the metadata uses are declared on it, since the minifier also
+// drops the declarations of namespaces it does not know.
+func protectMetadata(doc *Node) func([]byte) []byte {
+	var kept []*Node
+	var walk func(n *Node, scope map[string]string)
+	walk = func(n *Node, scope map[string]string) {
+		if n.Type == ElementNode {
+			inner := make(map[string]string, len(scope))
+			for k, v := range scope {
+				inner[k] = v
+			}
+			for _, a := range n.Attrs {
+				if prefix, ok := strings.CutPrefix(a.Name, "xmlns:"); ok {
+					inner[prefix] = a.Value
+				}
+			}
+			scope = inner
+		}
+		for i, c := range n.Children {
+			if c.Type == ElementNode && (c.Name == "metadata" || c.Name == "svg:metadata") {
+				for prefix := range usedPrefixes(c) {
+					if _, declared := c.Attr("xmlns:" + prefix); !declared && scope[prefix] != "" {
+						c.SetAttr("xmlns:"+prefix, scope[prefix])
+					}
+				}
+				n.Children[i] = &Node{Type: CommentNode, Text: fmt.Sprintf(metadataPlaceholder, len(kept))}
+				kept = append(kept, c)
+				continue
+			}
+			walk(c, scope)
+		}
+	}
+	walk(doc, nil)
+
+	return func(minified []byte) []byte {
+		for i, c := range kept {
+			placeholder := []byte("<!--" + fmt.Sprintf(metadataPlaceholder, i) + "-->")
+			minified = bytes.Replace(minified, placeholder, c.Bytes(), 1)
+		}
+		return minified
+	}
+}
+
+// usedPrefixes returns the namespace prefixes of the element and attribute
+// names below n.
+func usedPrefixes(n *Node) map[string]bool {
+	used := make(map[string]bool)
+	n.Walk(func(c *Node) bool {
+		if c.Type != ElementNode {
+			return true
+		}
+		names := []string{c.Name}
+		for _, a := range c.Attrs {
+			names = append(names, a.Name)
+		}
+		for _, name := range names {
+			if prefix, _, ok := strings.Cut(name, ":"); ok && prefix != "xmlns" && prefix != "xml" {
+				used[prefix] = true
+			}
+		}
+		return true
+	})
+	return used
+}
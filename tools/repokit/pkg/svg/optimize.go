@@ -1,15 +1,16 @@
 package svg
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"reflect" // Added for robust type name extraction
 	"regexp"  // Aliased for clarity
 	"repokit/pkg/core"
-	"strconv" // Added for parsing numbers from SVG attributes
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -38,23 +39,143 @@ const (
 	maxWorkers = 8
 	uiTickRate = 80 * time.Millisecond
 	pathMaxLen = 60
-	epsilon    = 0.025 // RDP tolerance (tighter for production)
-	snapAngle  = 0.012 // Radians (~0.7 degrees)
-	precision  = 2     // Decimal places for quantization
+
+	// DefaultTolerance is the default maximum geometric error of path
+	// simplification, in user units.
+	DefaultTolerance = 0.025
+	// DefaultPrecision is the default number of decimal places coordinates
+	// are rounded to.
+	DefaultPrecision = 2
+	// DefaultSnapAngle is the default angle, in radians (~0.7 degrees),
+	// within which polygon edges are snapped to the axes.
+	DefaultSnapAngle = 0.012
 )
 
 var (
 	tailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
 	blueStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("4")) // Corrected
 	goldStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
-
-	pathTagRegex     = regexp.MustCompile(`(?i)d="([^"]+)"`)
-	polygonTagRegex  = regexp.MustCompile(`(?i)points="([^"]+)"`)
-	polylineTagRegex = regexp.MustCompile(`(?i)points="([^"]+)"`)
 )
 
 // ─── Types & Logic ──────────────────────────────────────────────────────────
 
+// Options configures an optimization run.
+type Options struct {
+	// Tolerance is how far, in user units, simplifying a path may move any
+	// point of its outline, coordinate rounding included. It also bounds
+	// the simplification of polygons.
+	Tolerance float64
+	Precision int
+	SnapAngle float64
+
+	// ViewBox, when set, is the viewBox the normalize_viewbox pass fits
+	// every file's content into, Padding units away from its edges.
+	ViewBox string
+	Padding float64
+
+	// Passes names the passes to run, in order. Nil runs all of them, and
+	// an empty list, as set by "passes: []", none.
+	Passes []string
+
+	// Overrides replace the settings above for files matching their glob,
+	// in order.
+	Overrides []core.SVGOverride
+
+	// Verify renders every file before and after optimization and keeps the
+	// original when more than MaxDiff of its pixels change visibly.
+	Verify  bool
+	MaxDiff float64
+
+	// Check reports files that are not optimized yet and fails if there are
+	// any. DryRun reports what optimizing would save, and Diff prints what
+	// it would change. None of them write files.
+	Check  bool
+	DryRun bool
+	Diff   bool
+
+	// KeepComments keeps XML comments, and KeepMetadata keeps <metadata>
+	// elements such as licensing information, which the minifier would
+	// otherwise drop.
+	KeepComments bool
+	KeepMetadata bool
+
+	// OutDir receives optimized copies, at the files' paths relative to the
+	// working directory, instead of rewriting them in place.
+	OutDir string
+}
+
+// writes reports whether the run writes files at all.
+func (o Options) writes() bool {
+	return !o.Check && !o.DryRun && !o.Diff
+}
+
+// DefaultOptions returns the options used when none are configured.
+func DefaultOptions() Options {
+	return Options{
+		Tolerance: DefaultTolerance,
+		Precision: DefaultPrecision,
+		SnapAngle: DefaultSnapAngle,
+		MaxDiff:   DefaultMaxDiff,
+	}
+}
+
+// WithConfig returns the options with the settings of an svg config section
+// applied.
+func (o Options) WithConfig(c core.SVGConfig) Options {
+	o = o.with(c.Passes, c.Precision, c.Tolerance, c.SnapAngle, c.ViewBox, c.Padding)
+	o.Overrides = c.Overrides
+	return o
+}
+
+func (o Options) with(passes []string, precision *int, tolerance, snapAngle *float64, viewBox string, padding *float64) Options {
+	if passes != nil {
+		o.Passes = passes
+	}
+	if precision != nil {
+		o.Precision = *precision
+	}
+	if tolerance != nil {
+		o.Tolerance = *tolerance
+	}
+	if snapAngle != nil {
+		o.SnapAngle = *snapAngle
+	}
+	if viewBox != "" {
+		o.ViewBox = viewBox
+	}
+	if padding != nil {
+		o.Padding = *padding
+	}
+	return o
+}
+
+// forFile returns the options for one file, its matching overrides applied.
+func (o Options) forFile(path string) (Options, error) {
+	for _, ov := range o.Overrides {
+		ok, err := core.MatchGlob(ov.Files, path)
+		if err != nil {
+			return o, fmt.Errorf("invalid svg override glob %q: %w", ov.Files, err)
+		}
+		if ok {
+			o = o.with(ov.Passes, ov.Precision, ov.Tolerance, ov.SnapAngle, ov.ViewBox, ov.Padding)
+		}
+	}
+	return o, nil
+}
+
+// validate checks the pass names of the options and their overrides.
+func (o Options) validate() error {
+	if err := validatePasses(o.Passes); err != nil {
+		return err
+	}
+	for _, ov := range o.Overrides {
+		if err := validatePasses(ov.Passes); err != nil {
+			return fmt.Errorf("svg override %q: %w", ov.Files, err)
+		}
+	}
+	return nil
+}
+
 type Result struct {
 	File        string
 	Path        string
@@ -65,6 +186,18 @@ type Result struct {
 	SizeBefore  int64
 	SizeAfter   int64
 	LLMAnalysis string // New field for LLM analysis
+	Passes      []PassResult
+
+	// Set when the file was verified: the share of pixels that changed
+	// visibly, and whether the original was kept because of it.
+	Diff     float64
+	Rejected bool
+	Reason   string
+
+	// Changed is set when optimizing alters the file; Patch then holds the
+	// unified diff of the change if it was asked for.
+	Changed bool
+	Patch   string
 }
 
 type fileStatus struct {
@@ -74,6 +207,7 @@ type fileStatus struct {
 }
 
 type optimizer struct {
+	opts         Options
 	files        []string
 	results      []Result
 	workerStates []*fileStatus
@@ -89,31 +223,28 @@ type optimizer struct {
 
 // ─── Pipeline: The "Mind-Blowing" Implementation ────────────────────────────
 
-// processPathData applies geometric optimizations to an SVG path 'd' attribute.
-func processPathData(d string) (optimizedDS string, nodesBefore int, nodesAfter int) {
-	// 1. Lexing: Tokenize the 'd' attribute into high-level commands
-	commands := ParsePath(d)
-	nodesBefore = len(commands)
-
-	// 2. Geometric Extraction: Convert to world-space vectors
-	// We handle curves by sampling points along the spline
-	points := ToAbsolutePoints(commands)
-
-	// 3. Mathematical Decimation (RDP Pass)
-	// Removes redundant data points while preserving geometric intent
-	beautified := SimplifyPath(points, epsilon)
+// processPathData applies geometric optimizations to an SVG path 'd' attribute,
+// moving its outline by at most tolerance, and rounds it to precision
+// decimals. Data that does not parse is returned unchanged.
+func processPathData(d string, tolerance float64, precision int) (optimizedDS string, nodesBefore int, nodesAfter int) {
+	// 1. Parsing: Build the path AST, keeping curves, arcs and subpaths
+	path, err := ParsePath(d)
+	if err != nil {
+		return d, 0, 0
+	}
 
-	// 4. Primitive Analysis & Recovery
-	// If the points describe a circular arc, we could replace with an arc command
-	// For now, we perform regularization on the decimated set
-	beautified = SnapPointsToAxes(beautified, snapAngle)
+	// 2. Curve-Aware Simplification
+	// Rounding to the output precision moves points too, so it takes its
+	// share of the error budget first.
+	rounding := math.Sqrt2 / 2 * math.Pow(10, -float64(precision))
+	simplified := Simplify(path, tolerance-rounding)
 
-	// 5. Intelligent Serialization
-	// The Geometer now calculates the entropy of Absolute vs Relative pathing
-	// and picks the byte-optimal representation for every segment.
-	newD := FormatPathData(beautified, precision)
+	// 3. Intelligent Serialization
+	// Coordinates are quantized in absolute space and every segment is
+	// written in whichever of its absolute or relative forms is shorter.
+	newD := FormatPath(simplified, precision)
 
-	return newD, nodesBefore, len(beautified)
+	return newD, len(path), len(simplified)
 }
 
 // buildSVGDescriptionPrompt creates a prompt for the LLM based on SVG elements.
@@ -174,7 +305,10 @@ func generateSVGDescription(ctx context.Context, svgAST *svg_parser.Svg) (string
 
 // Optimize processes SVG files in parallel using rustyoz/svg for parsing
 // and tdewolff/minify for minification.
-func Optimize(pattern string) error {
+func Optimize(pattern string, opts Options) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
 	files, err := core.ResolveFiles(pattern)
 	if err != nil {
 		return err
@@ -183,13 +317,16 @@ func Optimize(pattern string) error {
 		return nil
 	}
 
-	opt := newOptimizer(files)
+	opt := newOptimizer(files, opts)
 	return opt.run()
 }
 
-func newOptimizer(files []string) *optimizer {
+func newOptimizer(files []string, opts Options) *optimizer {
 	m := minify.New()
-	m.AddFunc("image/svg+xml", svg_minifier.Minify)
+	// Metadata is protected from the minifier as placeholder comments, so
+	// it must keep comments; unwanted ones are removed from the document
+	// beforehand.
+	m.Add("image/svg+xml", &svg_minifier.Minifier{KeepComments: opts.KeepComments || opts.KeepMetadata})
 
 	workerCount := maxWorkers
 	if len(files) < workerCount {
@@ -202,6 +339,7 @@ func newOptimizer(files []string) *optimizer {
 	}
 
 	return &optimizer{
+		opts:         opts,
 		files:        files,
 		results:      make([]Result, len(files)),
 		workerStates: states,
@@ -249,25 +387,14 @@ func (o *optimizer) worker(id int, ctx context.Context, tasks <-chan int) {
 		default:
 			path := o.files[idx]
 			o.updateWorkerState(id, path, true)
-			sBefore, sAfter, nb, na, llmAnalysis, err := o.processFile(path)
+			r, err := o.processFile(path)
 			o.updateWorkerState(id, "", false)
 
-			o.results[idx] = Result{
-				File:        filepath.Base(path),
-				Path:        path,
-				Success:     err == nil,
-				NodesBefore: nb,
-				NodesAfter:  na,
-				SizeBefore:  sBefore,
-				SizeAfter:   sAfter,
-				LLMAnalysis: llmAnalysis,
-				Error: func() string {
-					if err != nil {
-						return err.Error()
-					}
-					return ""
-				}(),
+			r.File, r.Path, r.Success = filepath.Base(path), path, err == nil
+			if err != nil {
+				r.Error = err.Error()
 			}
+			o.results[idx] = r
 
 			atomic.AddInt32(&o.processed, 1)
 			if err != nil {
@@ -279,122 +406,128 @@ func (o *optimizer) worker(id int, ctx context.Context, tasks <-chan int) {
 	}
 }
 
-func (o *optimizer) processFile(path string) (sizeBefore int64, sizeAfter int64, nodesBefore int, nodesAfter int, llmAnalysis string, err error) {
+// analyze asks the configured LLM to describe an SVG file.
+func (o *optimizer) analyze(path, content string) string {
+	parsedSVG, err := svg_parser.ParseSvg(content, filepath.Base(path), 1.0)
+	if err != nil {
+		// Log error but don't fail entire process, proceed with minification
+		core.Error("Failed to parse SVG with rustyoz/svg for LLM analysis: %v", err)
+		return "Failed to generate LLM analysis."
+	}
+	if svgLLMConfig == nil || svgLLMConfig.Provider == "" {
+		return ""
+	}
+	analysis, err := generateSVGDescription(context.Background(), parsedSVG)
+	if err != nil {
+		core.Error("Failed to get LLM analysis for %s: %v", filepath.Base(path), err)
+		return "Failed to generate LLM analysis."
+	}
+	return analysis
+}
+
+func (o *optimizer) processFile(path string) (Result, error) {
+	var r Result
 	input, err := os.ReadFile(path)
 	if err != nil {
-		return 0, 0, 0, 0, "", err
+		return r, err
 	}
 
-	sizeBefore = int64(len(input))
+	r.SizeBefore = int64(len(input))
 	content := string(input)
-	nb := 0 // total nodes before geometric optimization
-	na := 0 // total nodes after geometric optimization
 
 	// --- LLM Analysis (using rustyoz/svg AST) ---
-	parsedSVG, err := svg_parser.ParseSvg(content, filepath.Base(path), 1.0)
-	if err != nil {
-		// Log error but don't fail entire process, proceed with minification
-		core.Error("Failed to parse SVG with rustyoz/svg for LLM analysis: %v", err)
-		llmAnalysis = "Failed to generate LLM analysis."
-	} else if svgLLMConfig != nil && svgLLMConfig.Provider != "" {
-		llmAnalysis, err = generateSVGDescription(context.Background(), parsedSVG)
-		if err != nil {
-			core.Error("Failed to get LLM analysis for %s: %v", filepath.Base(path), err)
-			llmAnalysis = "Failed to generate LLM analysis."
-		}
+	// Checks and previews write nothing, so they skip the analysis.
+	if o.opts.writes() {
+		r.LLMAnalysis = o.analyze(path, content)
 	}
 
-	// --- Geometric Optimization (using our old logic) ---
-	// Create a modifiable representation of the SVG content
-	var modifiedSVGContent = content
-
-	// 1. Optimize Paths
-	modifiedSVGContent = pathTagRegex.ReplaceAllStringFunc(modifiedSVGContent, func(m string) string {
-		match := pathTagRegex.FindStringSubmatch(m)
-		if len(match) < 2 {
-			return m
-		}
-		d, nodesB, nodesA := processPathData(match[1])
-		nb += nodesB
-		na += nodesA
-		return fmt.Sprintf("d=%q", d)
-	})
-
-	// 2. Optimize Polygons/Polylines (they use 'points' attribute)
-	modifiedSVGContent = polygonTagRegex.ReplaceAllStringFunc(modifiedSVGContent, func(m string) string {
-		match := polygonTagRegex.FindStringSubmatch(m)
-		if len(match) < 2 {
-			return m
+	// --- Optimization Passes ---
+	// Documents that are not well-formed XML are left to the minifier as
+	// they are rather than rewritten on a guess.
+	opts, err := o.opts.forFile(path)
+	if err != nil {
+		return r, err
+	}
+	minifierInput := input
+	var restore func([]byte) []byte
+	if doc, err := ParseDocument(input); err == nil {
+		r.Passes, r.NodesBefore, r.NodesAfter = runPasses(doc, opts)
+		if !o.opts.KeepComments {
+			removeComments(doc)
 		}
-		pointsStr := match[1]
-		// Convert points string to []Point
-		var points []Point
-		coords := regexp.MustCompile(`\s*,\s*|\s+`).Split(strings.TrimSpace(pointsStr), -1)
-		for i := 0; i < len(coords); i += 2 {
-			if i+1 < len(coords) {
-				x, _ := strconv.ParseFloat(coords[i], 64)
-				y, _ := strconv.ParseFloat(coords[i+1], 64)
-				points = append(points, Point{X: x, Y: y})
-			}
+		if o.opts.KeepMetadata {
+			restore = protectMetadata(doc)
 		}
+		minifierInput = doc.Bytes()
+	}
 
-		nodesB := len(points)
-		// Apply same simplification as paths
-		points = SimplifyPath(points, epsilon)
-		points = SnapPointsToAxes(points, snapAngle)
-		nodesA := len(points)
-
-		nb += nodesB
-		na += nodesA
-
-		processedPoints := FormatPointsData(points, precision)
-		return fmt.Sprintf("points=%q", processedPoints)
-	})
-
-	modifiedSVGContent = polylineTagRegex.ReplaceAllStringFunc(modifiedSVGContent, func(m string) string {
-		match := polylineTagRegex.FindStringSubmatch(m)
-		if len(match) < 2 {
-			return m
+	// --- Minify the geometrically optimized content ---
+	minified, err := o.minifier.Bytes("image/svg+xml", minifierInput)
+	if err != nil {
+		return r, fmt.Errorf("failed to minify SVG: %w", err)
+	}
+	if restore != nil {
+		minified = restore(minified)
+	}
+	r.SizeAfter = int64(len(minified))
+
+	// --- Visual Verification ---
+	// An optimization that changes how the icon looks is not worth its
+	// bytes, so the original is kept untouched.
+	if o.opts.Verify {
+		r.Diff, err = VisualDiff(input, minified)
+		switch {
+		case err != nil:
+			r.Rejected, r.Reason = true, err.Error()
+		case r.Diff > o.opts.MaxDiff:
+			r.Rejected = true
+			r.Reason = fmt.Sprintf("%.1f%% of pixels changed", 100*r.Diff)
 		}
-		pointsStr := match[1]
-		// Convert points string to []Point
-		var points []Point
-		coords := regexp.MustCompile(`\s*,\s*|\s+`).Split(strings.TrimSpace(pointsStr), -1)
-		for i := 0; i < len(coords); i += 2 {
-			if i+1 < len(coords) {
-				x, _ := strconv.ParseFloat(coords[i], 64)
-				y, _ := strconv.ParseFloat(coords[i+1], 64)
-				points = append(points, Point{X: x, Y: y})
-			}
+		if r.Rejected {
+			r.NodesAfter, r.SizeAfter = r.NodesBefore, r.SizeBefore
+			minified = input
 		}
+	}
 
-		nodesB := len(points)
-		// Apply same simplification as paths
-		points = SimplifyPath(points, epsilon)
-		points = SnapPointsToAxes(points, snapAngle)
-		nodesA := len(points)
-
-		nb += nodesB
-		na += nodesA
-
-		processedPoints := FormatPointsData(points, precision)
-		return fmt.Sprintf("points=%q", processedPoints)
-	})
+	r.Changed = !bytes.Equal(input, minified)
+	if o.opts.Diff && r.Changed {
+		r.Patch = unifiedDiff(displayPath(path), prettyPrint(input), prettyPrint(minified))
+	}
+	if !o.opts.writes() {
+		return r, nil
+	}
 
-	// --- Minify the geometrically optimized content ---
-	minified, err := o.minifier.Bytes("image/svg+xml", []byte(modifiedSVGContent))
-	if err != nil {
-		return sizeBefore, 0, 0, 0, llmAnalysis, fmt.Errorf("failed to minify SVG: %w", err)
+	target := path
+	if o.opts.OutDir != "" {
+		target = filepath.Join(o.opts.OutDir, displayPath(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return r, err
+		}
+	} else if !r.Changed {
+		return r, nil
 	}
-	sizeAfter = int64(len(minified))
+
 	info, _ := os.Stat(path)
 	mode := os.FileMode(0644)
 	if info != nil {
 		mode = info.Mode()
 	}
 
-	err = os.WriteFile(path, minified, mode)
-	return sizeBefore, sizeAfter, nb, na, llmAnalysis, err
+	return r, os.WriteFile(target, minified, mode)
+}
+
+// displayPath returns path relative to the working directory, or its base
+// name when it lies outside of it.
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filepath.Base(path)
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Base(path)
+	}
+	return rel
 }
 
 func (o *optimizer) updateWorkerState(id int, path string, active bool) {
@@ -447,8 +580,15 @@ func (o *optimizer) report() error {
 	failed := atomic.LoadInt32(&o.failedCount)
 	var totalSizeBefore, totalSizeAfter int64
 	var totalNodesBefore, totalNodesAfter int
+	var rejected, changed []Result
 
 	for _, r := range o.results {
+		if r.Rejected {
+			rejected = append(rejected, r)
+		}
+		if r.Changed {
+			changed = append(changed, r)
+		}
 		totalSizeBefore += r.SizeBefore
 		totalSizeAfter += r.SizeAfter
 		totalNodesBefore += r.NodesBefore
@@ -460,38 +600,107 @@ func (o *optimizer) report() error {
 		}
 	}
 
-	if failed == 0 {
-		reduction := 0.0
-		if totalNodesBefore > 0 {
-			reduction = 100 * (1 - float64(totalNodesAfter)/float64(totalNodesBefore))
+	if failed > 0 {
+		return fmt.Errorf("failed to process %d files", failed)
+	}
+
+	for _, r := range changed {
+		printPatch(r.Patch)
+	}
+
+	reduction := 0.0
+	if totalNodesBefore > 0 {
+		reduction = 100 * (1 - float64(totalNodesAfter)/float64(totalNodesBefore))
+	}
+
+	sizeReduction := 0.0
+	if totalSizeBefore > 0 {
+		sizeReduction = 100 * (1 - float64(totalSizeAfter)/float64(totalSizeBefore))
+	}
+
+	switch {
+	case !o.opts.writes():
+		core.Success("%d of %d SVG files can be optimized", len(changed), len(o.files))
+	case o.opts.OutDir != "":
+		core.Success("Wrote %d SVG files to %s", len(o.files), o.opts.OutDir)
+	default:
+		core.Success("Optimized %d SVG files", len(changed))
+	}
+	if o.opts.DryRun {
+		for _, r := range changed {
+			core.Info("  ┃ %s %s -> %s, %d -> %d nodes", displayPath(r.Path), core.FormatBytes(r.SizeBefore), core.FormatBytes(r.SizeAfter), r.NodesBefore, r.NodesAfter)
 		}
+	}
+	if totalNodesBefore > 0 {
+		core.Info("  ┃ " + blueStyle.Render("Geometric Pass:") + " %d -> %d nodes (%.1f%% density reduction)", totalNodesBefore, totalNodesAfter, reduction)
+	}
+	core.Info("  ┃ " + goldStyle.Render("Byte Pass:") + "      %s -> %s (%.1f%% reduction)", core.FormatBytes(totalSizeBefore), core.FormatBytes(totalSizeAfter), sizeReduction)
+	o.reportPasses()
+	o.reportRejected(rejected)
 
-		sizeReduction := 0.0
-		if totalSizeBefore > 0 {
-			sizeReduction = 100 * (1 - float64(totalSizeAfter)/float64(totalSizeBefore))
+	if o.opts.Check && len(changed) > 0 {
+		for _, r := range changed {
+			core.Info("  ┃ " + goldStyle.Render("not optimized:") + " %s", displayPath(r.Path))
 		}
+		return fmt.Errorf("%d SVG files are not optimized", len(changed))
+	}
+	return nil
+}
 
-		core.Success("Optimized %d SVG files", len(o.files))
-		if totalNodesBefore > 0 {
-			core.Info("  ┃ " + blueStyle.Render("Geometric Pass:") + " %d -> %d nodes (%.1f%% density reduction)", totalNodesBefore, totalNodesAfter, reduction)
+// reportPasses lists what each pass saved over all files, in the order the
+// passes ran.
+func (o *optimizer) reportPasses() {
+	var order []string
+	saved := make(map[string]int64)
+	for _, r := range o.results {
+		for _, p := range r.Passes {
+			if _, seen := saved[p.Name]; !seen {
+				order = append(order, p.Name)
+			}
+			saved[p.Name] += p.Saved
 		}
-		core.Info("  ┃ " + goldStyle.Render("Byte Pass:") + "      %s -> %s (%.1f%% reduction)", formatBytes(totalSizeBefore), formatBytes(totalSizeAfter), sizeReduction)
-		return nil
 	}
-	return fmt.Errorf("failed to process %d files", failed)
+	for _, name := range order {
+		if saved[name] == 0 {
+			continue
+		}
+		amount := core.FormatBytes(saved[name]) + " saved"
+		if saved[name] < 0 {
+			amount = core.FormatBytes(-saved[name]) + " added"
+		}
+		core.Info("  ┃   %s %s", tailStyle.Render(fmt.Sprintf("%-16s", name)), amount)
+	}
 }
 
-func formatBytes(b int64) string {
-	const unit = 1024
-	if b < unit {
-		return fmt.Sprintf("%d B", b)
+// reportRejected lists the files whose optimization changed them visibly
+// and were therefore left as they were.
+func (o *optimizer) reportRejected(rejected []Result) {
+	if len(rejected) == 0 {
+		return
+	}
+	core.Warning("Kept %d original SVG files: optimization changed them visibly or they could not be rendered", len(rejected))
+	for _, r := range rejected {
+		core.Info("  ┃ %s %s", displayPath(r.Path), tailStyle.Render("("+r.Reason+")"))
 	}
-	div, exp := int64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+}
+
+// printPatch writes a unified diff to stdout, colored when it is a
+// terminal.
+func printPatch(patch string) {
+	for _, line := range strings.SplitAfter(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			fmt.Print(core.Bold.Render(strings.TrimSuffix(line, "\n")) + "\n")
+		case strings.HasPrefix(line, "@@"):
+			fmt.Print(core.Cyan.Render(strings.TrimSuffix(line, "\n")) + "\n")
+		case strings.HasPrefix(line, "+"):
+			fmt.Print(core.Green.Render(strings.TrimSuffix(line, "\n")) + "\n")
+		case strings.HasPrefix(line, "-"):
+			fmt.Print(core.Red.Render(strings.TrimSuffix(line, "\n")) + "\n")
+		default:
+			fmt.Print(line)
+		}
 	}
-	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
 }
 
 func formatProgressLine(icon, path, suffix string) string {
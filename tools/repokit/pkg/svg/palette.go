@@ -0,0 +1,522 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ─── Color Values ───────────────────────────────────────────────────────────
+
+// Color is an sRGB color with an alpha between 0 and 1.
+type Color struct {
+	R, G, B uint8
+	A       float64
+}
+
+// Hex writes the color as #rrggbb, or #rrggbbaa if it is translucent.
+func (c Color) Hex() string {
+	if c.A < 1 {
+		return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, channel(c.A*255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// namedColors are the basic CSS color keywords.
+var namedColors = map[string]Color{
+	"black": {0, 0, 0, 1}, "silver": {192, 192, 192, 1}, "gray": {128, 128, 128, 1},
+	"grey": {128, 128, 128, 1}, "white": {255, 255, 255, 1}, "maroon": {128, 0, 0, 1},
+	"red": {255, 0, 0, 1}, "purple": {128, 0, 128, 1}, "fuchsia": {255, 0, 255, 1},
+	"green": {0, 128, 0, 1}, "lime": {0, 255, 0, 1}, "olive": {128, 128, 0, 1},
+	"yellow": {255, 255, 0, 1}, "navy": {0, 0, 128, 1}, "blue": {0, 0, 255, 1},
+	"teal": {0, 128, 128, 1}, "aqua": {0, 255, 255, 1}, "orange": {255, 165, 0, 1},
+}
+
+// ParseColor parses a CSS color: #rgb, #rgba, #rrggbb and #rrggbbaa, rgb(),
+// rgba(), hsl() and hsla() in comma or space syntax, and the basic named
+// colors.
+func ParseColor(s string) (Color, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, true
+	}
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		return parseHexColor(hex)
+	}
+	name, args, ok := strings.Cut(s, "(")
+	if !ok || !strings.HasSuffix(args, ")") {
+		return Color{}, false
+	}
+	fields := strings.FieldsFunc(strings.TrimSuffix(args, ")"), func(r rune) bool {
+		return r == ',' || r == '/' || unicode.IsSpace(r)
+	})
+	if len(fields) != 3 && len(fields) != 4 {
+		return Color{}, false
+	}
+	alpha := 1.0
+	if len(fields) == 4 {
+		a, ok := colorComponent(fields[3], 1)
+		if !ok {
+			return Color{}, false
+		}
+		alpha = max(0, min(a, 1))
+	}
+
+	switch strings.TrimSpace(name) {
+	case "rgb", "rgba":
+		var rgb [3]float64
+		for i := range rgb {
+			if rgb[i], ok = colorComponent(fields[i], 255); !ok {
+				return Color{}, false
+			}
+		}
+		return Color{channel(rgb[0]), channel(rgb[1]), channel(rgb[2]), alpha}, true
+	case "hsl", "hsla":
+		h, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "deg"), 64)
+		if err != nil {
+			return Color{}, false
+		}
+		var sl [2]float64
+		for i := range sl {
+			// Saturation and lightness are percentages, with or without
+			// the sign.
+			v, err := strconv.ParseFloat(strings.TrimSuffix(fields[i+1], "%"), 64)
+			if err != nil {
+				return Color{}, false
+			}
+			sl[i] = max(0, min(v/100, 1))
+		}
+		return hslColor(h, sl[0], sl[1], alpha), true
+	}
+	return Color{}, false
+}
+
+func parseHexColor(hex string) (Color, bool) {
+	if len(hex) == 3 || len(hex) == 4 {
+		long := make([]byte, 0, 2*len(hex))
+		for i := 0; i < len(hex); i++ {
+			long = append(long, hex[i], hex[i])
+		}
+		hex = string(long)
+	}
+	if len(hex) != 6 && len(hex) != 8 {
+		return Color{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, false
+	}
+	if len(hex) == 6 {
+		return Color{uint8(v >> 16), uint8(v >> 8), uint8(v), 1}, true
+	}
+	return Color{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), float64(uint8(v)) / 255}, true
+}
+
+// colorComponent parses a number, or a percentage of scale.
+func colorComponent(s string, scale float64) (float64, bool) {
+	percent := strings.HasSuffix(s, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, false
+	}
+	if percent {
+		v = v * scale / 100
+	}
+	return v, true
+}
+
+// channel rounds a color channel into 0-255.
+func channel(v float64) uint8 {
+	return uint8(math.Round(max(0, min(v, 255))))
+}
+
+// hslColor converts a hue in degrees and saturation and lightness between
+// 0 and 1 to sRGB.
+func hslColor(h, s, l, alpha float64) Color {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	f := func(n float64) uint8 {
+		k := math.Mod(n+h/30, 12)
+		return channel(255 * (l - s*min(l, 1-l)*max(-1, min(k-3, 9-k, 1))))
+	}
+	return Color{f(0), f(8), f(4), alpha}
+}
+
+// lab converts a color to CIELAB under D65, where distances approximate
+// perceived differences.
+func (c Color) lab() [3]float64 {
+	linear := func(v uint8) float64 {
+		x := float64(v) / 255
+		if x <= 0.04045 {
+			return x / 12.92
+		}
+		return math.Pow((x+0.055)/1.055, 2.4)
+	}
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx := f((0.4124*r + 0.3576*g + 0.1805*b) / 0.95047)
+	fy := f(0.2126*r + 0.7152*g + 0.0722*b)
+	fz := f((0.0193*r + 0.1192*g + 0.9505*b) / 1.08883)
+	return [3]float64{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
+
+// distance returns the CIE76 difference between two colors. A difference in
+// alpha counts like one in lightness, so that a translucent token is not
+// taken for its opaque color.
+func (c Color) distance(o Color) float64 {
+	a, b := c.lab(), o.lab()
+	dl, da, db, dalpha := a[0]-b[0], a[1]-b[1], a[2]-b[2], 100*(c.A-o.A)
+	return math.Sqrt(dl*dl + da*da + db*db + dalpha*dalpha)
+}
+
+// ─── Palettes ───────────────────────────────────────────────────────────────
+
+// Token is a named color of the design system.
+type Token struct {
+	Name  string // CSS custom property, without the leading dashes
+	Value string // the color as defined
+	Color Color
+}
+
+// Palette is the tokens SVG files may paint with, in the order they are
+// defined.
+type Palette []Token
+
+// paletteMatch is the largest difference at which a color counts as a
+// token's. Spelling a token in another notation rounds its channels, and a
+// difference of 1 is not visible.
+const paletteMatch = 1.0
+
+var (
+	cssCommentRegex = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	customPropRegex = regexp.MustCompile(`--([\w-]+)\s*:\s*([^;{}]+)`)
+)
+
+// LoadPalette reads the tokens of a palette file: the custom properties of
+// a CSS file whose values are colors, such as the --color-* variables of a
+// Tailwind theme, or a YAML or JSON map of token names to colors (quoted in
+// YAML, where # starts a comment). A CSS property defined more than once,
+// as themes do for dark mode, keeps its first color.
+func LoadPalette(path string) (Palette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Palette
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".css":
+		p = parseCSSPalette(string(data))
+	case ".yaml", ".yml", ".json":
+		if p, err = parseMapPalette(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unknown palette format %q (expected .css, .yaml or .json)", path, ext)
+	}
+	if len(p) == 0 {
+		return nil, fmt.Errorf("%s: no colors found", path)
+	}
+	return p, nil
+}
+
+func parseCSSPalette(css string) Palette {
+	var p Palette
+	seen := make(map[string]bool)
+	for _, m := range customPropRegex.FindAllStringSubmatch(cssCommentRegex.ReplaceAllString(css, ""), -1) {
+		name, value := m[1], strings.TrimSpace(m[2])
+		c, ok := ParseColor(value)
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		p = append(p, Token{Name: name, Value: value, Color: c})
+	}
+	return p
+}
+
+func parseMapPalette(data []byte) (Palette, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a map of token names to colors")
+	}
+	var p Palette
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		name, value := strings.TrimPrefix(m.Content[i].Value, "--"), m.Content[i+1].Value
+		c, ok := ParseColor(value)
+		if !ok {
+			return nil, fmt.Errorf("token %q: %q is not a color", name, value)
+		}
+		p = append(p, Token{Name: name, Value: value, Color: c})
+	}
+	return p, nil
+}
+
+// Nearest returns the token closest to c and their difference. Of equally
+// close tokens, the first defined wins.
+func (p Palette) Nearest(c Color) (Token, float64) {
+	var best Token
+	bestDistance := math.Inf(1)
+	for _, t := range p {
+		if d := c.distance(t.Color); d < bestDistance {
+			best, bestDistance = t, d
+		}
+	}
+	return best, bestDistance
+}
+
+func (p Palette) lookup(name string) (Token, bool) {
+	for _, t := range p {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Token{}, false
+}
+
+// ─── Palette Check ──────────────────────────────────────────────────────────
+
+// PaletteFix selects what CheckPalette replaces off-palette colors with.
+type PaletteFix int
+
+const (
+	FixNone  PaletteFix = iota // report only
+	FixColor                   // the nearest token's color
+	FixVar                     // var(--token, color) of the nearest token
+)
+
+// ColorUse is a color SVG files paint with, and how it maps to the palette.
+type ColorUse struct {
+	Value    string   `json:"value"`              // #rrggbb, var(--token), or as written if it is not understood
+	Token    string   `json:"token,omitempty"`    // token it is, if it is on the palette
+	Nearest  string   `json:"nearest,omitempty"`  // closest token, if it is not
+	Distance float64  `json:"distance,omitempty"` // CIE76 difference to the closest token
+	Replaced string   `json:"replaced,omitempty"` // token a fix replaced it with
+	Files    []string `json:"files"`
+}
+
+// OnPalette reports whether the color is one of the palette's tokens.
+func (u ColorUse) OnPalette() bool {
+	return u.Token != ""
+}
+
+// IconColors lists the colors of one file, as the values of their uses.
+type IconColors struct {
+	File   string   `json:"file"`
+	Colors []string `json:"colors"`
+	Fixed  int      `json:"fixed,omitempty"` // colors replaced
+}
+
+// PaletteReport is the colors of a set of SVG files, off-palette ones
+// first and replaced ones next, and the colors of each file.
+type PaletteReport struct {
+	Colors []ColorUse   `json:"colors"`
+	Icons  []IconColors `json:"icons"`
+}
+
+// OffPalette returns the colors that are not tokens and were not replaced
+// with one.
+func (r PaletteReport) OffPalette() []ColorUse {
+	var off []ColorUse
+	for _, u := range r.Colors {
+		if !u.OnPalette() && u.Replaced == "" {
+			off = append(off, u)
+		}
+	}
+	return off
+}
+
+// CheckPalette collects the colors the SVG files paint with, in paint
+// attributes, inline styles and style sheets, and maps them to the
+// palette. Colors within an invisible difference of a token count as that
+// token; var() references to tokens count too, and shapes without a fill
+// paint with the default black. Unless fix is FixNone, off-palette colors
+// are replaced with the nearest token and the files are rewritten.
+func CheckPalette(files []string, palette Palette, fix PaletteFix) (PaletteReport, error) {
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+
+	var report PaletteReport
+	uses := make(map[string]*ColorUse)
+	var order []string
+	for _, path := range files {
+		input, err := os.ReadFile(path)
+		if err != nil {
+			return report, err
+		}
+		doc, err := ParseDocument(input)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", path, err)
+		}
+
+		icon := IconColors{File: displayPath(path)}
+		seen := make(map[string]bool)
+		paint := func(value string) string {
+			use, ok := classifyColor(value, palette)
+			if !ok {
+				return value
+			}
+			u := uses[use.Value]
+			if u == nil {
+				u = &use
+				uses[use.Value] = u
+				order = append(order, use.Value)
+			}
+			if !seen[use.Value] {
+				seen[use.Value] = true
+				icon.Colors = append(icon.Colors, use.Value)
+				u.Files = append(u.Files, icon.File)
+			}
+			if fix == FixNone || use.OnPalette() || use.Nearest == "" {
+				return value
+			}
+			icon.Fixed++
+			u.Replaced = use.Nearest
+			t, _ := palette.lookup(use.Nearest)
+			if fix == FixVar {
+				return "var(--" + t.Name + ", " + t.Color.Hex() + ")"
+			}
+			return t.Color.Hex()
+		}
+		mapColors(doc, paint)
+		if root := rootElement(doc); root != nil {
+			black := false
+			defaultFilled(root, func(*Node) { black = true })
+			// The root's fill is inherited by exactly the shapes painting
+			// with the default.
+			if black {
+				if to := paint("#000000"); to != "#000000" {
+					root.SetAttr("fill", to)
+				}
+			}
+		}
+
+		if icon.Fixed > 0 {
+			info, err := os.Stat(path)
+			if err != nil {
+				return report, err
+			}
+			if err := os.WriteFile(path, doc.Bytes(), info.Mode().Perm()); err != nil {
+				return report, err
+			}
+		}
+		report.Icons = append(report.Icons, icon)
+	}
+
+	for _, v := range order {
+		report.Colors = append(report.Colors, *uses[v])
+	}
+	sort.SliceStable(report.Colors, func(i, j int) bool {
+		a, b := report.Colors[i], report.Colors[j]
+		if ra, rb := paletteRank(a), paletteRank(b); ra != rb {
+			return ra < rb
+		}
+		if len(a.Files) != len(b.Files) {
+			return len(a.Files) > len(b.Files)
+		}
+		return a.Value < b.Value
+	})
+	return report, nil
+}
+
+// paletteRank orders off-palette colors before replaced ones, and those
+// before the colors on the palette.
+func paletteRank(u ColorUse) int {
+	switch {
+	case u.OnPalette():
+		return 2
+	case u.Replaced != "":
+		return 1
+	default:
+		return 0
+	}
+}
+
+var varRefRegex = regexp.MustCompile(`^var\(\s*--([\w-]+)`)
+
+// classifyColor maps a paint value to the palette. Values that are not
+// colors give false: none, currentColor and other keywords, paint server
+// references, and variables that are not tokens, whose value is unknown.
+func classifyColor(value string, p Palette) (ColorUse, bool) {
+	value = strings.TrimSpace(value)
+	switch lower := strings.ToLower(value); {
+	case lower == "" || lower == "none" || lower == "currentcolor" || lower == "inherit" ||
+		lower == "transparent" || lower == "context-fill" || lower == "context-stroke":
+		return ColorUse{}, false
+	case strings.HasPrefix(lower, "url("):
+		return ColorUse{}, false
+	case strings.HasPrefix(lower, "var("):
+		m := varRefRegex.FindStringSubmatch(value)
+		if m == nil {
+			return ColorUse{}, false
+		}
+		t, ok := p.lookup(m[1])
+		if !ok {
+			return ColorUse{}, false
+		}
+		return ColorUse{Value: "var(--" + t.Name + ")", Token: t.Name}, true
+	}
+
+	c, ok := ParseColor(value)
+	if !ok {
+		return ColorUse{Value: value}, true
+	}
+	t, d := p.Nearest(c)
+	if d <= paletteMatch {
+		return ColorUse{Value: c.Hex(), Token: t.Name}, true
+	}
+	return ColorUse{Value: c.Hex(), Nearest: t.Name, Distance: math.Round(d*10) / 10}, true
+}
+
+var paintDeclRegex = regexp.MustCompile(`(?i)((?:^|[;{\s])(?:fill|stroke|color|stop-color|flood-color|lighting-color)\s*:\s*)([^;}!]+)`)
+
+// mapColors replaces the values of a document's paint attributes, and of
+// the paint properties of its inline styles and style sheets, with what fn
+// returns for them.
+func mapColors(doc *Node, fn func(string) string) {
+	doc.Walk(func(n *Node) bool {
+		for i, a := range n.Attrs {
+			switch {
+			case containsString(paintAttrs, a.Name):
+				n.Attrs[i].Value = fn(a.Value)
+			case a.Name == "style":
+				n.Attrs[i].Value = mapStyleColors(a.Value, fn)
+			}
+		}
+		if name, _ := svgName(n); name == "style" {
+			for _, c := range n.Children {
+				c.Text = mapStyleColors(c.Text, fn)
+			}
+		}
+		return true
+	})
+}
+
+func mapStyleColors(css string, fn func(string) string) string {
+	return paintDeclRegex.ReplaceAllStringFunc(css, func(m string) string {
+		sub := paintDeclRegex.FindStringSubmatch(m)
+		value := strings.TrimRight(sub[2], " \t\r\n")
+		return sub[1] + fn(value) + sub[2][len(value):]
+	})
+}
@@ -0,0 +1,1320 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"repokit/pkg/core"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ─── Optimization Passes ────────────────────────────────────────────────────
+
+// Pass is a named transformation of an SVG document. Passes run in order on
+// the parsed document, before it is handed to the minifier.
+type Pass struct {
+	Name        string
+	Description string
+	run         func(doc *Node, c *passContext)
+}
+
+// PassResult is what one pass saved on a file, in bytes of the document as
+// serialized before minification. Passes that enable later ones, such as
+// converting shapes to paths, may save nothing or even add bytes.
+type PassResult struct {
+	Name  string
+	Saved int64
+}
+
+// passContext carries the settings of the file being optimized and the
+// node counts reported by the geometric passes.
+type passContext struct {
+	opts        Options
+	nodesBefore int
+	nodesAfter  int
+}
+
+// passes lists every pass in its default order.
+var passes = []Pass{
+	{"remove_metadata", "Remove editor namespaces and, unless kept, <metadata>", removeEditorMetadata},
+	{"remove_hidden", "Remove elements that are never rendered", removeHidden},
+	{"convert_shapes", "Convert rectangles, lines, polylines and polygons to paths", convertShapes},
+	{"normalize_viewbox", "Fit the content into the configured viewBox, if any", normalizeViewBox},
+	{"flatten_transforms", "Apply transforms to path coordinates and push group transforms down", flattenTransforms},
+	{"collapse_groups", "Remove groups that do not change how their content renders", collapseGroups},
+	{"merge_paths", "Merge adjacent, non-overlapping paths with identical attributes", mergePaths},
+	{"simplify_paths", "Simplify outlines, moving them by at most the tolerance", simplifyPaths},
+	{"round_numbers", "Round coordinates and lengths to the precision", roundNumbers},
+	{"minify_ids", "Shorten referenced IDs and remove unreferenced ones", minifyIDs},
+	{"sort_attrs", "Write attributes in a fixed order, which compresses better", sortAttrs},
+}
+
+// Passes returns every pass in its default order.
+func Passes() []Pass {
+	return append([]Pass(nil), passes...)
+}
+
+func lookupPass(name string) (Pass, bool) {
+	for _, p := range passes {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Pass{}, false
+}
+
+func validatePasses(names []string) error {
+	for _, name := range names {
+		if _, ok := lookupPass(name); !ok {
+			known := make([]string, len(passes))
+			for i, p := range passes {
+				known[i] = p.Name
+			}
+			return fmt.Errorf("unknown svg pass %q (expected one of %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// runPasses runs the passes the options select on a document and returns
+// what each saved, along with the path nodes before and after
+// simplification.
+func runPasses(doc *Node, opts Options) (results []PassResult, nodesBefore, nodesAfter int) {
+	selected := passes
+	if opts.Passes != nil {
+		selected = nil
+		for _, name := range opts.Passes {
+			p, _ := lookupPass(name)
+			selected = append(selected, p)
+		}
+	}
+
+	c := &passContext{opts: opts}
+	size := len(doc.Bytes())
+	for _, p := range selected {
+		p.run(doc, c)
+		after := len(doc.Bytes())
+		results = append(results, PassResult{Name: p.Name, Saved: int64(size - after)})
+		size = after
+	}
+	return results, c.nodesBefore, c.nodesAfter
+}
+
+// ─── Document Helpers ───────────────────────────────────────────────────────
+
+// svgName returns the local name of an element in the SVG namespace, that
+// is unprefixed or prefixed with "svg".
+func svgName(n *Node) (string, bool) {
+	if n.Type != ElementNode {
+		return "", false
+	}
+	prefix, name, found := strings.Cut(n.Name, ":")
+	if !found {
+		return prefix, true
+	}
+	return name, prefix == "svg"
+}
+
+// forEachElement calls fn for every SVG element of a document. Content of
+// <metadata>, <foreignObject> and other namespaces is not SVG and is left
+// alone.
+func forEachElement(doc *Node, fn func(n *Node, name string)) {
+	doc.Walk(func(n *Node) bool {
+		if n.Type == DocumentNode {
+			return true
+		}
+		name, ok := svgName(n)
+		if !ok || name == "metadata" || name == "foreignObject" {
+			return false
+		}
+		fn(n, name)
+		return true
+	})
+}
+
+// hasElement reports whether a document contains an SVG element with one of
+// the names.
+func hasElement(doc *Node, names ...string) bool {
+	found := false
+	forEachElement(doc, func(n *Node, name string) {
+		for _, want := range names {
+			found = found || name == want
+		}
+	})
+	return found
+}
+
+// prune removes the nodes below n for which drop returns true.
+func prune(n *Node, drop func(*Node) bool) {
+	kept := n.Children[:0]
+	for _, c := range n.Children {
+		if drop(c) {
+			continue
+		}
+		prune(c, drop)
+		kept = append(kept, c)
+	}
+	n.Children = kept
+}
+
+// isBlank reports whether a node renders nothing by itself: whitespace,
+// comments and processing instructions.
+func isBlank(n *Node) bool {
+	switch n.Type {
+	case TextNode:
+		return strings.TrimSpace(n.Text) == ""
+	case CommentNode, ProcInstNode:
+		return true
+	}
+	return false
+}
+
+// parseNumber parses an attribute holding a plain number. Lengths with
+// units or percentages are not plain numbers.
+func parseNumber(v string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	return f, err == nil
+}
+
+// numberAttr returns a plain number attribute, or def when it is absent.
+func numberAttr(n *Node, name string, def float64) (float64, bool) {
+	v, ok := n.Attr(name)
+	if !ok {
+		return def, true
+	}
+	return parseNumber(v)
+}
+
+// parsePoints parses the 'points' attribute of a polygon or polyline.
+func parsePoints(points string) ([]Point, error) {
+	s := pathScanner{src: points}
+	var coords []float64
+	s.skipSpace()
+	for !s.done() {
+		if len(coords) > 0 {
+			s.skipSeparator()
+		}
+		v, err := s.number()
+		if err != nil {
+			return nil, err
+		}
+		coords = append(coords, v)
+		s.skipSpace()
+	}
+	if len(coords)%2 != 0 {
+		return nil, fmt.Errorf("odd number of coordinates in points")
+	}
+
+	pts := make([]Point, 0, len(coords)/2)
+	for i := 0; i < len(coords); i += 2 {
+		pts = append(pts, Point{X: coords[i], Y: coords[i+1]})
+	}
+	return pts, nil
+}
+
+// urlRefRegex matches references such as fill="url(#gradient)".
+var urlRefRegex = regexp.MustCompile(`url\(\s*['"]?#([^'")\s]+)['"]?\s*\)`)
+
+func isHref(name string) bool {
+	return name == "href" || strings.HasSuffix(name, ":href")
+}
+
+// referencedIDs returns the IDs that attributes or style sheets of a
+// document refer to.
+func referencedIDs(doc *Node) map[string]bool {
+	refs := make(map[string]bool)
+	doc.Walk(func(n *Node) bool {
+		for _, a := range n.Attrs {
+			if isHref(a.Name) && strings.HasPrefix(a.Value, "#") {
+				refs[a.Value[1:]] = true
+			}
+			for _, m := range urlRefRegex.FindAllStringSubmatch(a.Value, -1) {
+				refs[m[1]] = true
+			}
+		}
+		if name, _ := svgName(n); name == "style" {
+			for _, c := range n.Children {
+				for _, m := range urlRefRegex.FindAllStringSubmatch(c.Text, -1) {
+					refs[m[1]] = true
+				}
+			}
+		}
+		return true
+	})
+	return refs
+}
+
+// ─── remove_metadata ────────────────────────────────────────────────────────
+
+// editorNamespaces identify, by part of their URI, the namespaces drawing
+// applications write their own state into.
+var editorNamespaces = []string{
+	"inkscape.org",
+	"sodipodi",
+	"bohemiancoding.com/sketch",
+	"ns.adobe.com",
+	"serif.com",
+}
+
+func removeEditorMetadata(doc *Node, c *passContext) {
+	editor := make(map[string]bool)
+	doc.Walk(func(n *Node) bool {
+		for _, a := range n.Attrs {
+			prefix, ok := strings.CutPrefix(a.Name, "xmlns:")
+			if !ok {
+				continue
+			}
+			for _, ns := range editorNamespaces {
+				if strings.Contains(a.Value, ns) {
+					editor[prefix] = true
+				}
+			}
+		}
+		return true
+	})
+	isEditor := func(name string) bool {
+		prefix, _, found := strings.Cut(name, ":")
+		return found && editor[prefix]
+	}
+
+	prune(doc, func(n *Node) bool {
+		if n.Type != ElementNode {
+			return false
+		}
+		if name, ok := svgName(n); ok && name == "metadata" && !c.opts.KeepMetadata {
+			return true
+		}
+		if isEditor(n.Name) {
+			return true
+		}
+		kept := n.Attrs[:0]
+		for _, a := range n.Attrs {
+			if !isEditor(a.Name) {
+				kept = append(kept, a)
+			}
+		}
+		n.Attrs = kept
+		return false
+	})
+
+	// Declarations of namespaces nothing uses any more go as well.
+	used := usedPrefixes(doc)
+	doc.Walk(func(n *Node) bool {
+		kept := n.Attrs[:0]
+		for _, a := range n.Attrs {
+			if prefix, ok := strings.CutPrefix(a.Name, "xmlns:"); ok && !used[prefix] {
+				continue
+			}
+			kept = append(kept, a)
+		}
+		n.Attrs = kept
+		return true
+	})
+}
+
+// ─── remove_hidden ──────────────────────────────────────────────────────────
+
+// nonRendering are elements whose content is only rendered when referenced,
+// or chosen among, so visibility says nothing about it.
+var nonRendering = map[string]bool{
+	"defs": true, "symbol": true, "clipPath": true, "mask": true, "pattern": true,
+	"marker": true, "linearGradient": true, "radialGradient": true, "filter": true,
+	"switch": true, "metadata": true, "foreignObject": true,
+}
+
+// animations can change attributes, including visibility, after load.
+var animations = map[string]bool{
+	"animate": true, "animateMotion": true, "animateTransform": true, "set": true,
+}
+
+func removeHidden(doc *Node, c *passContext) {
+	refs := referencedIDs(doc)
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		kept := n.Children[:0]
+		for _, child := range n.Children {
+			name, ok := svgName(child)
+			if ok && n.Type != DocumentNode && isHidden(child, name) && !mustKeep(child, refs) {
+				continue
+			}
+			if ok && !nonRendering[name] {
+				walk(child)
+			}
+			kept = append(kept, child)
+		}
+		n.Children = kept
+	}
+	walk(doc)
+}
+
+// isHidden reports whether an element never renders anything.
+func isHidden(n *Node, name string) bool {
+	zero := func(attr string) bool {
+		v, ok := n.Attr(attr)
+		f, isNum := parseNumber(v)
+		return ok && isNum && f == 0
+	}
+	empty := func(attr string) bool {
+		v, _ := n.Attr(attr)
+		return strings.TrimSpace(v) == ""
+	}
+
+	if v, _ := n.Attr("display"); strings.TrimSpace(v) == "none" {
+		return true
+	}
+	if zero("opacity") {
+		return true
+	}
+	switch name {
+	case "rect":
+		return zero("width") || zero("height")
+	case "circle":
+		return zero("r")
+	case "ellipse":
+		return zero("rx") || zero("ry")
+	case "path":
+		return empty("d")
+	case "polygon", "polyline":
+		return empty("points")
+	case "g":
+		for _, c := range n.Children {
+			if !isBlank(c) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// mustKeep reports whether a hidden element may still matter: something
+// refers to it, or an animation may show it.
+func mustKeep(n *Node, refs map[string]bool) bool {
+	keep := false
+	n.Walk(func(c *Node) bool {
+		if id, ok := c.Attr("id"); ok && refs[id] {
+			keep = true
+		}
+		if name, ok := svgName(c); ok && animations[name] {
+			keep = true
+		}
+		return !keep
+	})
+	return keep
+}
+
+// ─── collapse_groups ────────────────────────────────────────────────────────
+
+// inheritedAttrs are the presentation attributes children inherit, so that
+// setting one on a group's only child, unless it has its own, renders the
+// same.
+var inheritedAttrs = map[string]bool{
+	"clip-rule": true, "color": true, "color-interpolation": true, "color-interpolation-filters": true,
+	"color-rendering": true, "cursor": true, "direction": true, "fill": true, "fill-opacity": true,
+	"fill-rule": true, "font": true, "font-family": true, "font-size": true, "font-size-adjust": true,
+	"font-stretch": true, "font-style": true, "font-variant": true, "font-weight": true,
+	"image-rendering": true, "letter-spacing": true, "marker": true, "marker-start": true,
+	"marker-mid": true, "marker-end": true, "paint-order": true, "pointer-events": true,
+	"shape-rendering": true, "stroke": true, "stroke-dasharray": true, "stroke-dashoffset": true,
+	"stroke-linecap": true, "stroke-linejoin": true, "stroke-miterlimit": true, "stroke-opacity": true,
+	"stroke-width": true, "text-anchor": true, "text-rendering": true, "visibility": true,
+	"word-spacing": true, "writing-mode": true,
+}
+
+// graphics are the elements a group's attributes can be moved onto.
+var graphics = map[string]bool{
+	"g": true, "path": true, "rect": true, "circle": true, "ellipse": true, "line": true,
+	"polyline": true, "polygon": true, "use": true, "text": true, "image": true,
+}
+
+// collapseGroups leaves documents with style sheets alone, since their
+// selectors may match the groups it removes.
+func collapseGroups(doc *Node, c *passContext) {
+	if hasElement(doc, "style") {
+		return
+	}
+	collapseIn(doc)
+}
+
+// collapseIn replaces the groups below n that have no attributes, or whose
+// attributes can move onto their only child, by their content. Inner groups
+// go first, so nested groups collapse in one run.
+func collapseIn(n *Node) {
+	var out []*Node
+	for _, child := range n.Children {
+		name, ok := svgName(child)
+		if !ok || name == "switch" || name == "metadata" || name == "foreignObject" {
+			out = append(out, child)
+			continue
+		}
+		collapseIn(child)
+		if name == "g" && !hasDescription(child) {
+			if len(child.Attrs) == 0 {
+				out = append(out, child.Children...)
+				continue
+			}
+			if only := soleChild(child); only != nil && moveGroupAttrs(child, only) {
+				out = append(out, child.Children...)
+				continue
+			}
+		}
+		out = append(out, child)
+	}
+	n.Children = out
+}
+
+// hasDescription reports whether a group carries a title or description,
+// which describe the group and would describe its parent once hoisted.
+func hasDescription(g *Node) bool {
+	for _, c := range g.Children {
+		if name, ok := svgName(c); ok && (name == "title" || name == "desc") {
+			return true
+		}
+	}
+	return false
+}
+
+// soleChild returns the only child of a group if it is a graphics element
+// and everything else in the group is blank.
+func soleChild(g *Node) *Node {
+	var only *Node
+	for _, c := range g.Children {
+		if isBlank(c) {
+			continue
+		}
+		if only != nil {
+			return nil
+		}
+		only = c
+	}
+	if only == nil {
+		return nil
+	}
+	if name, ok := svgName(only); !ok || !graphics[name] {
+		return nil
+	}
+	return only
+}
+
+// moveGroupAttrs moves the attributes of a group onto its only child. It
+// changes nothing and returns false if any attribute cannot move.
+func moveGroupAttrs(g, child *Node) bool {
+	for _, a := range g.Attrs {
+		_, childHas := child.Attr(a.Name)
+		switch {
+		case a.Name == "transform", inheritedAttrs[a.Name]:
+		case a.Name == "opacity" && !childHas:
+		default:
+			return false
+		}
+	}
+	for _, a := range g.Attrs {
+		v, childHas := child.Attr(a.Name)
+		switch {
+		case a.Name == "transform" && childHas:
+			// The group's transform applies outside of the child's.
+			child.SetAttr("transform", a.Value+" "+v)
+		case !childHas:
+			child.SetAttr(a.Name, a.Value)
+		}
+	}
+	return true
+}
+
+// ─── convert_shapes ─────────────────────────────────────────────────────────
+
+// shapeAttrs are the geometry attributes of basic shapes, which a path
+// replaces with 'd'.
+var shapeAttrs = map[string][]string{
+	"line":     {"x1", "y1", "x2", "y2"},
+	"rect":     {"x", "y", "width", "height", "rx", "ry"},
+	"circle":   {"cx", "cy", "r"},
+	"ellipse":  {"cx", "cy", "rx", "ry"},
+	"polyline": {"points"},
+	"polygon":  {"points"},
+}
+
+// Circles and ellipses are not converted: as arcs they are longer than the
+// elements they replace. Documents with style sheets are left alone, since
+// their selectors may match the shapes by element name.
+func convertShapes(doc *Node, c *passContext) {
+	if hasElement(doc, "style") {
+		return
+	}
+	forEachElement(doc, func(n *Node, name string) {
+		p, ok := shapePath(n, name)
+		if !ok {
+			return
+		}
+		for _, attr := range shapeAttrs[name] {
+			n.RemoveAttr(attr)
+		}
+		n.Name = strings.TrimSuffix(n.Name, name) + "path"
+		n.SetAttr("d", p.String())
+	})
+}
+
+// shapePath returns the path equivalent to a shape, if it has one that
+// renders the same.
+func shapePath(n *Node, name string) (Path, bool) {
+	num := func(attrs ...string) ([]float64, bool) {
+		out := make([]float64, len(attrs))
+		for i, a := range attrs {
+			v, ok := numberAttr(n, a, 0)
+			if !ok {
+				return nil, false
+			}
+			out[i] = v
+		}
+		return out, true
+	}
+
+	switch name {
+	case "rect":
+		// Rounded corners, and sizes that disable rendering, stay as they are.
+		if _, ok := n.Attr("rx"); ok {
+			return nil, false
+		}
+		if _, ok := n.Attr("ry"); ok {
+			return nil, false
+		}
+		v, ok := num("x", "y", "width", "height")
+		if !ok || v[2] <= 0 || v[3] <= 0 {
+			return nil, false
+		}
+		x, y, w, h := v[0], v[1], v[2], v[3]
+		return Path{
+			{Command: 'M', Args: []float64{x, y}},
+			{Command: 'H', Args: []float64{x + w}},
+			{Command: 'V', Args: []float64{y + h}},
+			{Command: 'H', Args: []float64{x}},
+			{Command: 'z'},
+		}, true
+	case "line":
+		v, ok := num("x1", "y1", "x2", "y2")
+		if !ok {
+			return nil, false
+		}
+		return Path{
+			{Command: 'M', Args: v[:2]},
+			{Command: 'L', Args: v[2:]},
+		}, true
+	case "polyline", "polygon":
+		points, _ := n.Attr("points")
+		pts, err := parsePoints(points)
+		if err != nil || len(pts) == 0 {
+			return nil, false
+		}
+		p := make(Path, 0, len(pts)+1)
+		for i, pt := range pts {
+			c := CommandType('L')
+			if i == 0 {
+				c = 'M'
+			}
+			p = append(p, Segment{Command: c, Args: []float64{pt.X, pt.Y}})
+		}
+		if name == "polygon" {
+			p = append(p, Segment{Command: 'z'})
+		}
+		return p, true
+	}
+	return nil, false
+}
+
+// ─── normalize_viewbox ──────────────────────────────────────────────────────
+
+// normalizeViewBox scales and centers the content of a document into the
+// configured viewBox, less the padding, and sets it as the document's
+// viewBox. The content is wrapped in a transformed group, which
+// flatten_transforms then applies to the coordinates where it can.
+// Documents whose extent cannot be computed are left alone.
+func normalizeViewBox(doc *Node, c *passContext) {
+	if c.opts.ViewBox == "" {
+		return
+	}
+	box, err := core.ParseViewBox(c.opts.ViewBox)
+	if err != nil {
+		return
+	}
+	root := rootElement(doc)
+	if root == nil || hasElement(root, "style") {
+		return
+	}
+	lo, hi, ok := contentBounds(root)
+	if !ok {
+		return
+	}
+
+	pad := c.opts.Padding
+	innerW, innerH := box[2]-2*pad, box[3]-2*pad
+	w, h := hi.X-lo.X, hi.Y-lo.Y
+	scale := math.Inf(1)
+	if w > exact {
+		scale = innerW / w
+	}
+	if h > exact {
+		scale = math.Min(scale, innerH/h)
+	}
+	if innerW <= 0 || innerH <= 0 || math.IsInf(scale, 1) {
+		return
+	}
+
+	m := Translate(box[0]+box[2]/2, box[1]+box[3]/2).
+		Mul(Scale(scale, scale)).
+		Mul(Translate(-(lo.X+hi.X)/2, -(lo.Y+hi.Y)/2))
+	if !nearIdentity(m) {
+		wrapContent(root, m)
+	}
+	root.SetAttr("viewBox", strings.Join(formatArgs(box[:], -1), " "))
+}
+
+// rootElement returns the outermost <svg> element of a document.
+func rootElement(doc *Node) *Node {
+	for _, c := range doc.Children {
+		if name, ok := svgName(c); ok && name == "svg" {
+			return c
+		}
+	}
+	return nil
+}
+
+func nearIdentity(m Matrix) bool {
+	const eps = 1e-6
+	return math.Abs(m.A-1) < eps && math.Abs(m.B) < eps && math.Abs(m.C) < eps &&
+		math.Abs(m.D-1) < eps && math.Abs(m.E) < eps && math.Abs(m.F) < eps
+}
+
+// wrapContent moves the rendered children of the root into a group with
+// the transform, placed where the first of them was.
+func wrapContent(root *Node, m Matrix) {
+	g := &Node{
+		Type:  ElementNode,
+		Name:  strings.TrimSuffix(root.Name, "svg") + "g",
+		Attrs: []Attr{{Name: "transform", Value: m.String()}},
+	}
+	var out []*Node
+	for _, c := range root.Children {
+		name, ok := svgName(c)
+		if !ok || nonRendering[name] || !graphics[name] && name != "a" && name != "svg" {
+			out = append(out, c)
+			continue
+		}
+		if len(g.Children) == 0 {
+			out = append(out, g)
+		}
+		g.Children = append(g.Children, c)
+	}
+	root.Children = out
+}
+
+// contentBounds returns the box the rendered content of an <svg> element
+// covers in its user space, strokes included. Strokes widen the box by half
+// their width; sharp miter joins may reach slightly further. It reports
+// false when some content cannot be measured: text, images, <use>
+// references, nested viewports, <switch> and elements styled by CSS.
+func contentBounds(root *Node) (lo, hi Point, ok bool) {
+	lo = Point{math.Inf(1), math.Inf(1)}
+	hi = Point{math.Inf(-1), math.Inf(-1)}
+	ok = true
+
+	var walk func(n *Node, ctm Matrix, s paintState)
+	walk = func(n *Node, ctm Matrix, s paintState) {
+		for _, child := range n.Children {
+			name, isSVG := svgName(child)
+			if name == "switch" || name == "foreignObject" {
+				ok = false
+			}
+			if !ok || !isSVG || nonRendering[name] || !graphics[name] && name != "a" && name != "svg" {
+				continue
+			}
+			if isHidden(child, name) {
+				continue
+			}
+			cs := s.inherit(child)
+			m := ctm
+			if t, has := child.Attr("transform"); has {
+				tm, err := ParseTransform(t)
+				if err != nil {
+					ok = false
+					return
+				}
+				m = ctm.Mul(tm)
+			}
+
+			if name == "g" || name == "a" {
+				walk(child, m, cs)
+				continue
+			}
+			p, known := outline(child, name)
+			if !known || cs.styled || cs.stroked && !cs.widthOK {
+				ok = false
+				return
+			}
+			if !cs.filled && !cs.stroked {
+				continue
+			}
+			plo, phi := p.Transform(m).Bounds()
+			if cs.stroked {
+				r := cs.width / 2 * math.Max(math.Hypot(m.A, m.B), math.Hypot(m.C, m.D))
+				plo, phi = Point{plo.X - r, plo.Y - r}, Point{phi.X + r, phi.Y + r}
+			}
+			lo = Point{math.Min(lo.X, plo.X), math.Min(lo.Y, plo.Y)}
+			hi = Point{math.Max(hi.X, phi.X), math.Max(hi.Y, phi.Y)}
+		}
+	}
+	walk(root, Identity, paintState{filled: true, width: 1, widthOK: true}.inherit(root))
+	return lo, hi, ok && lo.X <= hi.X && lo.Y <= hi.Y
+}
+
+// outline returns a path covering the outline of a shape. Rounded
+// rectangles are covered by their corners.
+func outline(n *Node, name string) (Path, bool) {
+	num := func(attr string) float64 {
+		v, _ := numberAttr(n, attr, 0)
+		return v
+	}
+	switch name {
+	case "path":
+		d, _ := n.Attr("d")
+		p, err := ParsePath(d)
+		return p, err == nil
+	case "circle", "ellipse":
+		rx, ry := num("rx"), num("ry")
+		if name == "circle" {
+			rx, ry = num("r"), num("r")
+		}
+		cx, cy := num("cx"), num("cy")
+		return Path{
+			{Command: 'M', Args: []float64{cx - rx, cy}},
+			{Command: 'A', Args: []float64{rx, ry, 0, 1, 0, cx + rx, cy}},
+			{Command: 'A', Args: []float64{rx, ry, 0, 1, 0, cx - rx, cy}},
+		}, true
+	case "rect":
+		x, y, w, h := num("x"), num("y"), num("width"), num("height")
+		return Path{
+			{Command: 'M', Args: []float64{x, y}},
+			{Command: 'H', Args: []float64{x + w}},
+			{Command: 'V', Args: []float64{y + h}},
+			{Command: 'H', Args: []float64{x}},
+			{Command: 'z'},
+		}, true
+	}
+	return shapePath(n, name)
+}
+
+// ─── flatten_transforms ─────────────────────────────────────────────────────
+
+// paintState is what an element inherits that decides whether its
+// transform can be applied to its coordinates.
+type paintState struct {
+	filled, stroked bool
+	width           float64 // stroke-width
+	widthOK         bool    // stroke-width is a plain number
+	dashed          bool
+	referenced      bool // painted with a gradient or pattern, or has markers
+	styled          bool // a class or style attribute may change any of it
+}
+
+// inherit returns the state of an element given its parent's.
+func (s paintState) inherit(n *Node) paintState {
+	if v, ok := n.Attr("fill"); ok {
+		s.filled = strings.TrimSpace(v) != "none"
+	}
+	if v, ok := n.Attr("stroke"); ok {
+		s.stroked = strings.TrimSpace(v) != "none"
+	}
+	if v, ok := n.Attr("stroke-width"); ok {
+		s.width, s.widthOK = parseNumber(v)
+	}
+	if v, ok := n.Attr("stroke-dasharray"); ok {
+		s.dashed = strings.TrimSpace(v) != "none"
+	}
+	for _, a := range n.Attrs {
+		switch a.Name {
+		case "fill", "stroke", "marker", "marker-start", "marker-mid", "marker-end":
+			if strings.Contains(a.Value, "url(") {
+				s.referenced = true
+			}
+		case "class", "style", "vector-effect":
+			s.styled = true
+		}
+	}
+	return s
+}
+
+// flattenTransforms applies the transforms of shapes to their coordinates
+// and pushes the transforms of groups down to their children, leaving the
+// groups for collapse_groups to remove. Anything that would render
+// differently keeps its transform: content painted or clipped in its own
+// user space, and strokes that a transform would distort rather than
+// scale. Documents with style sheets are left alone, since their rules
+// may set either.
+func flattenTransforms(doc *Node, c *passContext) {
+	if hasElement(doc, "style") {
+		return
+	}
+	flattenIn(doc, paintState{filled: true, width: 1, widthOK: true})
+}
+
+func flattenIn(n *Node, s paintState) {
+	for _, child := range n.Children {
+		name, ok := svgName(child)
+		if !ok || name != "svg" && name != "a" && !graphics[name] {
+			continue
+		}
+		cs := s.inherit(child)
+		if t, has := child.Attr("transform"); has && name != "svg" {
+			if m, err := ParseTransform(t); err == nil {
+				var done bool
+				if name == "g" || name == "a" {
+					done = pushTransform(child, t)
+				} else {
+					done = applyTransform(child, name, m, cs)
+				}
+				if done {
+					child.RemoveAttr("transform")
+				}
+			}
+		}
+		if name == "svg" || name == "g" || name == "a" {
+			flattenIn(child, cs)
+		}
+	}
+}
+
+// ownsUserSpace reports whether an element has attributes that refer to
+// content drawn in its user space, such as clip paths, masks and filters,
+// or that CSS may apply to.
+func ownsUserSpace(n *Node) bool {
+	for _, a := range n.Attrs {
+		if strings.Contains(a.Value, "url(") || a.Name == "class" || a.Name == "style" {
+			return true
+		}
+	}
+	return false
+}
+
+// pushTransform prepends a group's transform to those of its children.
+func pushTransform(g *Node, t string) bool {
+	if ownsUserSpace(g) {
+		return false
+	}
+	for _, c := range g.Children {
+		name, ok := svgName(c)
+		if c.Type == ElementNode && (!ok || !graphics[name] && name != "a" && name != "title" && name != "desc") {
+			return false
+		}
+	}
+	for _, c := range g.Children {
+		if name, _ := svgName(c); graphics[name] || name == "a" {
+			if v, has := c.Attr("transform"); has {
+				c.SetAttr("transform", t+" "+v)
+			} else {
+				c.SetAttr("transform", t)
+			}
+		}
+	}
+	return true
+}
+
+// applyTransform rewrites the geometry of a shape with its transform. A
+// stroke keeps its look under transforms that scale uniformly, its width
+// scaled along.
+func applyTransform(n *Node, name string, m Matrix, s paintState) bool {
+	if ownsUserSpace(n) || s.referenced || s.styled && !m.IsTranslation() {
+		return false
+	}
+	for _, c := range n.Children {
+		if cname, _ := svgName(c); c.Type == ElementNode && cname != "title" && cname != "desc" {
+			return false
+		}
+	}
+	scale, uniform := m.Similarity()
+	if s.stroked && !m.IsTranslation() && (!uniform || !s.widthOK || s.dashed) {
+		return false
+	}
+	if !transformGeometry(n, name, m) {
+		return false
+	}
+	if s.stroked && math.Abs(scale-1) > exact {
+		n.SetAttr("stroke-width", formatNumber(s.width*scale))
+	}
+	return true
+}
+
+// transformGeometry rewrites the geometry attributes of a shape with a
+// transform, if the shape stays the same kind: circles under uniform
+// scaling, ellipses and rectangles as long as their axes stay aligned.
+func transformGeometry(n *Node, name string, m Matrix) bool {
+	nums := func(attrs ...string) ([]float64, bool) {
+		out := make([]float64, len(attrs))
+		for i, a := range attrs {
+			v, ok := numberAttr(n, a, 0)
+			if !ok {
+				return nil, false
+			}
+			out[i] = v
+		}
+		return out, true
+	}
+	set := func(values map[string]float64) {
+		for _, attr := range shapeAttrs[name] {
+			v, ok := values[attr]
+			if _, had := n.Attr(attr); ok && (had || v != 0) {
+				n.SetAttr(attr, formatNumber(v))
+			}
+		}
+	}
+	scale, uniform := m.Similarity()
+	aligned := m.B == 0 && m.C == 0
+
+	switch name {
+	case "path":
+		d, _ := n.Attr("d")
+		p, err := ParsePath(d)
+		if err != nil {
+			return false
+		}
+		n.SetAttr("d", p.Transform(m).String())
+	case "line":
+		v, ok := nums("x1", "y1", "x2", "y2")
+		if !ok {
+			return false
+		}
+		p1, p2 := m.Apply(Point{v[0], v[1]}), m.Apply(Point{v[2], v[3]})
+		set(map[string]float64{"x1": p1.X, "y1": p1.Y, "x2": p2.X, "y2": p2.Y})
+	case "polyline", "polygon":
+		points, _ := n.Attr("points")
+		pts, err := parsePoints(points)
+		if err != nil {
+			return false
+		}
+		coords := make([]string, len(pts))
+		for i, q := range pts {
+			q = m.Apply(q)
+			coords[i] = formatNumber(q.X) + "," + formatNumber(q.Y)
+		}
+		n.SetAttr("points", strings.Join(coords, " "))
+	case "circle":
+		v, ok := nums("cx", "cy", "r")
+		if !ok || !uniform {
+			return false
+		}
+		c := m.Apply(Point{v[0], v[1]})
+		set(map[string]float64{"cx": c.X, "cy": c.Y, "r": v[2] * scale})
+	case "ellipse":
+		v, ok := nums("cx", "cy", "rx", "ry")
+		if !ok || !aligned {
+			return false
+		}
+		c := m.Apply(Point{v[0], v[1]})
+		set(map[string]float64{"cx": c.X, "cy": c.Y, "rx": v[2] * math.Abs(m.A), "ry": v[3] * math.Abs(m.D)})
+	case "rect":
+		v, ok := nums("x", "y", "width", "height")
+		if !ok || !aligned {
+			return false
+		}
+		p1, p2 := m.Apply(Point{v[0], v[1]}), m.Apply(Point{v[0] + v[2], v[1] + v[3]})
+		values := map[string]float64{
+			"x": math.Min(p1.X, p2.X), "y": math.Min(p1.Y, p2.Y),
+			"width": v[2] * math.Abs(m.A), "height": v[3] * math.Abs(m.D),
+		}
+		// A missing corner radius takes the other one's value, which
+		// scaling by different factors would no longer give.
+		_, hasRX := n.Attr("rx")
+		_, hasRY := n.Attr("ry")
+		if hasRX || hasRY {
+			r, ok := nums("rx", "ry")
+			if !ok {
+				return false
+			}
+			if !hasRX {
+				r[0] = r[1]
+			}
+			if !hasRY {
+				r[1] = r[0]
+			}
+			values["rx"], values["ry"] = r[0]*math.Abs(m.A), r[1]*math.Abs(m.D)
+		}
+		set(values)
+	default:
+		return false
+	}
+	return true
+}
+
+// ─── merge_paths ────────────────────────────────────────────────────────────
+
+// unmergeable are attributes that tie a path's rendering to it being a
+// separate element: overlapping translucent paths blend, and markers are
+// placed per element.
+var unmergeable = map[string]bool{
+	"id": true, "style": true, "class": true, "opacity": true, "fill-opacity": true,
+	"stroke-opacity": true, "marker": true, "marker-start": true, "marker-mid": true,
+	"marker-end": true,
+}
+
+func mergePaths(doc *Node, c *passContext) {
+	// Gradients and patterns span the bounding box of the element they
+	// paint, which merging would change.
+	if hasElement(doc, "linearGradient", "radialGradient", "pattern") {
+		return
+	}
+
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		out := n.Children[:0]
+		var prev *Node
+		for _, child := range n.Children {
+			if isBlank(child) {
+				out = append(out, child)
+				continue
+			}
+			name, ok := svgName(child)
+			if ok && name == "path" && mergeCandidate(child) {
+				if prev != nil && mergeInto(prev, child) {
+					continue
+				}
+				prev = child
+			} else {
+				prev = nil
+			}
+			if ok && name != "metadata" && name != "foreignObject" {
+				walk(child)
+			}
+			out = append(out, child)
+		}
+		n.Children = out
+	}
+	walk(doc)
+}
+
+func mergeCandidate(n *Node) bool {
+	for _, c := range n.Children {
+		if !isBlank(c) {
+			return false
+		}
+	}
+	for _, a := range n.Attrs {
+		if unmergeable[a.Name] || strings.Contains(a.Value, "url(") {
+			return false
+		}
+	}
+	d, _ := n.Attr("d")
+	_, err := ParsePath(d)
+	return err == nil
+}
+
+// mergeInto appends the outline of b to a if both have the same attributes
+// and their outlines are apart, so that no fill rule can tell them apart.
+func mergeInto(a, b *Node) bool {
+	if len(a.Attrs) != len(b.Attrs) {
+		return false
+	}
+	for _, attr := range a.Attrs {
+		if v, ok := b.Attr(attr.Name); !ok || (attr.Name != "d" && v != attr.Value) {
+			return false
+		}
+	}
+
+	da, _ := a.Attr("d")
+	db, _ := b.Attr("d")
+	pa, _ := ParsePath(da)
+	pb, _ := ParsePath(db)
+	aMin, aMax := pa.Bounds()
+	bMin, bMax := pb.Bounds()
+	if aMin.X <= bMax.X && bMin.X <= aMax.X && aMin.Y <= bMax.Y && bMin.Y <= aMax.Y {
+		return false
+	}
+
+	// The first moveto of a path is absolute even when written relative,
+	// but not once it follows another subpath.
+	pb[0].Command = 'M'
+	a.SetAttr("d", append(pa, pb...).String())
+	return true
+}
+
+// ─── simplify_paths ─────────────────────────────────────────────────────────
+
+func simplifyPaths(doc *Node, c *passContext) {
+	forEachElement(doc, func(n *Node, name string) {
+		switch name {
+		case "path":
+			if d, ok := n.Attr("d"); ok {
+				d, nb, na := processPathData(d, c.opts.Tolerance, c.opts.Precision)
+				n.SetAttr("d", d)
+				c.nodesBefore, c.nodesAfter = c.nodesBefore+nb, c.nodesAfter+na
+			}
+		case "polygon", "polyline":
+			points, _ := n.Attr("points")
+			pts, err := parsePoints(points)
+			if err != nil {
+				return
+			}
+			simplified := SnapPointsToAxes(SimplifyPath(pts, c.opts.Tolerance), c.opts.SnapAngle)
+			n.SetAttr("points", FormatPointsData(simplified, c.opts.Precision))
+			c.nodesBefore, c.nodesAfter = c.nodesBefore+len(pts), c.nodesAfter+len(simplified)
+		}
+	})
+}
+
+// ─── round_numbers ──────────────────────────────────────────────────────────
+
+func roundNumbers(doc *Node, c *passContext) {
+	precision := c.opts.Precision
+	forEachElement(doc, func(n *Node, name string) {
+		switch name {
+		case "path":
+			if d, ok := n.Attr("d"); ok {
+				if p, err := ParsePath(d); err == nil {
+					n.SetAttr("d", FormatPath(p, precision))
+				}
+			}
+		case "polygon", "polyline":
+			points, _ := n.Attr("points")
+			if pts, err := parsePoints(points); err == nil {
+				n.SetAttr("points", FormatPointsData(pts, precision))
+			}
+		default:
+			for _, attr := range append([]string{"stroke-width"}, shapeAttrs[name]...) {
+				v, ok := n.Attr(attr)
+				if !ok {
+					continue
+				}
+				// Lengths with units or percentages are kept as written.
+				if f, ok := parseNumber(v); ok {
+					n.SetAttr(attr, formatNumber(SmartRound(f, precision)))
+				}
+			}
+		}
+	})
+}
+
+// ─── minify_ids ─────────────────────────────────────────────────────────────
+
+// The ID of the root element is how other documents refer to this one, so
+// it is kept as it is.
+func minifyIDs(doc *Node, c *passContext) {
+	// Scripts, style sheets and animations can refer to IDs in ways that
+	// cannot be rewritten reliably.
+	if hasElement(doc, "script", "style", "animate", "animateMotion", "animateTransform", "set") {
+		return
+	}
+
+	refs := referencedIDs(doc)
+	var root *Node
+	taken := make(map[string]bool)
+	for _, n := range doc.Children {
+		if n.Type == ElementNode {
+			root = n
+			if id, ok := n.Attr("id"); ok {
+				taken[id] = true
+			}
+			break
+		}
+	}
+
+	renamed := make(map[string]string)
+	next := 0
+	forEachElement(doc, func(n *Node, name string) {
+		id, ok := n.Attr("id")
+		if !ok || n == root {
+			return
+		}
+		if !refs[id] {
+			n.RemoveAttr("id")
+			return
+		}
+		short, done := renamed[id]
+		for !done {
+			short = shortID(next)
+			next++
+			done = !taken[short]
+		}
+		renamed[id] = short
+		n.SetAttr("id", short)
+	})
+
+	renameRefs(doc, renamed)
+}
+
+// renameRefs rewrites the references of a document to the IDs in renamed:
+// hrefs, url() values and url() values in style sheets.
+func renameRefs(doc *Node, renamed map[string]string) {
+	rename := func(v string) string {
+		return urlRefRegex.ReplaceAllStringFunc(v, func(m string) string {
+			id := urlRefRegex.FindStringSubmatch(m)[1]
+			if to, ok := renamed[id]; ok {
+				return "url(#" + to + ")"
+			}
+			return m
+		})
+	}
+	doc.Walk(func(n *Node) bool {
+		for i, a := range n.Attrs {
+			if to, ok := renamed[strings.TrimPrefix(a.Value, "#")]; ok && isHref(a.Name) && strings.HasPrefix(a.Value, "#") {
+				n.Attrs[i].Value = "#" + to
+				continue
+			}
+			n.Attrs[i].Value = rename(a.Value)
+		}
+		if name, _ := svgName(n); name == "style" {
+			for _, c := range n.Children {
+				c.Text = rename(c.Text)
+			}
+		}
+		return true
+	})
+}
+
+const idLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// shortID returns the i-th shortest ID: a…Z, then aa, ab and so on.
+func shortID(i int) string {
+	var b []byte
+	for {
+		b = append([]byte{idLetters[i%len(idLetters)]}, b...)
+		i = i/len(idLetters) - 1
+		if i < 0 {
+			return string(b)
+		}
+	}
+}
+
+// ─── sort_attrs ─────────────────────────────────────────────────────────────
+
+// attrOrder puts identity and geometry first, then painting, then the
+// outline, with other attributes following in alphabetical order.
+var attrOrder = []string{
+	"id", "width", "height", "x", "x1", "x2", "y", "y1", "y2",
+	"cx", "cy", "r", "rx", "ry", "fill", "stroke", "marker", "d", "points",
+}
+
+func sortAttrs(doc *Node, c *passContext) {
+	rank := func(name string) int {
+		switch {
+		case name == "xmlns":
+			return -2
+		case strings.HasPrefix(name, "xmlns:"):
+			return -1
+		}
+		for i, o := range attrOrder {
+			if o == name {
+				return i
+			}
+		}
+		return len(attrOrder)
+	}
+	doc.Walk(func(n *Node) bool {
+		sort.SliceStable(n.Attrs, func(i, j int) bool {
+			ri, rj := rank(n.Attrs[i].Name), rank(n.Attrs[j].Name)
+			if ri != rj {
+				return ri < rj
+			}
+			return n.Attrs[i].Name < n.Attrs[j].Name
+		})
+		return true
+	})
+}
@@ -0,0 +1,410 @@
+package svg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ─── Path AST ───────────────────────────────────────────────────────────────
+
+// CommandType defines the SVG path instruction: an uppercase letter for
+// absolute coordinates, lowercase for relative ones.
+type CommandType byte
+
+// Segment is a single path instruction with the numbers of one occurrence.
+// Repeated parameter groups ("L1 2 3 4") are split into one segment each,
+// and the groups implicitly following a moveto become linetos.
+type Segment struct {
+	Command CommandType
+	Args    []float64
+}
+
+// Path is a parsed 'd' attribute. Subpaths start at each moveto.
+type Path []Segment
+
+// argCounts is the number of parameters each command takes.
+var argCounts = map[CommandType]int{
+	'M': 2, 'L': 2, 'H': 1, 'V': 1, 'C': 6, 'S': 4, 'Q': 4, 'T': 2, 'A': 7, 'Z': 0,
+}
+
+// Upper returns the absolute form of the command.
+func (c CommandType) Upper() CommandType {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+// IsRelative reports whether the command takes relative coordinates.
+func (c CommandType) IsRelative() bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func (c CommandType) withCase(relative bool) CommandType {
+	if relative {
+		return c.Upper() - 'A' + 'a'
+	}
+	return c.Upper()
+}
+
+// ─── Parsing ────────────────────────────────────────────────────────────────
+
+// ParsePath parses a 'd' attribute following the SVG path grammar. Unlike
+// browsers, which render up to the first error, it rejects malformed data
+// so that callers never rewrite a path they only partly understood.
+func ParsePath(d string) (Path, error) {
+	s := pathScanner{src: d}
+	var path Path
+
+	s.skipSpace()
+	for !s.done() {
+		c := CommandType(s.src[s.pos])
+		count, ok := argCounts[c.Upper()]
+		if !ok {
+			return nil, s.errorf("unexpected %q", s.src[s.pos])
+		}
+		if len(path) == 0 && c.Upper() != 'M' {
+			return nil, s.errorf("path must start with a moveto, got %q", c)
+		}
+		s.pos++
+
+		if count == 0 {
+			path = append(path, Segment{Command: c})
+			s.skipSpace()
+			continue
+		}
+
+		for first := true; first || s.startsNumber(); first = false {
+			args := make([]float64, count)
+			for i := range args {
+				if i > 0 || !first {
+					s.skipSeparator()
+				} else {
+					s.skipSpace()
+				}
+				var err error
+				if c.Upper() == 'A' && (i == 3 || i == 4) {
+					args[i], err = s.flag()
+				} else {
+					args[i], err = s.number()
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			path = append(path, Segment{Command: c, Args: args})
+
+			// Extra coordinate pairs after a moveto are implicit linetos.
+			if c.Upper() == 'M' {
+				c = CommandType('L').withCase(c.IsRelative())
+			}
+			s.skipSpace()
+		}
+	}
+	return path, nil
+}
+
+type pathScanner struct {
+	src string
+	pos int
+}
+
+func (s *pathScanner) done() bool { return s.pos >= len(s.src) }
+
+func (s *pathScanner) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid path data at offset %d: %s", s.pos, fmt.Sprintf(format, args...))
+}
+
+func (s *pathScanner) skipSpace() {
+	for !s.done() && strings.IndexByte(" \t\n\r\f", s.src[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+// skipSeparator skips whitespace with at most one comma.
+func (s *pathScanner) skipSeparator() {
+	s.skipSpace()
+	if !s.done() && s.src[s.pos] == ',' {
+		s.pos++
+		s.skipSpace()
+	}
+}
+
+// startsNumber reports whether another parameter group follows, possibly
+// after a comma.
+func (s *pathScanner) startsNumber() bool {
+	i := s.pos
+	if i < len(s.src) && s.src[i] == ',' {
+		i++
+		for i < len(s.src) && strings.IndexByte(" \t\n\r\f", s.src[i]) >= 0 {
+			i++
+		}
+	}
+	return i < len(s.src) && strings.IndexByte("0123456789.-+", s.src[i]) >= 0
+}
+
+func (s *pathScanner) number() (float64, error) {
+	start := s.pos
+	if !s.done() && (s.src[s.pos] == '+' || s.src[s.pos] == '-') {
+		s.pos++
+	}
+	digits := s.digits()
+	if !s.done() && s.src[s.pos] == '.' {
+		s.pos++
+		digits += s.digits()
+	}
+	if digits == 0 {
+		s.pos = start
+		if s.done() {
+			return 0, s.errorf("expected a number, got end of data")
+		}
+		return 0, s.errorf("expected a number, got %q", s.src[s.pos])
+	}
+	if !s.done() && (s.src[s.pos] == 'e' || s.src[s.pos] == 'E') {
+		mark := s.pos
+		s.pos++
+		if !s.done() && (s.src[s.pos] == '+' || s.src[s.pos] == '-') {
+			s.pos++
+		}
+		if s.digits() == 0 {
+			s.pos = mark // not an exponent
+		}
+	}
+	return strconv.ParseFloat(s.src[start:s.pos], 64)
+}
+
+func (s *pathScanner) digits() int {
+	n := 0
+	for !s.done() && s.src[s.pos] >= '0' && s.src[s.pos] <= '9' {
+		s.pos++
+		n++
+	}
+	return n
+}
+
+// flag reads an arc flag, a single 0 or 1 that needs no separator from
+// what follows ("a1 1 0 0110 10").
+func (s *pathScanner) flag() (float64, error) {
+	if s.done() || (s.src[s.pos] != '0' && s.src[s.pos] != '1') {
+		return 0, s.errorf("expected an arc flag (0 or 1)")
+	}
+	s.pos++
+	return float64(s.src[s.pos-1] - '0'), nil
+}
+
+// ─── Absolute Coordinates ───────────────────────────────────────────────────
+
+// Absolute returns the path with every segment in absolute coordinates.
+// Command types are otherwise kept, so H, V and shorthand curves remain.
+func (p Path) Absolute() Path {
+	out := make(Path, len(p))
+	var cur, start Point
+	for i, seg := range p {
+		args := append([]float64(nil), seg.Args...)
+		c := seg.Command.Upper()
+		if seg.Command.IsRelative() && i > 0 {
+			switch c {
+			case 'H':
+				args[0] += cur.X
+			case 'V':
+				args[0] += cur.Y
+			case 'A':
+				args[5] += cur.X
+				args[6] += cur.Y
+			default:
+				for j := 0; j+1 < len(args); j += 2 {
+					args[j] += cur.X
+					args[j+1] += cur.Y
+				}
+			}
+		}
+		out[i] = Segment{Command: c, Args: args}
+
+		cur = endPoint(out[i], cur, start)
+		if c == 'M' {
+			start = cur
+		}
+	}
+	return out
+}
+
+// endPoint returns where an absolute segment leaves the current point.
+func endPoint(seg Segment, cur, start Point) Point {
+	switch seg.Command {
+	case 'Z':
+		return start
+	case 'H':
+		return Point{seg.Args[0], cur.Y}
+	case 'V':
+		return Point{cur.X, seg.Args[0]}
+	default:
+		n := len(seg.Args)
+		return Point{seg.Args[n-2], seg.Args[n-1]}
+	}
+}
+
+// ─── Serialization ──────────────────────────────────────────────────────────
+
+// String serializes the path as parsed, in its most compact notation, so
+// that parsing the result yields the same path.
+func (p Path) String() string {
+	return FormatPath(p, -1)
+}
+
+// FormatPath serializes a path compactly: repeated commands and separators
+// are omitted where the grammar allows. A negative precision keeps every
+// segment exactly as it is. Otherwise coordinates are rounded to that many
+// decimals in absolute space, so that rounding does not accumulate along
+// relative segments, and each segment is written in whichever of its
+// absolute or relative forms is shorter.
+func FormatPath(p Path, precision int) string {
+	var w pathWriter
+	if precision < 0 {
+		for _, seg := range p {
+			w.segment(seg.Command, formatArgs(seg.Args, -1))
+		}
+		return w.sb.String()
+	}
+
+	var cur, start Point
+	for i, seg := range p.Absolute() {
+		for j := range seg.Args {
+			seg.Args[j] = SmartRound(seg.Args[j], precision)
+		}
+
+		abs := formatArgs(seg.Args, precision)
+		best, relative := abs, false
+		if i > 0 && seg.Command != 'Z' {
+			rel := formatArgs(relativeArgs(seg, cur), precision)
+			l, r := w.cost(seg.Command, abs), w.cost(seg.Command.withCase(true), rel)
+			if r < l || (r == l && p[i].Command.IsRelative()) {
+				best, relative = rel, true
+			}
+		} else if seg.Command == 'Z' {
+			relative = p[i].Command.IsRelative()
+		}
+		w.segment(seg.Command.withCase(relative), best)
+
+		cur = endPoint(seg, cur, start)
+		if seg.Command == 'M' {
+			start = cur
+		}
+	}
+	return w.sb.String()
+}
+
+// relativeArgs converts the arguments of an absolute segment to be
+// relative to cur.
+func relativeArgs(seg Segment, cur Point) []float64 {
+	args := append([]float64(nil), seg.Args...)
+	switch seg.Command {
+	case 'H':
+		args[0] -= cur.X
+	case 'V':
+		args[0] -= cur.Y
+	case 'A':
+		args[5] -= cur.X
+		args[6] -= cur.Y
+	default:
+		for j := 0; j+1 < len(args); j += 2 {
+			args[j] -= cur.X
+			args[j+1] -= cur.Y
+		}
+	}
+	return args
+}
+
+func formatArgs(args []float64, precision int) []string {
+	out := make([]string, len(args))
+	for i, v := range args {
+		if precision >= 0 {
+			v = SmartRound(v, precision)
+		}
+		out[i] = formatNumber(v)
+	}
+	return out
+}
+
+// formatNumber writes the shortest decimal that parses back to v, without
+// a leading zero before the decimal point.
+func formatNumber(v float64) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	switch {
+	case s == "-0":
+		return "0"
+	case strings.HasPrefix(s, "0."):
+		return s[1:]
+	case strings.HasPrefix(s, "-0."):
+		return "-" + s[2:]
+	}
+	return s
+}
+
+// argsLen is the length of the arguments once written with separators.
+func argsLen(args []string) int {
+	n := 0
+	for i, a := range args {
+		n += len(a)
+		if i > 0 && needsSeparator(args[i-1], a) {
+			n++
+		}
+	}
+	return n
+}
+
+// needsSeparator reports whether a space must be written between two
+// numbers for them to parse back as two.
+func needsSeparator(prev, next string) bool {
+	if next[0] == '-' {
+		return false
+	}
+	return !(next[0] == '.' && strings.Contains(prev, "."))
+}
+
+type pathWriter struct {
+	sb      strings.Builder
+	last    CommandType
+	lastArg string
+}
+
+// needsLetter reports whether c must be written, or whether the grammar
+// repeats the previous command implicitly.
+func (w *pathWriter) needsLetter(c CommandType) bool {
+	implicit := w.last
+	switch w.last {
+	case 'M':
+		implicit = 'L'
+	case 'm':
+		implicit = 'l'
+	}
+	return w.last == 0 || c != implicit || c.Upper() == 'M' || c.Upper() == 'Z'
+}
+
+// cost is the number of bytes segment would write.
+func (w *pathWriter) cost(c CommandType, args []string) int {
+	n := argsLen(args)
+	if w.needsLetter(c) {
+		n++
+	} else if w.lastArg != "" && len(args) > 0 && needsSeparator(w.lastArg, args[0]) {
+		n++
+	}
+	return n
+}
+
+// segment writes a command and its arguments, leaving out the command
+// letter when the grammar repeats the previous one implicitly.
+func (w *pathWriter) segment(c CommandType, args []string) {
+	if w.needsLetter(c) {
+		w.sb.WriteByte(byte(c))
+		w.lastArg = ""
+	}
+	for _, a := range args {
+		if w.lastArg != "" && needsSeparator(w.lastArg, a) {
+			w.sb.WriteByte(' ')
+		}
+		w.sb.WriteString(a)
+		w.lastArg = a
+	}
+	w.last = c
+}
@@ -0,0 +1,392 @@
+package svg
+
+import (
+	"math"
+)
+
+// ─── Curve-Aware Simplification ─────────────────────────────────────────────
+
+// exact is the distance below which two coordinates are considered equal.
+const exact = 1e-9
+
+// Simplify reduces a path without flattening its curves. Every change keeps
+// segment end points in place and moves the drawn outline by at most
+// tolerance user units:
+//
+//   - zero-length segments are dropped;
+//   - runs of collinear lines are merged into one;
+//   - curves that are straight within tolerance become lines, cubics that
+//     match a quadratic or a circular arc become one;
+//   - lines along an axis become H or V, and curves whose first control point
+//     reflects the previous one use the S or T shorthand.
+//
+// The result is in absolute coordinates; FormatPath picks the shorter of
+// absolute and relative notation for each segment.
+func Simplify(p Path, tolerance float64) Path {
+	s := simplifier{tol: max(tolerance, 0)}
+	for _, seg := range normalize(p) {
+		s.add(seg)
+	}
+	s.flushLines()
+	return s.shorthand()
+}
+
+// normalize converts a path to absolute M, L, C, Q, A and Z segments, with
+// the control points of shorthand curves spelled out.
+func normalize(p Path) Path {
+	out := make(Path, 0, len(p))
+	var cur, start, ctrl Point
+	prev := CommandType(0)
+	for _, seg := range p.Absolute() {
+		a := seg.Args
+		switch seg.Command {
+		case 'H':
+			seg = Segment{Command: 'L', Args: []float64{a[0], cur.Y}}
+		case 'V':
+			seg = Segment{Command: 'L', Args: []float64{cur.X, a[0]}}
+		case 'S':
+			c1 := cur
+			if prev == 'C' {
+				c1 = mirror(ctrl, cur)
+			}
+			seg = Segment{Command: 'C', Args: []float64{c1.X, c1.Y, a[0], a[1], a[2], a[3]}}
+		case 'T':
+			c := cur
+			if prev == 'Q' {
+				c = mirror(ctrl, cur)
+			}
+			seg = Segment{Command: 'Q', Args: []float64{c.X, c.Y, a[0], a[1]}}
+		}
+
+		switch seg.Command {
+		case 'C':
+			ctrl = Point{seg.Args[2], seg.Args[3]}
+		case 'Q':
+			ctrl = Point{seg.Args[0], seg.Args[1]}
+		}
+		prev = seg.Command
+		cur = endPoint(seg, cur, start)
+		if seg.Command == 'M' {
+			start = cur
+		}
+		out = append(out, seg)
+	}
+	return out
+}
+
+type simplifier struct {
+	tol float64
+	out Path
+
+	cur, start Point
+	drawn      bool // whether the current subpath has drawn anything yet
+
+	// Pending run of collinear lines: where it starts and the points it
+	// passes through, the last one being the current point.
+	lineFrom Point
+	line     []Point
+}
+
+func (s *simplifier) add(seg Segment) {
+	switch seg.Command {
+	case 'M':
+		s.flushLines()
+		s.emit(seg)
+		s.cur, s.start, s.drawn = pt(seg.Args, 0), pt(seg.Args, 0), false
+		return
+	case 'Z':
+		s.flushLines()
+		// A line back to the start is drawn by the closepath itself.
+		if n := len(s.out); n >= 2 && s.out[n-1].Command == 'L' && s.out[n-2].Command != 'M' &&
+			dist(pt(s.out[n-1].Args, 0), s.start) <= exact {
+			s.out = s.out[:n-1]
+		}
+		s.emit(seg)
+		s.cur = s.start
+		return
+	}
+
+	end := endPoint(seg, s.cur, s.start)
+	if s.zeroLength(seg, end) && s.drawn {
+		return
+	}
+	s.drawn = true
+
+	if seg.Command == 'C' {
+		seg = s.reduceCubic(seg)
+	}
+	if seg.Command == 'Q' && s.straight(s.cur, end, pt(seg.Args, 0)) {
+		seg = Segment{Command: 'L', Args: []float64{end.X, end.Y}}
+	}
+
+	if seg.Command == 'L' {
+		s.addLine(end)
+	} else {
+		s.flushLines()
+		s.emit(seg)
+	}
+	s.cur = end
+}
+
+func (s *simplifier) emit(seg Segment) {
+	s.out = append(s.out, seg)
+}
+
+// zeroLength reports whether a segment draws nothing but a dot.
+func (s *simplifier) zeroLength(seg Segment, end Point) bool {
+	if dist(end, s.cur) > exact {
+		return false
+	}
+	switch seg.Command {
+	case 'C':
+		return dist(pt(seg.Args, 0), s.cur) <= exact && dist(pt(seg.Args, 2), s.cur) <= exact
+	case 'Q':
+		return dist(pt(seg.Args, 0), s.cur) <= exact
+	}
+	return true // lines, and arcs, which the spec omits when they end where they start
+}
+
+// addLine extends the pending run of lines to end, as long as every point
+// the run passes through stays within tolerance of the line replacing it.
+// Otherwise the run is written out and a new one starts.
+func (s *simplifier) addLine(end Point) {
+	if len(s.line) == 0 {
+		s.lineFrom = s.cur
+	}
+	for _, p := range s.line {
+		if distToSegment(p, s.lineFrom, end) > s.tol {
+			s.flushLines()
+			s.lineFrom = s.cur
+			break
+		}
+	}
+	s.line = append(s.line, end)
+}
+
+// flushLines writes the pending run of lines as a single line.
+func (s *simplifier) flushLines() {
+	if len(s.line) == 0 {
+		return
+	}
+	to := s.line[len(s.line)-1]
+	s.line = nil
+	s.emit(Segment{Command: 'L', Args: []float64{to.X, to.Y}})
+}
+
+// reduceCubic replaces a cubic with a line, or with whichever of a
+// quadratic, an arc and the cubic itself is within tolerance and shortest
+// to write.
+func (s *simplifier) reduceCubic(seg Segment) Segment {
+	p0, c1, c2, p3 := s.cur, pt(seg.Args, 0), pt(seg.Args, 2), pt(seg.Args, 4)
+	if s.straight(p0, p3, c1, c2) {
+		return Segment{Command: 'L', Args: []float64{p3.X, p3.Y}}
+	}
+
+	best := seg
+	// A quadratic with control point q elevates to the cubic with control
+	// points p0+2/3(q-p0) and p3+2/3(q-p3). The difference between the two
+	// curves is 3t(1-t)²·d1 + 3t²(1-t)·d2, at most 3/4 of max(|d1|, |d2|).
+	q := Point{
+		X: (3*c1.X - p0.X + 3*c2.X - p3.X) / 4,
+		Y: (3*c1.Y - p0.Y + 3*c2.Y - p3.Y) / 4,
+	}
+	d1 := dist(c1, lerp(p0, q, 2.0/3))
+	d2 := dist(c2, lerp(p3, q, 2.0/3))
+	if quad := (Segment{Command: 'Q', Args: []float64{q.X, q.Y, p3.X, p3.Y}}); 0.75*max(d1, d2) <= s.tol && s.cost(quad) <= s.cost(best) {
+		best = quad
+	}
+	if arc, ok := s.fitArc(p0, c1, c2, p3); ok && s.cost(arc) < s.cost(best) {
+		best = arc
+	}
+	return best
+}
+
+// cost estimates how many bytes a segment takes in relative notation,
+// counting only the numbers a shorthand S or T would keep.
+func (s *simplifier) cost(seg Segment) int {
+	args := relativeArgs(seg, s.cur)
+	if n := len(s.out); n > 0 && len(s.line) == 0 && s.out[n-1].Command == seg.Command {
+		prev := s.out[n-1].Args
+		switch {
+		case seg.Command == 'C' && dist(pt(seg.Args, 0), mirror(pt(prev, 2), s.cur)) <= s.tol:
+			args = args[2:]
+		case seg.Command == 'Q' && dist(pt(seg.Args, 0), mirror(pt(prev, 0), s.cur)) <= s.tol:
+			args = args[2:]
+		}
+	}
+	n := 1
+	for _, v := range args {
+		n += len(formatNumber(SmartRound(v, 3))) + 1
+	}
+	return n
+}
+
+// straight reports whether a curve from a to b with the given control points
+// stays within tolerance of the line ab. A Bézier curve lies in the convex
+// hull of its points, so it is enough for the control points to be close.
+func (s *simplifier) straight(a, b Point, ctrl ...Point) bool {
+	for _, c := range ctrl {
+		if distToSegment(c, a, b) > s.tol {
+			return false
+		}
+	}
+	return true
+}
+
+// arcSamples is how many points along a cubic are checked against a circle.
+const arcSamples = 16
+
+// fitArc finds the circle through the ends and the middle of a cubic and
+// checks that the curve follows it, sweeping monotonically from p0 to p3.
+func (s *simplifier) fitArc(p0, c1, c2, p3 Point) (Segment, bool) {
+	mid := cubicAt(p0, c1, c2, p3, 0.5)
+	center, ok := circumcenter(p0, mid, p3)
+	if !ok {
+		return Segment{}, false
+	}
+	r := dist(center, p0)
+
+	a0 := math.Atan2(p0.Y-center.Y, p0.X-center.X)
+	sweepTo := func(p Point) float64 {
+		return math.Atan2(p.Y-center.Y, p.X-center.X) - a0
+	}
+	// Signed angle swept through mid to p3.
+	toMid := normalizeAngle(sweepTo(mid))
+	total := toMid + normalizeAngle(sweepTo(p3)-toMid)
+	if total == 0 {
+		return Segment{}, false
+	}
+
+	last := 0.0
+	for i := 1; i < arcSamples; i++ {
+		p := cubicAt(p0, c1, c2, p3, float64(i)/arcSamples)
+		if math.Abs(dist(p, center)-r) > s.tol {
+			return Segment{}, false
+		}
+		// Angle progressed from p0, in the direction of the sweep.
+		a := normalizeAngle(sweepTo(p)) * math.Copysign(1, total)
+		if a < 0 {
+			a += 2 * math.Pi
+		}
+		if a < last-exact || a > math.Abs(total)+exact {
+			return Segment{}, false
+		}
+		last = a
+	}
+
+	large, sweep := 0.0, 0.0
+	if math.Abs(total) > math.Pi {
+		large = 1
+	}
+	if total > 0 {
+		sweep = 1
+	}
+	return Segment{Command: 'A', Args: []float64{r, r, 0, large, sweep, p3.X, p3.Y}}, true
+}
+
+// shorthand rewrites axis-aligned lines as H and V, and curves whose first
+// control point reflects the previous curve's last one as S and T. A
+// reflected control point may move by up to the tolerance, which moves the
+// curve by less than that.
+func (s *simplifier) shorthand() Path {
+	out := make(Path, 0, len(s.out))
+	var cur, start, ctrl Point
+	prev := CommandType(0)
+	for _, seg := range s.out {
+		next := seg
+		a := seg.Args
+		switch seg.Command {
+		case 'L':
+			switch {
+			case math.Abs(a[1]-cur.Y) <= exact:
+				next = Segment{Command: 'H', Args: []float64{a[0]}}
+			case math.Abs(a[0]-cur.X) <= exact:
+				next = Segment{Command: 'V', Args: []float64{a[1]}}
+			}
+		case 'C':
+			if prev == 'C' && dist(pt(a, 0), mirror(ctrl, cur)) <= s.tol {
+				next = Segment{Command: 'S', Args: a[2:]}
+			}
+			ctrl = pt(a, 2)
+		case 'Q':
+			if prev == 'Q' && dist(pt(a, 0), mirror(ctrl, cur)) <= s.tol {
+				next = Segment{Command: 'T', Args: a[2:]}
+				ctrl = mirror(ctrl, cur)
+			} else {
+				ctrl = pt(a, 0)
+			}
+		}
+
+		prev = seg.Command
+		cur = endPoint(seg, cur, start)
+		if seg.Command == 'M' {
+			start = cur
+		}
+		out = append(out, next)
+	}
+	return out
+}
+
+// ─── Geometry Helpers ───────────────────────────────────────────────────────
+
+func pt(args []float64, i int) Point {
+	return Point{args[i], args[i+1]}
+}
+
+func dist(a, b Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+func lerp(a, b Point, t float64) Point {
+	return Point{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}
+}
+
+// mirror reflects p through center.
+func mirror(p, center Point) Point {
+	return Point{2*center.X - p.X, 2*center.Y - p.Y}
+}
+
+// distToSegment is the distance from p to the closest point of segment ab.
+func distToSegment(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return dist(p, a)
+	}
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / l2
+	t = max(0, min(1, t))
+	return dist(p, Point{a.X + t*dx, a.Y + t*dy})
+}
+
+func cubicAt(p0, c1, c2, p3 Point, t float64) Point {
+	u := 1 - t
+	b0, b1, b2, b3 := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+	return Point{
+		X: b0*p0.X + b1*c1.X + b2*c2.X + b3*p3.X,
+		Y: b0*p0.Y + b1*c1.Y + b2*c2.Y + b3*p3.Y,
+	}
+}
+
+// circumcenter returns the center of the circle through three points.
+func circumcenter(a, b, c Point) (Point, bool) {
+	d := 2 * (a.X*(b.Y-c.Y) + b.X*(c.Y-a.Y) + c.X*(a.Y-b.Y))
+	if math.Abs(d) < exact {
+		return Point{}, false
+	}
+	a2, b2, c2 := a.X*a.X+a.Y*a.Y, b.X*b.X+b.Y*b.Y, c.X*c.X+c.Y*c.Y
+	return Point{
+		X: (a2*(b.Y-c.Y) + b2*(c.Y-a.Y) + c2*(a.Y-b.Y)) / d,
+		Y: (a2*(c.X-b.X) + b2*(a.X-c.X) + c2*(b.X-a.X)) / d,
+	}, true
+}
+
+// normalizeAngle maps an angle to (-π, π].
+func normalizeAngle(a float64) float64 {
+	for a <= -math.Pi {
+		a += 2 * math.Pi
+	}
+	for a > math.Pi {
+		a -= 2 * math.Pi
+	}
+	return a
+}
@@ -0,0 +1,261 @@
+package svg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"repokit/pkg/core"
+	"sort"
+	"strings"
+
+	"github.com/tdewolff/minify/v2"
+	svg_minifier "github.com/tdewolff/minify/v2/svg"
+)
+
+// ─── Sprite Sheets ──────────────────────────────────────────────────────────
+
+// Symbol is an icon of a sprite sheet, as listed in its manifest.
+type Symbol struct {
+	ID      string `json:"id"`
+	ViewBox string `json:"viewBox,omitempty"`
+	File    string `json:"file"`
+}
+
+// hoistedDefs are the definitions moved out of the symbols into the
+// sprite's own <defs>, where identical ones are kept once.
+var hoistedDefs = map[string]bool{
+	"linearGradient": true, "radialGradient": true, "pattern": true, "clipPath": true,
+	"mask": true, "filter": true, "marker": true,
+}
+
+// BuildSprite optimizes SVG files and combines them into one document of
+// <symbol> elements, with IDs derived from the file names. The IDs inside
+// each file are prefixed with its symbol's ID and an underscore, which file
+// names never slugify to, so they cannot collide; identical gradients and
+// other definitions are kept once. Files with style sheets or scripts are
+// rejected, since their selectors would apply to the whole sprite.
+func BuildSprite(files []string, opts Options) ([]byte, []Symbol, error) {
+	if err := opts.validate(); err != nil {
+		return nil, nil, err
+	}
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+
+	sprite := &Node{Type: ElementNode, Name: "svg", Attrs: []Attr{{Name: "xmlns", Value: "http://www.w3.org/2000/svg"}}}
+	defs := &Node{Type: ElementNode, Name: "defs"}
+	sprite.Children = append(sprite.Children, defs)
+
+	var symbols []Symbol
+	owners := make(map[string]string)
+	shared := make(map[string]string) // definition without its ID → that ID
+	for _, path := range files {
+		id := core.Slugify(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		if id == "" {
+			return nil, nil, fmt.Errorf("%s: no symbol ID can be derived from the file name", path)
+		}
+		if other, taken := owners[id]; taken {
+			return nil, nil, fmt.Errorf("%s and %s would both be symbol %q", other, path, id)
+		}
+		owners[id] = path
+
+		root, err := optimizedRoot(path, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		if hasElement(root, "style", "script") {
+			return nil, nil, fmt.Errorf("%s: style sheets and scripts cannot be combined into a sprite", path)
+		}
+		prefixIDs(root, id+"_")
+		hoistDefs(root, defs, shared)
+
+		symbol := newSymbol(root, id)
+		for _, a := range root.Attrs {
+			if _, declared := sprite.Attr(a.Name); strings.HasPrefix(a.Name, "xmlns:") && !declared {
+				sprite.SetAttr(a.Name, a.Value)
+			}
+		}
+		sprite.Children = append(sprite.Children, symbol)
+		viewBox, _ := symbol.Attr("viewBox")
+		symbols = append(symbols, Symbol{ID: id, ViewBox: viewBox, File: displayPath(path)})
+	}
+	if len(defs.Children) == 0 {
+		sprite.Children = sprite.Children[1:]
+	}
+
+	m := minify.New()
+	m.Add("image/svg+xml", &svg_minifier.Minifier{KeepComments: opts.KeepComments})
+	data, err := m.Bytes("image/svg+xml", sprite.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to minify sprite: %w", err)
+	}
+	return data, symbols, nil
+}
+
+// optimizedRoot reads an SVG file, runs the optimization passes on it and
+// returns its root element.
+func optimizedRoot(path string, opts Options) (*Node, error) {
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ParseDocument(input)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if opts, err = opts.forFile(path); err != nil {
+		return nil, err
+	}
+	runPasses(doc, opts)
+	if !opts.KeepComments {
+		removeComments(doc)
+	}
+	root := rootElement(doc)
+	if root == nil {
+		return nil, fmt.Errorf("%s: no <svg> element", path)
+	}
+	return root, nil
+}
+
+// prefixIDs prefixes every ID below n, and the references to them.
+func prefixIDs(n *Node, prefix string) {
+	renamed := make(map[string]string)
+	n.Walk(func(c *Node) bool {
+		if id, ok := c.Attr("id"); ok {
+			renamed[id] = prefix + id
+			c.SetAttr("id", prefix+id)
+		}
+		return true
+	})
+	renameRefs(n, renamed)
+}
+
+// hoistDefs moves the definitions of a document into the sprite's defs.
+// Definitions identical to one already there, but for their IDs, are dropped
+// and references to them point to the one kept.
+func hoistDefs(root, defs *Node, shared map[string]string) {
+	renamed := make(map[string]string)
+	forEachElement(root, func(n *Node, name string) {
+		if name != "defs" {
+			return
+		}
+		kept := n.Children[:0]
+		for _, c := range n.Children {
+			cname, _ := svgName(c)
+			id, hasID := c.Attr("id")
+			if !hoistedDefs[cname] || !hasID {
+				kept = append(kept, c)
+				continue
+			}
+			key := defKey(c, renamed)
+			if first, dup := shared[key]; dup {
+				renamed[id] = first
+				continue
+			}
+			shared[key] = id
+			defs.Children = append(defs.Children, c)
+		}
+		n.Children = kept
+	})
+	renameRefs(root, renamed)
+
+	prune(root, func(n *Node) bool {
+		name, _ := svgName(n)
+		if name != "defs" {
+			return false
+		}
+		for _, c := range n.Children {
+			if !isBlank(c) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// defKey identifies a definition regardless of the file it comes from: the
+// IDs inside it are numbered in document order, and references to the
+// definitions already merged point to the ones kept.
+func defKey(def *Node, renamed map[string]string) string {
+	c := cloneNode(def)
+	local := make(map[string]string, len(renamed))
+	for id, to := range renamed {
+		local[id] = to
+	}
+	n := 0
+	c.Walk(func(e *Node) bool {
+		if id, ok := e.Attr("id"); ok {
+			local[id] = fmt.Sprintf(":%d", n)
+			e.SetAttr("id", local[id])
+			n++
+		}
+		return true
+	})
+	renameRefs(c, local)
+	return string(c.Bytes())
+}
+
+// cloneNode returns a deep copy of n.
+func cloneNode(n *Node) *Node {
+	c := *n
+	c.Attrs = append([]Attr(nil), n.Attrs...)
+	c.Children = make([]*Node, len(n.Children))
+	for i, child := range n.Children {
+		c.Children[i] = cloneNode(child)
+	}
+	return &c
+}
+
+// newSymbol turns the root of an SVG document into a <symbol>. It keeps the
+// viewBox, derived from a plain width and height if there is none, and the
+// attributes its content inherits.
+func newSymbol(root *Node, id string) *Node {
+	symbol := &Node{Type: ElementNode, Name: "symbol", Attrs: []Attr{{Name: "id", Value: id}}}
+	if viewBox, ok := viewBoxOf(root); ok {
+		symbol.SetAttr("viewBox", viewBox)
+	}
+	for _, a := range root.Attrs {
+		if inheritedAttrs[a.Name] || a.Name == "preserveAspectRatio" || a.Name == "style" {
+			symbol.SetAttr(a.Name, a.Value)
+		}
+	}
+	symbol.Children = root.Children
+	return symbol
+}
+
+// viewBoxOf returns the viewBox of an <svg> element, derived from a plain
+// width and height if it has none.
+func viewBoxOf(root *Node) (string, bool) {
+	if viewBox, ok := root.Attr("viewBox"); ok {
+		return viewBox, true
+	}
+	w, wok := numberAttr(root, "width", 0)
+	h, hok := numberAttr(root, "height", 0)
+	if wok && hok && w > 0 && h > 0 {
+		return "0 0 " + formatNumber(w) + " " + formatNumber(h), true
+	}
+	return "", false
+}
+
+// WriteManifestJSON writes the symbols of a sprite as a JSON array.
+func WriteManifestJSON(w io.Writer, symbols []Symbol) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(symbols)
+}
+
+// WriteManifestTS writes the symbols of a sprite as a TypeScript module:
+// the viewBox of each symbol, and a type of the symbol IDs.
+func WriteManifestTS(w io.Writer, symbols []Symbol) error {
+	var sb strings.Builder
+	sb.WriteString("// Generated by repokit svg sprite. Do not edit.\n\nexport const symbols = {\n")
+	for _, s := range symbols {
+		id, _ := json.Marshal(s.ID)
+		viewBox, _ := json.Marshal(s.ViewBox)
+		fmt.Fprintf(&sb, "  %s: { viewBox: %s },\n", id, viewBox)
+	}
+	sb.WriteString("} as const;\n\nexport type SymbolId = keyof typeof symbols;\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
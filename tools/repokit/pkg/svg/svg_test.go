@@ -1,8 +1,15 @@
 package svg
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"repokit/pkg/core"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestParsePath(t *testing.T) {
@@ -14,12 +21,18 @@ func TestParsePath(t *testing.T) {
 		{"Move and Line", "M 10 10 L 20 20", 2},
 		{"Tight spacing", "M10 10L20 20", 2},
 		{"Scientific notation", "M1.5e2 200", 1},
-		{"Multiple points", "M10 10 20 20 30 30", 1}, // M with implicit L
+		{"Multiple points", "M10 10 20 20 30 30", 3}, // M with implicit L
+		{"Curves", "M0 0C1 2 3 4 5 6S7 8 9 10Q1 1 2 2T3 3", 5},
+		{"Compact arc flags", "M0 0a1 1 0 0110 10", 2},
+		{"Subpaths", "M0 0h10v10zm20 0h5z", 7},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cmds := ParsePath(tt.input)
+			cmds, err := ParsePath(tt.input)
+			if err != nil {
+				t.Fatalf("ParsePath(%q) error: %v", tt.input, err)
+			}
 			if len(cmds) != tt.expected {
 				t.Errorf("expected %d commands, got %d", tt.expected, len(cmds))
 			}
@@ -27,6 +40,74 @@ func TestParsePath(t *testing.T) {
 	}
 }
 
+func TestParsePath_Grammar(t *testing.T) {
+	path, err := ParsePath("m1-2.5.5 3l4,5 6 7a25,26 -30 1,0 50-25h1e1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Path{
+		{'m', []float64{1, -2.5}},
+		{'l', []float64{.5, 3}}, // the second pair of a moveto is a lineto
+		{'l', []float64{4, 5}},
+		{'l', []float64{6, 7}},
+		{'a', []float64{25, 26, -30, 1, 0, 50, -25}},
+		{'h', []float64{10}},
+	}
+	if !reflect.DeepEqual(path, want) {
+		t.Errorf("ParsePath() = %v, want %v", path, want)
+	}
+
+	for _, bad := range []string{"L0 0", "M0", "M0 0 L", "M0 0 A1 1 0 2 0 5 5", "M0 0 X1"} {
+		if _, err := ParsePath(bad); err == nil {
+			t.Errorf("ParsePath(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestPathRoundTrip(t *testing.T) {
+	inputs := []string{
+		"M0 0C1 2 3 4 5 6S7 8 9 10Q1 1 2 2T3 3Z",
+		"m10 10h5v-5a2.5 2.5 0 0 1-5 0zM20 20l1 1 2 2",
+		"M.5.5L-1e-7 3m1 1 2 2zz",
+	}
+	for _, in := range inputs {
+		path, err := ParsePath(in)
+		if err != nil {
+			t.Fatalf("ParsePath(%q) error: %v", in, err)
+		}
+		again, err := ParsePath(path.String())
+		if err != nil {
+			t.Fatalf("ParsePath(%q) error: %v", path.String(), err)
+		}
+		if !reflect.DeepEqual(path, again) {
+			t.Errorf("%q: round trip through %q gave %v", in, path.String(), again)
+		}
+	}
+}
+
+func TestFormatPath(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		// Implicit linetos and repeated commands drop their letters.
+		{"M 0 0 L 10 10 L 20 0.5", "M0 0 10 10 20 .5"},
+		// Relative segments are quantized in absolute space, so rounding
+		// does not drift along the path.
+		{"m0 0 l0.333 0.333 l0.333 0.333 l0.333 0.333", "M0 0l.33.33.34.34L1 1"},
+		// Curves, arcs and closepath survive serialization.
+		{"M10 10 C 20 20, 40 20, 50 10 A 5 5 0 0 1 60 10 Z", "M10 10c10 10 30 10 40 0a5 5 0 0 1 10 0Z"},
+	}
+	for _, tt := range tests {
+		path, err := ParsePath(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FormatPath(path, 2); got != tt.want {
+			t.Errorf("FormatPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
 func TestDistanceToLine(t *testing.T) {
 	p := Point{0, 5}
 	a := Point{0, 0}
@@ -89,34 +170,893 @@ func TestFormatPathData(t *testing.T) {
 	}
 }
 
-func TestToAbsolutePoints(t *testing.T) {
-	cmds := []Command{
-		{Type: 'M', Points: []Point{{10, 10}}},
-		{Type: 'L', Points: []Point{{20, 20}}},
-		{Type: 'H', Points: []Point{{30, 0}}}, // H only uses X, but tokenizer stores it in Point{X, 0}
-		{Type: 'V', Points: []Point{{0, 40}}}, // V only uses Y, but tokenizer stores it in Point{0, Y} (actually logic in tokenizer.go:45)
-		{Type: 'm', Points: []Point{{10, 10}}}, // Relative Move
-		{Type: 'l', Points: []Point{{5, 5}}},   // Relative Line
+func TestPathAbsolute(t *testing.T) {
+	path, err := ParsePath("M10 10 L20 20 h10 v10 m10 10 l5 5 c1 1 2 2 3 3 a1 1 0 0 1 2 2 z l1 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Path{
+		{'M', []float64{10, 10}},
+		{'L', []float64{20, 20}},
+		{'H', []float64{30}},
+		{'V', []float64{30}},
+		{'M', []float64{40, 40}},
+		{'L', []float64{45, 45}},
+		{'C', []float64{46, 46, 47, 47, 48, 48}},
+		{'A', []float64{1, 1, 0, 0, 1, 50, 50}},
+		{'Z', []float64{}},
+		{'L', []float64{41, 41}}, // relative to the start of the closed subpath
+	}
+	got := path.Absolute()
+	for i := range got {
+		if got[i].Args == nil {
+			got[i].Args = []float64{}
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Absolute() = %v, want %v", got, want)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"Collinear lines merge", "M0 0L5 0L10 0L10 4L10 10", "M0 0H10V10"},
+		{"Zero-length segments drop", "M0 0L10 0L10 0C10 0 10 0 10 0L10 10", "M0 0H10V10"},
+		{"A lone dot is kept", "M5 5L5 5", "M5 5H5"},
+		{"Closing line is left to Z", "M0 0H10V10H0V0Z", "M0 0H10V10H0Z"},
+		{"Straight cubic becomes a line", "M0 0C3 .001 6-.001 10 0", "M0 0H10"},
+		{"Elevated quadratic", "M0 0C3.333333 6.666667 6.666667 6.666667 10 0", "M0 0Q5 10 10 0"},
+		{"Reflected quadratic", "M0 0Q5 10 10 0Q15-10 20 0", "M0 0Q5 10 10 0T20 0"},
+		{"Reflected cubic", "M0 0C0 20 10 20 10 0C10-20 30-20 30 0", "M0 0C0 20 10 20 10 0S30-20 30 0"},
+		{"Quarter circle", "M10 0C10 5.522847 5.522847 10 0 10", "M10 0A10 10 0 0 1 0 10"},
+		{"Curves outside tolerance stay", "M0 0C0 20 10 20 10 0", "M0 0C0 20 10 20 10 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := ParsePath(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			simplified := Simplify(path, 0.01)
+			if got := FormatPath(simplified, 3); got != tt.want {
+				t.Errorf("Simplify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimplify_BoundedError(t *testing.T) {
+	// A polyline that wobbles by 0.05 is only merged when the tolerance
+	// allows it.
+	path, _ := ParsePath("M0 0L5 .05L10 0")
+	if got := len(Simplify(path, 0.01)); got != 3 {
+		t.Errorf("expected the wobble to be kept at tolerance 0.01, got %d segments", got)
+	}
+	if got := len(Simplify(path, 0.1)); got != 2 {
+		t.Errorf("expected the wobble to be merged at tolerance 0.1, got %d segments", got)
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Matrix
+	}{
+		{"translate(10)", Matrix{1, 0, 0, 1, 10, 0}},
+		{"translate(10, 20) scale(2)", Matrix{2, 0, 0, 2, 10, 20}},
+		{"scale(2 3)", Matrix{2, 0, 0, 3, 0, 0}},
+		{"rotate(90)", Matrix{0, 1, -1, 0, 0, 0}},
+		{"rotate(180 5 5)", Matrix{-1, 0, 0, -1, 10, 10}},
+		{"skewX(45)", Matrix{1, 0, 1, 1, 0, 0}},
+		{"matrix(1,2,3,4,5,6)", Matrix{1, 2, 3, 4, 5, 6}},
 	}
-	points := ToAbsolutePoints(cmds)
-	if len(points) == 0 {
-		t.Fatal("expected points, got 0")
+	for _, tt := range tests {
+		got, err := ParseTransform(tt.in)
+		if err != nil {
+			t.Errorf("ParseTransform(%q): %v", tt.in, err)
+			continue
+		}
+		for i, v := range []float64{got.A - tt.want.A, got.B - tt.want.B, got.C - tt.want.C, got.D - tt.want.D, got.E - tt.want.E, got.F - tt.want.F} {
+			if v > 1e-9 || v < -1e-9 {
+				t.Errorf("ParseTransform(%q) = %v, want %v (entry %d)", tt.in, got, tt.want, i)
+				break
+			}
+		}
 	}
 
-	// Verify some coordinates
-	if points[0].X != 10 || points[0].Y != 10 {
-		t.Errorf("Point 0 wrong: %v", points[0])
+	for _, in := range []string{"translate(1 2 3)", "spin(4)", "scale(2", "rotate(a)"} {
+		if _, err := ParseTransform(in); err == nil {
+			t.Errorf("ParseTransform(%q) should fail", in)
+		}
+	}
+}
+
+func TestPathTransform(t *testing.T) {
+	tests := []struct {
+		name, path, transform, want string
+	}{
+		{"Translate", "M0 0h10v10", "translate(5 5)", "M5 5 15 5l0 10"},
+		{"Scale curves", "M0 0Q5 10 10 0", "scale(2 1)", "M0 0Q10 10 20 0"},
+		{"Circular arc under uniform scale", "M0 0A5 5 0 0 1 10 0", "scale(2)", "M0 0A10 10 0 0 1 20 0"},
+		{"Arc squashed into an ellipse", "M0 0A5 5 0 0 1 10 0", "scale(1 2)", "M0 0A10 5 90 0 1 10 0"},
+		{"Mirroring reverses the sweep", "M0 0A5 5 0 0 1 10 0", "scale(-1 1)", "M0 0A5 5 0 0 0-10 0"},
+		{"Rotated ellipse", "M0 0A10 5 0 0 1 20 0", "rotate(90)", "M0 0A10 5 90 0 1 0 20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := ParsePath(tt.path)
+			m, err := ParseTransform(tt.transform)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := FormatPath(p.Transform(m), 3); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathBounds(t *testing.T) {
+	tests := []struct {
+		path   string
+		lo, hi Point
+	}{
+		{"M0 0H10V5", Point{0, 0}, Point{10, 5}},
+		// The control point lies outside; the curve peaks halfway.
+		{"M0 0Q5 10 10 0", Point{0, 0}, Point{10, 5}},
+		{"M0 0C0 10 10 10 10 0", Point{0, 0}, Point{10, 7.5}},
+		// A half circle below its chord, and the same arc's other half.
+		{"M0 0A5 5 0 0 0 10 0", Point{0, 0}, Point{10, 5}},
+		{"M0 0A5 5 0 0 1 10 0", Point{0, -5}, Point{10, 0}},
+		{"M0 0A5 5 0 1 1 5 5", Point{0, -5}, Point{10, 5}},
+	}
+	for _, tt := range tests {
+		p, _ := ParsePath(tt.path)
+		lo, hi := p.Bounds()
+		near := func(a, b Point) bool { return dist(a, b) < 1e-9 }
+		if !near(lo, tt.lo) || !near(hi, tt.hi) {
+			t.Errorf("Bounds(%q) = %v, %v, want %v, %v", tt.path, lo, hi, tt.lo, tt.hi)
+		}
 	}
 }
 
 func TestOptimize(t *testing.T) {
 	// Test Optimize with empty or non-existent files
-	err := Optimize("non-existent-*.svg")
+	err := Optimize("non-existent-*.svg", DefaultOptions())
 	if err != nil {
 		t.Errorf("Optimize with no matches should not return error, got %v", err)
 	}
 }
 
+const testIcon = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"><path fill="#000" d="%s"/></svg>`
+
+func TestVisualDiff(t *testing.T) {
+	square := []byte(fmt.Sprintf(testIcon, "M4 4H20V20H4Z"))
+	img, err := Rasterize(square, 24)
+	if err != nil {
+		t.Fatalf("Rasterize: %v", err)
+	}
+	if _, _, _, a := img.At(12, 12).RGBA(); a == 0 {
+		t.Errorf("expected the center of the square to be painted")
+	}
+	if _, _, _, a := img.At(1, 1).RGBA(); a != 0 {
+		t.Errorf("expected the corner outside the square to be empty")
+	}
+
+	same := []byte(fmt.Sprintf(testIcon, "M4 4h16v16H4z"))
+	if diff, err := VisualDiff(square, same); err != nil || diff != 0 {
+		t.Errorf("equivalent paths: got diff %v, err %v", diff, err)
+	}
+
+	smaller := []byte(fmt.Sprintf(testIcon, "M4 4H12V20H4Z"))
+	if diff, err := VisualDiff(square, smaller); err != nil || diff < 0.2 {
+		t.Errorf("halved square: got diff %v, err %v", diff, err)
+	}
+}
+
+func TestPixelDiff_ThinLines(t *testing.T) {
+	line := func(x string) []byte {
+		return []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"><path d="M` + x + ` 2V22" stroke="#000" stroke-width="1"/></svg>`)
+	}
+	// Moving a thin line changes a small share of the canvas but most of
+	// the ink.
+	if diff, err := VisualDiff(line("6"), line("18")); err != nil || diff < 0.5 {
+		t.Errorf("moved line: got diff %v, err %v", diff, err)
+	}
+	if diff, err := VisualDiff(line("6"), line("6")); err != nil || diff != 0 {
+		t.Errorf("same line: got diff %v, err %v", diff, err)
+	}
+}
+
+func TestVisualDiff_Unrenderable(t *testing.T) {
+	clipped := []byte(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:dc="http://purl.org/dc/elements/1.1/" viewBox="0 0 24 24"><metadata><dc:title>x</dc:title></metadata>` +
+		`<clipPath id="c"><circle cx="12" cy="12" r="4"/></clipPath><text>A</text><path d="M4 4H20V20H4Z" clip-path="url(#c)"/></svg>`)
+	_, err := VisualDiff(clipped, clipped)
+	if err == nil || !strings.Contains(err.Error(), "<clipPath>, <text>") {
+		t.Errorf("expected the skipped elements to be reported, got %v", err)
+	}
+}
+
+func TestOptimize_Verify(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "icon.svg")
+	original := fmt.Sprintf(testIcon, "M4 4L12 4.4L20 4L20 20L4 20Z")
+	if err := os.WriteFile(file, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tolerance this large flattens the dent, which shows at 64px.
+	opts := Options{Tolerance: 2, Verify: true, MaxDiff: 0}
+	o := newOptimizer([]string{file}, opts)
+	r, err := o.processFile(file)
+	if err != nil {
+		t.Fatalf("processFile: %v", err)
+	}
+	if !r.Rejected || r.Diff == 0 {
+		t.Errorf("expected the optimization to be rejected, got %+v", r)
+	}
+	if got, _ := os.ReadFile(file); string(got) != original {
+		t.Errorf("expected the original to be kept, got %s", got)
+	}
+
+	opts.MaxDiff = 1
+	r, err = newOptimizer([]string{file}, opts).processFile(file)
+	if err != nil || r.Rejected {
+		t.Fatalf("expected the optimization to be accepted, got %+v, %v", r, err)
+	}
+	if got, _ := os.ReadFile(file); string(got) == original {
+		t.Errorf("expected the file to be rewritten")
+	}
+}
+
+func TestPrettyPrint(t *testing.T) {
+	got := prettyPrint([]byte(`<svg viewBox="0 0 24 24"><g fill="red"><path d="M0 0L1 1"/></g></svg>`))
+	want := `<svg
+    viewBox="0 0 24 24"
+>
+  <g
+      fill="red"
+  >
+    <path
+        d="M0 0L1 1"
+    />
+  </g>
+</svg>
+`
+	if got != want {
+		t.Errorf("prettyPrint:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	want := `--- a/x.svg
++++ b/x.svg
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k
+`
+	if got := unifiedDiff("x.svg", before, after); got != want {
+		t.Errorf("unifiedDiff:\n%s\nwant:\n%s", got, want)
+	}
+	if got := unifiedDiff("x.svg", before, before); got != "" {
+		t.Errorf("expected no diff for equal texts, got %q", got)
+	}
+}
+
+func TestOptimize_NoWrite(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	original := fmt.Sprintf(testIcon, "M4 4L12 4L20 4L20 20L4 20Z")
+	if err := os.WriteFile("icon.svg", []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "icon.svg")
+
+	opts := DefaultOptions()
+	opts.Check, opts.Diff = true, true
+	o := newOptimizer([]string{file}, opts)
+	r, err := o.processFile(file)
+	if err != nil {
+		t.Fatalf("processFile: %v", err)
+	}
+	if !r.Changed || !strings.HasPrefix(r.Patch, "--- a/icon.svg\n") {
+		t.Errorf("expected a change with a patch, got %+v", r)
+	}
+	if got, _ := os.ReadFile(file); string(got) != original {
+		t.Errorf("check mode rewrote the file: %s", got)
+	}
+	o.results[0] = r
+	if err := o.report(); err == nil {
+		t.Errorf("expected check mode to fail when a file would change")
+	}
+
+	opts = DefaultOptions()
+	opts.OutDir = "out"
+	if _, err := newOptimizer([]string{file}, opts).processFile(file); err != nil {
+		t.Fatalf("processFile: %v", err)
+	}
+	if got, _ := os.ReadFile(file); string(got) != original {
+		t.Errorf("out-dir mode rewrote the file: %s", got)
+	}
+	copied, err := os.ReadFile(filepath.Join("out", "icon.svg"))
+	if err != nil || string(copied) == original {
+		t.Errorf("expected an optimized copy in out/, got %q, %v", copied, err)
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	src := `<?xml version="1.0"?><svg xmlns='http://www.w3.org/2000/svg' xmlns:xlink="http://www.w3.org/1999/xlink"><!-- note --><use xlink:href="#a"/><style>a>b{fill:red}</style></svg>`
+	doc, err := ParseDocument([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	want := `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><!-- note --><use xlink:href="#a"/><style>a>b{fill:red}</style></svg>`
+	if got := string(doc.Bytes()); got != want {
+		t.Errorf("round trip:\n got %s\nwant %s", got, want)
+	}
+
+	for _, bad := range []string{`<svg><g></svg>`, `<svg>`, `<svg a="1" a="2"`} {
+		if _, err := ParseDocument([]byte(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestRunPasses_Geometry(t *testing.T) {
+	src := `<svg xmlns="http://www.w3.org/2000/svg">` +
+		`<path id="d" d='M0 0 L5 0 L10 0'/>` +
+		`<polygon points="0,0 5,0 10,0 10,10"/><polyline points="1 2 3"/>` +
+		`<rect x="0.123456" width="50%" height="4"/>` +
+		`<metadata><path d="M0 0 L5 0 L10 0"/></metadata></svg>`
+	doc, err := ParseDocument([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultOptions()
+	opts.Passes = []string{"simplify_paths", "round_numbers"}
+	results, before, after := runPasses(doc, opts)
+	if len(results) != 2 || results[0].Name != "simplify_paths" || results[0].Saved <= 0 {
+		t.Errorf("unexpected pass results %+v", results)
+	}
+	if before != 7 || after != 5 {
+		t.Errorf("nodes: got %d -> %d, want 7 -> 5", before, after)
+	}
+
+	want := `<svg xmlns="http://www.w3.org/2000/svg">` +
+		`<path id="d" d="M0 0H10"/>` +
+		`<polygon points="0,0 10,0 10,10"/><polyline points="1 2 3"/>` +
+		`<rect x=".12" width="50%" height="4"/>` +
+		`<metadata><path d="M0 0 L5 0 L10 0"/></metadata></svg>`
+	if got := string(doc.Bytes()); got != want {
+		t.Errorf("optimizeGeometry:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestRunPasses_StyleSheet(t *testing.T) {
+	// The rules select by element name and structure, so the shapes and the
+	// group must survive every pass for them to keep matching.
+	src := `<svg xmlns="http://www.w3.org/2000/svg"><style>rect{fill:red} g>circle{fill:blue}</style>` +
+		`<rect x="1" y="1" width="4" height="4"/><g><circle cx="8" cy="8" r="2"/></g></svg>`
+	doc, err := ParseDocument([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runPasses(doc, DefaultOptions())
+	got := string(doc.Bytes())
+	for _, want := range []string{`<rect `, `<g><circle `} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s to be kept for the style sheet, got %s", want, got)
+		}
+	}
+}
+
+func TestPasses(t *testing.T) {
+	const ns = `xmlns="http://www.w3.org/2000/svg"`
+	tests := []struct {
+		pass, in, want string
+	}{
+		{"remove_metadata",
+			`<svg ` + ns + ` xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape" xmlns:sodipodi="http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd" xmlns:dc="http://purl.org/dc/elements/1.1/"><sodipodi:namedview/><metadata><dc:title/></metadata><path inkscape:label="a" d="M0 0"/></svg>`,
+			`<svg ` + ns + `><path d="M0 0"/></svg>`},
+		{"remove_hidden",
+			`<svg ` + ns + `><g/><rect width="0" height="5"/><path d="M0 0" display="none"/><path id="r" d="M1 1" opacity="0"/><use href="#r"/><defs><path d=""/></defs><circle r="2"/></svg>`,
+			`<svg ` + ns + `><path id="r" d="M1 1" opacity="0"/><use href="#r"/><defs><path d=""/></defs><circle r="2"/></svg>`},
+		{"collapse_groups",
+			`<svg ` + ns + `><g><g fill="red" transform="scale(2)"><path d="M0 0" transform="translate(1)"/></g></g><g opacity=".5"><path/><path/></g><g fill="red"><title>t</title><path/></g></svg>`,
+			`<svg ` + ns + `><path d="M0 0" transform="scale(2) translate(1)" fill="red"/><g opacity=".5"><path/><path/></g><g fill="red"><title>t</title><path/></g></svg>`},
+		{"convert_shapes",
+			`<svg ` + ns + `><rect x="1" y="2" width="3" height="4" fill="red"/><rect width="3" height="4" rx="1"/><line x2="5" y2="5"/><polygon points="0,0 1,0 1,1"/><rect width="50%" height="4"/><circle r="1"/></svg>`,
+			`<svg ` + ns + `><path fill="red" d="M1 2H4V6H1z"/><rect width="3" height="4" rx="1"/><path d="M0 0 5 5"/><path d="M0 0 1 0 1 1z"/><rect width="50%" height="4"/><circle r="1"/></svg>`},
+		{"flatten_transforms",
+			`<svg ` + ns + `><g transform="translate(10)"><path d="M0 0H2V2z"/><path d="M0 0h1" stroke="red" transform="scale(2)"/></g><path d="M0 0h1" stroke="red" transform="scale(2 1)"/><circle r="1" transform="scale(3)"/><g transform="rotate(90)" clip-path="url(#c)"><path d="M0 0h1"/></g></svg>`,
+			`<svg ` + ns + `><g><path d="M10 0 12 0 12 2Z"/><path d="M10 0 12 0" stroke="red" stroke-width="2"/></g><path d="M0 0h1" stroke="red" transform="scale(2 1)"/><circle r="3"/><g transform="rotate(90)" clip-path="url(#c)"><path d="M0 0h1"/></g></svg>`},
+		{"merge_paths",
+			`<svg ` + ns + `><path fill="red" d="M0 0H1V1z"/> <path fill="red" d="m5 5h1v1z"/><path fill="red" d="M5.5 5.5h1v1z"/><path fill="blue" d="M9 9h1"/></svg>`,
+			`<svg ` + ns + `><path fill="red" d="M0 0H1V1zM5 5h1v1z"/> <path fill="red" d="M5.5 5.5h1v1z"/><path fill="blue" d="M9 9h1"/></svg>`},
+		{"minify_ids",
+			`<svg ` + ns + ` id="root"><defs><linearGradient id="gradient"/><clipPath id="unused"/></defs><path id="shape" fill="url(#gradient)"/><use href="#shape"/><use href="#root"/></svg>`,
+			`<svg ` + ns + ` id="root"><defs><linearGradient id="a"/><clipPath/></defs><path id="b" fill="url(#a)"/><use href="#b"/><use href="#root"/></svg>`},
+		{"sort_attrs",
+			`<svg viewBox="0 0 1 1" ` + ns + `><path stroke="red" d="M0 0" fill="none" id="x" aria-hidden="true"/></svg>`,
+			`<svg ` + ns + ` viewBox="0 0 1 1"><path id="x" fill="none" stroke="red" d="M0 0" aria-hidden="true"/></svg>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pass, func(t *testing.T) {
+			doc, err := ParseDocument([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := DefaultOptions()
+			opts.Passes = []string{tt.pass}
+			results, _, _ := runPasses(doc, opts)
+			if got := string(doc.Bytes()); got != tt.want {
+				t.Errorf("\n got %s\nwant %s", got, tt.want)
+			}
+			if saved := int64(len(tt.in) - len(doc.Bytes())); results[0].Saved != saved {
+				t.Errorf("reported %d bytes saved, want %d", results[0].Saved, saved)
+			}
+		})
+	}
+}
+
+func TestNormalizeViewBox(t *testing.T) {
+	const ns = `xmlns="http://www.w3.org/2000/svg"`
+	tests := []struct {
+		name, in, want string
+	}{
+		{"Content is scaled and centered",
+			`<svg ` + ns + ` viewBox="0 0 100 100"><title>t</title><path d="M10 10H50V30H10z"/><g transform="translate(40 40)"><circle cx="10" cy="10" r="9" fill="none" stroke="red" stroke-width="2"/></g></svg>`,
+			`<svg ` + ns + ` viewBox="0 0 24 24"><title>t</title><path d="M1 1H18.6V9.8H1Z"/><circle cx="18.6" cy="18.6" r="3.96" fill="none" stroke="red" stroke-width=".88"/></svg>`},
+		{"Wide content is centered vertically",
+			`<svg ` + ns + `><path d="M0 0H44V22H0z"/></svg>`,
+			`<svg ` + ns + ` viewBox="0 0 24 24"><path d="M1 6.5H23v11H1Z"/></svg>`},
+		{"Text cannot be measured",
+			`<svg ` + ns + ` viewBox="0 0 100 100"><text>a</text></svg>`,
+			`<svg ` + ns + ` viewBox="0 0 100 100"><text>a</text></svg>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := DefaultOptions()
+			opts.ViewBox, opts.Padding = "0 0 24 24", 1
+			opts.Passes = []string{"normalize_viewbox", "flatten_transforms", "collapse_groups", "simplify_paths", "round_numbers"}
+			runPasses(doc, opts)
+			if got := string(doc.Bytes()); got != tt.want {
+				t.Errorf("\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptions_ForFile(t *testing.T) {
+	precision, tolerance := 1, 0.5
+	opts := DefaultOptions().WithConfig(core.SVGConfig{
+		Passes: []string{"simplify_paths"},
+		Overrides: []core.SVGOverride{
+			{Files: "icons/**/*.svg", Precision: &precision},
+			{Files: "icons/large/*.svg", Tolerance: &tolerance, Passes: []string{"sort_attrs"}},
+		},
+	})
+	if err := opts.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	small, err := opts.forFile("icons/small/a.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if small.Precision != 1 || small.Tolerance != DefaultTolerance || small.Passes[0] != "simplify_paths" {
+		t.Errorf("icons/small: got %+v", small)
+	}
+	large, _ := opts.forFile("icons/large/a.svg")
+	if large.Precision != 1 || large.Tolerance != 0.5 || large.Passes[0] != "sort_attrs" {
+		t.Errorf("icons/large: got %+v", large)
+	}
+	other, _ := opts.forFile("logo.svg")
+	if other.Precision != DefaultPrecision {
+		t.Errorf("logo.svg: got precision %d", other.Precision)
+	}
+
+	var config core.SVGConfig
+	if err := yaml.Unmarshal([]byte("overrides:\n  - files: raw/*.svg\n    passes: []\n"), &config); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := DefaultOptions().WithConfig(config).forFile("raw/a.svg")
+	if raw.Passes == nil || len(raw.Passes) != 0 {
+		t.Fatalf("raw: expected an explicit empty pass list, got %#v", raw.Passes)
+	}
+	doc, _ := ParseDocument([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect width="4" height="4"/></svg>`))
+	if results, _, _ := runPasses(doc, raw); len(results) != 0 || !strings.Contains(string(doc.Bytes()), "<rect") {
+		t.Errorf("raw: expected passes: [] to run no passes, ran %+v", results)
+	}
+
+	opts.Overrides = append(opts.Overrides, core.SVGOverride{Files: "*.svg", Passes: []string{"nope"}})
+	if err := opts.validate(); err == nil || !strings.Contains(err.Error(), `unknown svg pass "nope"`) {
+		t.Errorf("expected an unknown pass error, got %v", err)
+	}
+}
+
+func TestOptimize_KeepMetadata(t *testing.T) {
+	src := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:dc="http://purl.org/dc/elements/1.1/" viewBox="0 0 24 24">` +
+		`<!-- drawn by hand --><metadata><dc:title>Icon</dc:title></metadata><path d="M4 4H20V20H4Z"/></svg>`
+	file := filepath.Join(t.TempDir(), "icon.svg")
+
+	for _, tt := range []struct {
+		opts     Options
+		metadata bool
+		comment  bool
+	}{
+		{Options{Tolerance: DefaultTolerance}, false, false},
+		{Options{Tolerance: DefaultTolerance, KeepMetadata: true}, true, false},
+		{Options{Tolerance: DefaultTolerance, KeepComments: true}, false, true},
+	} {
+		if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := newOptimizer([]string{file}, tt.opts).processFile(file); err != nil {
+			t.Fatalf("processFile: %v", err)
+		}
+		got, _ := os.ReadFile(file)
+		metadata := `<metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Icon</dc:title></metadata>`
+		if strings.Contains(string(got), metadata) != tt.metadata {
+			t.Errorf("%+v: metadata kept = %v in %s", tt.opts, !tt.metadata, got)
+		}
+		if strings.Contains(string(got), "drawn by hand") != tt.comment {
+			t.Errorf("%+v: comment kept = %v in %s", tt.opts, !tt.comment, got)
+		}
+		if _, err := ParseDocument(got); err != nil {
+			t.Errorf("%+v: output is not well-formed: %v", tt.opts, err)
+		}
+	}
+}
+
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestBuildSprite(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	const gradient = `<linearGradient id="%s"><stop offset="0" stop-color="red"/><stop offset="1" stop-color="blue"/></linearGradient>`
+	arrow := write("Arrow Left.svg", `<svg xmlns="http://www.w3.org/2000/svg" width="24" height="24" fill="none"><defs>`+fmt.Sprintf(gradient, "g1")+`</defs><path d="M20 12H4" stroke="url(#g1)"/></svg>`)
+	home := write("home.svg", `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"><defs>`+fmt.Sprintf(gradient, "grad")+`<clipPath id="c"><path d="M0 0H8V8H0z"/></clipPath></defs><path d="M1 8 8 1l7 7v7H1z" fill="url(#grad)" clip-path="url(#c)"/></svg>`)
+
+	data, symbols, err := BuildSprite([]string{home, arrow}, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sprite := string(data)
+	if n := strings.Count(sprite, "<linearGradient"); n != 1 {
+		t.Errorf("expected the identical gradients to be kept once, got %d in %s", n, sprite)
+	}
+	for _, want := range []string{
+		`<symbol id="arrow-left" viewBox="0 0 24 24" fill="none">`,
+		`<symbol id="home" viewBox="0 0 16 16">`,
+		`fill="url(#arrow-left_a)"`,
+		`clip-path="url(#home_b)"`,
+		`<clipPath id="home_b">`,
+	} {
+		if !strings.Contains(sprite, want) {
+			t.Errorf("sprite lacks %s:\n%s", want, sprite)
+		}
+	}
+	if len(symbols) != 2 || symbols[0].ID != "arrow-left" || symbols[1].ViewBox != "0 0 16 16" {
+		t.Errorf("unexpected symbols %+v", symbols)
+	}
+
+	var ts strings.Builder
+	if err := WriteManifestTS(&ts, symbols); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ts.String(), `"arrow-left": { viewBox: "0 0 24 24" },`) {
+		t.Errorf("unexpected manifest:\n%s", ts.String())
+	}
+
+	clash := write("arrow_left.svg", `<svg xmlns="http://www.w3.org/2000/svg"/>`)
+	if _, _, err := BuildSprite([]string{arrow, clash}, DefaultOptions()); err == nil {
+		t.Error("expected files with the same symbol ID to fail")
+	}
+
+	styled := write("styled.svg", `<svg xmlns="http://www.w3.org/2000/svg"><style>#a{fill:red}</style><path id="a" d="M0 0H8V8H0z"/></svg>`)
+	if _, _, err := BuildSprite([]string{styled}, DefaultOptions()); err == nil {
+		t.Error("expected a file with a style sheet to fail")
+	}
+}
+
+func TestBuildSprite_NestedDefs(t *testing.T) {
+	dir := t.TempDir()
+	// A gradient inheriting the stops of another, and a pattern referring to
+	// an element inside it: every ID involved differs between the files.
+	const icon = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"><defs>` +
+		`<linearGradient id="%[1]s-stops"><stop offset="0" stop-color="red"/><stop offset="1" stop-color="blue"/></linearGradient>` +
+		`<linearGradient id="%[1]s-fill" href="#%[1]s-stops" x2="0" y2="1"/>` +
+		`<pattern id="%[1]s-dots" width="4" height="4" patternUnits="userSpaceOnUse"><circle id="%[1]s-dot" cx="2" cy="2" r="1"/><use href="#%[1]s-dot" x="1"/></pattern>` +
+		`</defs><path d="M0 0H16V8H0z" fill="url(#%[1]s-fill)"/><path d="M0 8H16V16H0z" fill="url(#%[1]s-dots)"/></svg>`
+	var files []string
+	for _, name := range []string{"one", "two"} {
+		path := filepath.Join(dir, name+".svg")
+		if err := os.WriteFile(path, []byte(fmt.Sprintf(icon, name)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+
+	data, _, err := BuildSprite(files, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sprite := string(data)
+	if n := strings.Count(sprite, "<linearGradient"); n != 2 {
+		t.Errorf("expected both gradients to be kept once, got %d in %s", n, sprite)
+	}
+	if n := strings.Count(sprite, "<pattern"); n != 1 {
+		t.Errorf("expected the pattern to be kept once, got %d in %s", n, sprite)
+	}
+	if strings.Contains(sprite, "two_") {
+		t.Errorf("expected the second icon to use the first icon's definitions:\n%s", sprite)
+	}
+}
+
+func TestGenerateComponents(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	files := []string{
+		write("check.svg", `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"><title>Check</title><path d="M4 12l5 5L20 6" fill="none" stroke="#000" stroke-width="2"/><circle cx="12" cy="12" r="2" fill="black"/></svg>`),
+		write("flag.svg", `<svg xmlns="http://www.w3.org/2000/svg" width="16" height="16"><path d="M0 0H8V16H0z" fill="red"/><path d="M8 0H16V16H8z" fill="blue"/></svg>`),
+	}
+
+	react, err := GenerateComponents(files, "react", DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(react) != 3 || react[0].Name != "check.tsx" || react[2].Name != "index.ts" {
+		t.Fatalf("unexpected files %v", react)
+	}
+	check, flag := react[0].Content, react[1].Content
+	for _, want := range []string{
+		"export const CheckIcon = ({ size = 24, title, ...props }: CheckIconProps) => (",
+		`stroke="currentColor"`,
+		`strokeWidth="2"`,
+		`<circle cx="12" cy="12" r="2" fill="currentColor" />`,
+	} {
+		if !strings.Contains(check, want) {
+			t.Errorf("check.tsx lacks %s:\n%s", want, check)
+		}
+	}
+	if strings.Contains(check, "<title>Check</title>") {
+		t.Errorf("the title prop should replace the icon's title:\n%s", check)
+	}
+	if !strings.Contains(flag, `viewBox="0 0 16 16"`) || !strings.Contains(flag, `fill="red"`) || strings.Contains(flag, "currentColor") {
+		t.Errorf("multicolor icons should keep their colors:\n%s", flag)
+	}
+	if want := "export { CheckIcon } from \"./check\";\nexport { FlagIcon } from \"./flag\";\n"; !strings.HasSuffix(react[2].Content, want) {
+		t.Errorf("unexpected index:\n%s", react[2].Content)
+	}
+
+	astro, err := GenerateComponents(files, "astro", DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(astro[0].Content, `stroke-width="2"`) || !strings.Contains(astro[2].Content, `export { default as FlagIcon } from "./flag.astro";`) {
+		t.Errorf("unexpected astro output:\n%s\n%s", astro[0].Content, astro[2].Content)
+	}
+	again, _ := GenerateComponents(files, "astro", DefaultOptions())
+	if !reflect.DeepEqual(astro, again) {
+		t.Error("generating twice should give the same files")
+	}
+
+	if _, err := GenerateComponents(files, "vue", DefaultOptions()); err == nil {
+		t.Error("expected an unknown framework to fail")
+	}
+}
+
+func TestIsMonochrome(t *testing.T) {
+	tests := []struct {
+		name string
+		svg  string
+		want bool
+	}{
+		{"default fill and red", `<svg><path d="M0 0h4v4z"/><circle r="2" fill="#f00"/></svg>`, false},
+		{"default fill and black", `<svg><path d="M0 0h4v4z"/><circle r="2" fill="black"/></svg>`, true},
+		{"inherited fill", `<svg><g fill="#f00"><path d="M0 0h4v4z"/></g><circle r="2" fill="#ff0000"/></svg>`, true},
+		{"unfilled stroke", `<svg fill="none"><path d="M0 0h4" stroke="#f00"/></svg>`, true},
+		{"clip path", `<svg><clipPath id="c"><rect width="4" height="4"/></clipPath><path d="M0 0h4v4z" fill="red" clip-path="url(#c)"/></svg>`, true},
+		{"two colors", `<svg><path d="M0 0h4v4z" fill="red"/><path d="M0 0h4v4z" fill="blue"/></svg>`, false},
+	}
+	for _, tt := range tests {
+		doc, err := ParseDocument([]byte(tt.svg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := isMonochrome(rootElement(doc)); got != tt.want {
+			t.Errorf("%s: isMonochrome() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJSXAttrName(t *testing.T) {
+	for in, want := range map[string]string{
+		"stroke-width": "strokeWidth",
+		"xlink:href":   "xlinkHref",
+		"class":        "className",
+		"aria-label":   "aria-label",
+		"viewBox":      "viewBox",
+	} {
+		if got := jsxAttrName(in); got != want {
+			t.Errorf("jsxAttrName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"#FFF", "#ffffff", true},
+		{"#0f08", "#00ff0088", true},
+		{"#18181b", "#18181b", true},
+		{"white", "#ffffff", true},
+		{"rgb(255, 0, 0)", "#ff0000", true},
+		{"rgba(0 0 255 / 50%)", "#0000ff80", true},
+		{"rgb(100%, 50%, 0%)", "#ff8000", true},
+		{"hsl(240 10% 3.9%)", "#09090b", true},
+		{"hsl(0deg, 72.2%, 50.6%)", "#dc2626", true},
+		{"hsla(120, 100%, 50%, 0.5)", "#00ff0080", true},
+		{"hotpink", "", false},
+		{"#12345", "", false},
+		{"rgb(1, 2)", "", false},
+		{"var(--x)", "", false},
+	}
+	for _, tt := range tests {
+		c, ok := ParseColor(tt.input)
+		if ok != tt.ok || (ok && c.Hex() != tt.want) {
+			t.Errorf("ParseColor(%q) = %s, %v, want %s, %v", tt.input, c.Hex(), ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLoadPalette(t *testing.T) {
+	dir := t.TempDir()
+	css := filepath.Join(dir, "global.css")
+	os.WriteFile(css, []byte(`@theme {
+  /* --color-commented: #123456; */
+  --color-background: hsl(240 10% 3.9%);
+  --color-primary: #fafafa;
+  --radius: 0.5rem;
+  --color-sidebar: var(--sidebar);
+}
+.dark { --color-primary: #000; }`), 0644)
+	p, err := LoadPalette(css)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tok := range p {
+		names = append(names, tok.Name+"="+tok.Color.Hex())
+	}
+	if want := []string{"color-background=#09090b", "color-primary=#fafafa"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("CSS palette = %v, want %v", names, want)
+	}
+
+	yml := filepath.Join(dir, "palette.yaml")
+	os.WriteFile(yml, []byte("brand: \"#e11d48\"\n--ink: rgb(9, 9, 11)\n"), 0644)
+	if p, err = LoadPalette(yml); err != nil {
+		t.Fatal(err)
+	}
+	if len(p) != 2 || p[0].Name != "brand" || p[1].Name != "ink" || p[1].Color.Hex() != "#09090b" {
+		t.Errorf("unexpected YAML palette %+v", p)
+	}
+
+	os.WriteFile(yml, []byte("brand: blurple\n"), 0644)
+	if _, err := LoadPalette(yml); err == nil || !strings.Contains(err.Error(), "not a color") {
+		t.Errorf("expected an invalid color error, got %v", err)
+	}
+}
+
+func TestCheckPalette(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	palette := Palette{
+		{Name: "color-ink", Value: "#09090b", Color: Color{9, 9, 11, 1}},
+		{Name: "color-brand", Value: "#e11d48", Color: Color{225, 29, 72, 1}},
+	}
+	a := write("a.svg", `<svg xmlns="http://www.w3.org/2000/svg"><style>.x{fill:#ff0000}</style><path fill="#09090B" d="M0 0h1"/><path class="x" style="stroke:rgb(10, 10, 12); stroke-width:2" d="M0 0h1"/></svg>`)
+	b := write("b.svg", `<svg xmlns="http://www.w3.org/2000/svg"><path fill="var(--color-brand)" stroke="hotpink" d="M0 0h1"/><path fill="url(#g)" stroke="currentColor" d="M0 0h1"/></svg>`)
+
+	report, err := CheckPalette([]string{b, a}, palette, FixNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	colors := make(map[string]ColorUse)
+	for _, u := range report.Colors {
+		colors[u.Value] = u
+	}
+	if len(colors) != 5 {
+		t.Errorf("expected 5 colors, got %+v", report.Colors)
+	}
+	if colors["#09090b"].Token != "color-ink" || colors["#0a0a0c"].Token != "color-ink" {
+		t.Errorf("expected #09090b and a rounding of it to be color-ink, got %+v", report.Colors)
+	}
+	if u := colors["#ff0000"]; u.OnPalette() || u.Nearest != "color-brand" || u.Distance == 0 {
+		t.Errorf("expected #ff0000 to be off palette near color-brand, got %+v", u)
+	}
+	if u := colors["var(--color-brand)"]; u.Token != "color-brand" || len(u.Files) != 1 {
+		t.Errorf("expected the var() reference to be color-brand, got %+v", u)
+	}
+	if u := colors["hotpink"]; u.OnPalette() || u.Nearest != "" {
+		t.Errorf("expected hotpink to be unmatched, got %+v", u)
+	}
+	if report.Colors[0].OnPalette() || !report.Colors[len(report.Colors)-1].OnPalette() {
+		t.Errorf("expected off-palette colors first, got %+v", report.Colors)
+	}
+	if len(report.Icons) != 2 || len(report.Icons[0].Colors) != 3 || len(report.Icons[1].Colors) != 2 {
+		t.Errorf("unexpected icons %+v", report.Icons)
+	}
+	if off := report.OffPalette(); len(off) != 2 {
+		t.Errorf("expected 2 off-palette colors, got %+v", off)
+	}
+
+	report, err = CheckPalette([]string{a}, palette, FixVar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := report.Colors[0]; u.Value != "#ff0000" || u.Replaced != "color-brand" || len(report.OffPalette()) != 0 {
+		t.Errorf("expected #ff0000 to be reported as replaced, got %+v", report.Colors)
+	}
+	data, _ := os.ReadFile(a)
+	if !strings.Contains(string(data), `.x{fill:var(--color-brand, #e11d48)}`) || !strings.Contains(string(data), `fill="#09090B"`) {
+		t.Errorf("expected the off-palette color to be replaced, got %s", data)
+	}
+	report, _ = CheckPalette([]string{a}, palette, FixNone)
+	if off := report.OffPalette(); len(off) != 0 {
+		t.Errorf("expected no off-palette colors after fixing, got %+v", off)
+	}
+
+	logo := write("logo.svg", `<svg xmlns="http://www.w3.org/2000/svg"><path d="M0 0h1"/><path fill="#09090b" d="M0 0h1"/></svg>`)
+	report, err = CheckPalette([]string{logo}, palette, FixColor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if icon := report.Icons[0]; len(icon.Colors) != 2 || icon.Colors[1] != "#000000" || icon.Fixed != 1 {
+		t.Errorf("expected the default black fill to be counted and fixed, got %+v", icon)
+	}
+	data, _ = os.ReadFile(logo)
+	if !strings.HasPrefix(string(data), `<svg xmlns="http://www.w3.org/2000/svg" fill="#09090b">`) {
+		t.Errorf("expected the default fill to be replaced on the root, got %s", data)
+	}
+}
@@ -0,0 +1,391 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ─── Transforms ─────────────────────────────────────────────────────────────
+
+// Matrix is an affine transform as in SVG's matrix(a b c d e f), mapping
+// (x, y) to (a·x + c·y + e, b·x + d·y + f).
+type Matrix struct {
+	A, B, C, D, E, F float64
+}
+
+// Identity is the transform that changes nothing.
+var Identity = Matrix{A: 1, D: 1}
+
+// Mul returns the transform applying n first, then m.
+func (m Matrix) Mul(n Matrix) Matrix {
+	return Matrix{
+		A: m.A*n.A + m.C*n.B,
+		B: m.B*n.A + m.D*n.B,
+		C: m.A*n.C + m.C*n.D,
+		D: m.B*n.C + m.D*n.D,
+		E: m.A*n.E + m.C*n.F + m.E,
+		F: m.B*n.E + m.D*n.F + m.F,
+	}
+}
+
+// Apply maps a point.
+func (m Matrix) Apply(p Point) Point {
+	return Point{m.A*p.X + m.C*p.Y + m.E, m.B*p.X + m.D*p.Y + m.F}
+}
+
+// IsTranslation reports whether the transform only moves points.
+func (m Matrix) IsTranslation() bool {
+	return m.A == 1 && m.B == 0 && m.C == 0 && m.D == 1
+}
+
+// Similarity returns the scale factor of a transform that keeps shapes,
+// scaling uniformly and possibly rotating or mirroring them. Strokes and
+// dashes scale by that factor. It reports false for other transforms.
+func (m Matrix) Similarity() (float64, bool) {
+	sx, sy := m.A*m.A+m.B*m.B, m.C*m.C+m.D*m.D
+	if math.Abs(sx-sy) > exact*math.Max(sx, sy) || math.Abs(m.A*m.C+m.B*m.D) > exact*math.Max(sx, sy) {
+		return 0, false
+	}
+	return math.Sqrt(sx), true
+}
+
+// String writes the transform as a matrix() function.
+func (m Matrix) String() string {
+	args := formatArgs([]float64{m.A, m.B, m.C, m.D, m.E, m.F}, -1)
+	return "matrix(" + strings.Join(args, " ") + ")"
+}
+
+// Translate returns a transform moving points by (tx, ty).
+func Translate(tx, ty float64) Matrix {
+	return Matrix{A: 1, D: 1, E: tx, F: ty}
+}
+
+// Scale returns a transform scaling points from the origin.
+func Scale(sx, sy float64) Matrix {
+	return Matrix{A: sx, D: sy}
+}
+
+// Rotate returns a transform rotating points around the origin by deg
+// degrees, clockwise in SVG's downward y axis.
+func Rotate(deg float64) Matrix {
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	return Matrix{A: cos, B: sin, C: -sin, D: cos}
+}
+
+// transformArgs is how many numbers each transform function accepts.
+var transformArgs = map[string][]int{
+	"matrix":    {6},
+	"translate": {1, 2},
+	"scale":     {1, 2},
+	"rotate":    {1, 3},
+	"skewX":     {1},
+	"skewY":     {1},
+}
+
+// ParseTransform parses a 'transform' attribute into a single matrix.
+func ParseTransform(s string) (Matrix, error) {
+	sc := pathScanner{src: s}
+	m := Identity
+	sc.skipSpace()
+	for !sc.done() {
+		start := sc.pos
+		for !sc.done() && (sc.src[sc.pos] >= 'a' && sc.src[sc.pos] <= 'z' || sc.src[sc.pos] >= 'A' && sc.src[sc.pos] <= 'Z') {
+			sc.pos++
+		}
+		name := sc.src[start:sc.pos]
+		counts, ok := transformArgs[name]
+		if !ok {
+			return Identity, fmt.Errorf("invalid transform %q: unknown function %q", s, name)
+		}
+		sc.skipSpace()
+		if sc.done() || sc.src[sc.pos] != '(' {
+			return Identity, fmt.Errorf("invalid transform %q: expected ( after %s", s, name)
+		}
+		sc.pos++
+
+		var args []float64
+		sc.skipSpace()
+		for !sc.done() && sc.src[sc.pos] != ')' {
+			if len(args) > 0 {
+				sc.skipSeparator()
+			}
+			v, err := sc.number()
+			if err != nil {
+				return Identity, fmt.Errorf("invalid transform %q: expected a number at offset %d", s, sc.pos)
+			}
+			args = append(args, v)
+			sc.skipSpace()
+		}
+		if sc.done() {
+			return Identity, fmt.Errorf("invalid transform %q: unclosed %s(", s, name)
+		}
+		sc.pos++
+		if !containsInt(counts, len(args)) {
+			return Identity, fmt.Errorf("invalid transform %q: %s takes %v arguments, got %d", s, name, counts, len(args))
+		}
+
+		m = m.Mul(transformFunc(name, args))
+		sc.skipSeparator()
+	}
+	return m, nil
+}
+
+func transformFunc(name string, a []float64) Matrix {
+	switch name {
+	case "matrix":
+		return Matrix{a[0], a[1], a[2], a[3], a[4], a[5]}
+	case "translate":
+		if len(a) == 1 {
+			return Translate(a[0], 0)
+		}
+		return Translate(a[0], a[1])
+	case "scale":
+		if len(a) == 1 {
+			return Scale(a[0], a[0])
+		}
+		return Scale(a[0], a[1])
+	case "rotate":
+		if len(a) == 3 {
+			return Translate(a[1], a[2]).Mul(Rotate(a[0])).Mul(Translate(-a[1], -a[2]))
+		}
+		return Rotate(a[0])
+	case "skewX":
+		return Matrix{A: 1, C: math.Tan(a[0] * math.Pi / 180), D: 1}
+	default: // skewY
+		return Matrix{A: 1, B: math.Tan(a[0] * math.Pi / 180), D: 1}
+	}
+}
+
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Transform returns the path with a transform applied to its coordinates.
+// The result is absolute, with shorthand commands expanded; arcs stay arcs,
+// their ellipses transformed.
+func (p Path) Transform(m Matrix) Path {
+	out := make(Path, 0, len(p))
+	for _, seg := range normalize(p) {
+		args := append([]float64(nil), seg.Args...)
+		if seg.Command == 'A' {
+			args[0], args[1], args[2] = transformEllipse(args[0], args[1], args[2], m)
+			if m.A*m.D-m.B*m.C < 0 {
+				args[4] = 1 - args[4] // mirroring reverses the sweep
+			}
+			end := m.Apply(pt(args, 5))
+			args[5], args[6] = end.X, end.Y
+		} else {
+			for i := 0; i+1 < len(args); i += 2 {
+				q := m.Apply(pt(args, i))
+				args[i], args[i+1] = q.X, q.Y
+			}
+		}
+		out = append(out, Segment{Command: seg.Command, Args: args})
+	}
+	return out
+}
+
+// transformEllipse returns the radii and rotation, in degrees, of the
+// ellipse an arc lies on once transformed. The image of an ellipse under an
+// affine transform is an ellipse, whose axes are found from the
+// eigenvalues of the transformed shape matrix.
+func transformEllipse(rx, ry, rotation float64, m Matrix) (float64, float64, float64) {
+	sin, cos := math.Sincos(rotation * math.Pi / 180)
+	ma := [4]float64{
+		rx * (m.A*cos + m.C*sin),
+		rx * (m.B*cos + m.D*sin),
+		ry * (-m.A*sin + m.C*cos),
+		ry * (-m.B*sin + m.D*cos),
+	}
+	j := ma[0]*ma[0] + ma[2]*ma[2]
+	k := ma[1]*ma[1] + ma[3]*ma[3]
+	d := ((ma[0]-ma[3])*(ma[0]-ma[3]) + (ma[2]+ma[1])*(ma[2]+ma[1])) *
+		((ma[0]+ma[3])*(ma[0]+ma[3]) + (ma[2]-ma[1])*(ma[2]-ma[1]))
+	jk := (j + k) / 2
+	if d < exact*jk {
+		r := math.Sqrt(jk)
+		return r, r, 0
+	}
+
+	l := ma[0]*ma[1] + ma[2]*ma[3]
+	d = math.Sqrt(d)
+	l1, l2 := jk+d/2, jk-d/2
+	var angle float64
+	switch {
+	case math.Abs(l) < exact && math.Abs(l1-k) < exact:
+		angle = 90
+	case math.Abs(l) > math.Abs(l1-k):
+		angle = math.Atan((l1-j)/l) * 180 / math.Pi
+	default:
+		angle = math.Atan(l/(l1-k)) * 180 / math.Pi
+	}
+	if angle >= 0 {
+		return math.Sqrt(l1), math.Sqrt(math.Max(l2, 0)), angle
+	}
+	return math.Sqrt(math.Max(l2, 0)), math.Sqrt(l1), angle + 90
+}
+
+// ─── Bounds ─────────────────────────────────────────────────────────────────
+
+// Bounds returns the tight bounding box of a path's outline, curve
+// extremes included. An empty path has an inverted, infinite box.
+func (p Path) Bounds() (lo, hi Point) {
+	lo = Point{math.Inf(1), math.Inf(1)}
+	hi = Point{math.Inf(-1), math.Inf(-1)}
+	add := func(q Point) {
+		lo = Point{math.Min(lo.X, q.X), math.Min(lo.Y, q.Y)}
+		hi = Point{math.Max(hi.X, q.X), math.Max(hi.Y, q.Y)}
+	}
+
+	var cur, start Point
+	for _, seg := range normalize(p) {
+		a := seg.Args
+		switch seg.Command {
+		case 'M', 'L':
+			add(pt(a, 0))
+		case 'C':
+			p0, c1, c2, p3 := cur, pt(a, 0), pt(a, 2), pt(a, 4)
+			add(p3)
+			for _, t := range cubicExtrema(p0, c1, c2, p3) {
+				add(cubicAt(p0, c1, c2, p3, t))
+			}
+		case 'Q':
+			c, p2 := pt(a, 0), pt(a, 2)
+			add(p2)
+			for _, t := range []float64{quadExtremum(cur.X, c.X, p2.X), quadExtremum(cur.Y, c.Y, p2.Y)} {
+				if t > 0 && t < 1 {
+					add(lerp(lerp(cur, c, t), lerp(c, p2, t), t))
+				}
+			}
+		case 'A':
+			add(pt(a, 5))
+			for _, q := range arcExtrema(cur, seg) {
+				add(q)
+			}
+		}
+		cur = endPoint(seg, cur, start)
+		if seg.Command == 'M' {
+			start = cur
+		}
+	}
+	return lo, hi
+}
+
+// quadExtremum returns where a quadratic Bézier coordinate has its extreme,
+// or -1 if it has none.
+func quadExtremum(p0, c, p2 float64) float64 {
+	d := p0 - 2*c + p2
+	if d == 0 {
+		return -1
+	}
+	return (p0 - c) / d
+}
+
+// cubicExtrema returns the parameters in (0, 1) where a cubic Bézier
+// reaches an extreme in x or y: the roots of its derivative.
+func cubicExtrema(p0, c1, c2, p3 Point) []float64 {
+	var ts []float64
+	for _, v := range [][4]float64{{p0.X, c1.X, c2.X, p3.X}, {p0.Y, c1.Y, c2.Y, p3.Y}} {
+		a := -v[0] + 3*v[1] - 3*v[2] + v[3]
+		b := 2 * (v[0] - 2*v[1] + v[2])
+		c := v[1] - v[0]
+		var roots []float64
+		if math.Abs(a) < exact {
+			if b != 0 {
+				roots = append(roots, -c/b)
+			}
+		} else if disc := b*b - 4*a*c; disc >= 0 {
+			sq := math.Sqrt(disc)
+			roots = append(roots, (-b+sq)/(2*a), (-b-sq)/(2*a))
+		}
+		for _, t := range roots {
+			if t > 0 && t < 1 {
+				ts = append(ts, t)
+			}
+		}
+	}
+	return ts
+}
+
+// arcCenter converts an arc from its endpoints to its center, radii and
+// angles, following the SVG implementation notes: radii too small to span
+// the endpoints are scaled up.
+func arcCenter(from Point, seg Segment) (center Point, rx, ry, phi, theta, delta float64, ok bool) {
+	a := seg.Args
+	to := pt(a, 5)
+	rx, ry = math.Abs(a[0]), math.Abs(a[1])
+	if rx == 0 || ry == 0 || from == to {
+		return Point{}, 0, 0, 0, 0, 0, false
+	}
+	phi = a[2] * math.Pi / 180
+	sin, cos := math.Sincos(phi)
+
+	dx, dy := (from.X-to.X)/2, (from.Y-to.Y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	f := math.Sqrt(math.Max(num, 0) / den)
+	if (a[3] != 0) == (a[4] != 0) {
+		f = -f
+	}
+	cx1, cy1 := f*rx*y1/ry, -f*ry*x1/rx
+	center = Point{
+		cos*cx1 - sin*cy1 + (from.X+to.X)/2,
+		sin*cx1 + cos*cy1 + (from.Y+to.Y)/2,
+	}
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta = angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta = angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if a[4] == 0 && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if a[4] != 0 && delta < 0 {
+		delta += 2 * math.Pi
+	}
+	return center, rx, ry, phi, theta, delta, true
+}
+
+// arcExtrema returns the points where an arc reaches an extreme in x or y
+// between its endpoints.
+func arcExtrema(from Point, seg Segment) []Point {
+	c, rx, ry, phi, theta, delta, ok := arcCenter(from, seg)
+	if !ok {
+		return nil
+	}
+	sin, cos := math.Sincos(phi)
+	at := func(t float64) Point {
+		st, ct := math.Sincos(t)
+		return Point{c.X + rx*cos*ct - ry*sin*st, c.Y + rx*sin*ct + ry*cos*st}
+	}
+
+	var out []Point
+	tx := math.Atan2(-ry*sin, rx*cos)
+	ty := math.Atan2(ry*cos, rx*sin)
+	for _, t := range []float64{tx, tx + math.Pi, ty, ty + math.Pi} {
+		// How far t lies along the arc's direction from its start.
+		d := math.Mod(t-theta, 2*math.Pi)
+		if delta < 0 {
+			d = -d
+		}
+		if d < 0 {
+			d += 2 * math.Pi
+		}
+		if d <= math.Abs(delta) {
+			out = append(out, at(t))
+		}
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package svg
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+// ─── Visual Verification ────────────────────────────────────────────────────
+
+const (
+	// DefaultMaxDiff is the share of painted pixels allowed to change
+	// visibly before an optimization is rejected.
+	DefaultMaxDiff = 0.01
+
+	// visibleDelta is how much a color channel must change, out of 255, for
+	// a pixel to count as changed. Anti-aliasing shifts below it are noise.
+	visibleDelta = 32
+)
+
+// verifySizes are the square sizes, in pixels, that icons are compared at.
+// Small sizes catch lost details, large ones shifted outlines.
+var verifySizes = []int{16, 32, 64}
+
+// rasterized are the elements the rasterizer draws, or reads for drawing,
+// and those that draw nothing anyway. It skips any other element, so that
+// changes to clip paths, masks or text would go unseen.
+var rasterized = map[string]bool{
+	"svg": true, "g": true, "use": true, "defs": true, "style": true, "title": true, "desc": true,
+	"path": true, "rect": true, "circle": true, "ellipse": true, "line": true, "polyline": true,
+	"polygon": true, "linearGradient": true, "radialGradient": true, "stop": true,
+}
+
+// unrenderable returns the names of the elements of a document the
+// rasterizer would skip, once each. Metadata and elements of other
+// namespaces, which draw nothing, are left out.
+func unrenderable(data []byte) ([]string, error) {
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	doc.Walk(func(n *Node) bool {
+		if n.Type != ElementNode {
+			return n.Type == DocumentNode
+		}
+		name, ok := svgName(n)
+		if !ok || name == "metadata" {
+			return false
+		}
+		if !rasterized[name] {
+			if !containsString(names, name) {
+				names = append(names, name)
+			}
+			return false
+		}
+		return true
+	})
+	return names, nil
+}
+
+// Rasterize renders an SVG document to a size×size image, scaling its
+// viewBox to fit.
+func Rasterize(data []byte, size int) (*image.RGBA, error) {
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data), oksvg.IgnoreErrorMode)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	icon.SetTarget(0, 0, float64(size), float64(size))
+	scanner := rasterx.NewScannerGV(size, size, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(size, size, scanner), 1)
+	return img, nil
+}
+
+// PixelDiff returns the share of painted pixels, those not transparent in
+// either image, that differ visibly between two images of the same size.
+// Measuring against the painted area rather than the canvas keeps thin
+// line icons from passing while losing most of their ink.
+func PixelDiff(a, b *image.RGBA) float64 {
+	if a.Bounds() != b.Bounds() {
+		return 1
+	}
+	painted, changed := 0, 0
+	for i := 0; i < len(a.Pix); i += 4 {
+		if a.Pix[i+3] == 0 && b.Pix[i+3] == 0 {
+			continue
+		}
+		painted++
+		for c := range 4 {
+			d := int(a.Pix[i+c]) - int(b.Pix[i+c])
+			if d > visibleDelta || d < -visibleDelta {
+				changed++
+				break
+			}
+		}
+	}
+	if painted == 0 {
+		return 0
+	}
+	return float64(changed) / float64(painted)
+}
+
+// VisualDiff rasterizes two versions of a document at every verification
+// size and returns the largest share of visibly changed pixels. Documents
+// with elements the rasterizer skips cannot be verified, which is an
+// error naming them.
+func VisualDiff(before, after []byte) (float64, error) {
+	for _, data := range [][]byte{before, after} {
+		skipped, err := unrenderable(data)
+		if err != nil {
+			return 0, err
+		}
+		if len(skipped) > 0 {
+			return 0, fmt.Errorf("cannot verify <%s>, which the renderer skips", strings.Join(skipped, ">, <"))
+		}
+	}
+	worst := 0.0
+	for _, size := range verifySizes {
+		a, err := Rasterize(before, size)
+		if err != nil {
+			return 0, fmt.Errorf("failed to render original: %w", err)
+		}
+		b, err := Rasterize(after, size)
+		if err != nil {
+			return 0, fmt.Errorf("failed to render optimized file: %w", err)
+		}
+		worst = max(worst, PixelDiff(a, b))
+	}
+	return worst, nil
+}
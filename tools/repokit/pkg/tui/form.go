@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"repokit/pkg/core"
+)
+
+// ─── Parameter Form ──────────────────────────────────────────────────────────
+
+var (
+	formLabelStyle = lipgloss.NewStyle().Foreground(colorMuted).Width(12)
+	formFocusStyle = lipgloss.NewStyle().Foreground(colorAccent).Bold(true).Width(12)
+	formErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ef4444"))
+)
+
+// paramForm asks for the params of a native command before it runs. Inputs
+// start empty; an empty input takes the param's default, shown as the
+// placeholder.
+type paramForm struct {
+	command core.NativeCommand
+	params  []core.Param
+	inputs  []textinput.Model
+	focus   int
+	err     string
+}
+
+func newParamForm(c core.NativeCommand) paramForm {
+	f := paramForm{command: c, params: c.Params()}
+	for _, p := range f.params {
+		ti := textinput.New()
+		ti.Prompt = "❯ "
+		ti.CharLimit = 156
+		ti.Width = 40
+		switch {
+		case p.Bool:
+			ti.Placeholder = "true/false"
+			if p.Default != "" {
+				ti.Placeholder += " (" + p.Default + ")"
+			}
+		case len(p.Options) > 0:
+			ti.Placeholder = strings.Join(p.Options, "/")
+			if p.Default != "" {
+				ti.Placeholder += " (" + p.Default + ")"
+			}
+		default:
+			ti.Placeholder = p.Default
+		}
+		f.inputs = append(f.inputs, ti)
+	}
+	if len(f.inputs) > 0 {
+		f.inputs[0].Focus()
+	}
+	return f
+}
+
+// Update handles a key press. It returns the resolved params once the form
+// is submitted with valid values.
+func (f *paramForm) Update(msg tea.KeyMsg) (core.Params, tea.Cmd) {
+	switch msg.String() {
+	case "tab", "down":
+		f.setFocus(f.focus + 1)
+		return nil, nil
+	case "shift+tab", "up":
+		f.setFocus(f.focus - 1)
+		return nil, nil
+	case "enter":
+		given := make(map[string]string, len(f.params))
+		for i, p := range f.params {
+			given[p.Name] = strings.TrimSpace(f.inputs[i].Value())
+		}
+		params, err := core.ResolveParams(f.command, given)
+		if err != nil {
+			f.err = err.Error()
+			return nil, nil
+		}
+		return params, nil
+	}
+
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	f.err = ""
+	return nil, cmd
+}
+
+// UpdateInput forwards other messages, such as cursor blinks, to the
+// focused input.
+func (f *paramForm) UpdateInput(msg tea.Msg) tea.Cmd {
+	if len(f.inputs) == 0 {
+		return nil
+	}
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	return cmd
+}
+
+func (f *paramForm) setFocus(i int) {
+	if len(f.inputs) == 0 {
+		return
+	}
+	f.inputs[f.focus].Blur()
+	f.focus = (i + len(f.inputs)) % len(f.inputs)
+	f.inputs[f.focus].Focus()
+}
+
+func (f paramForm) View() string {
+	var sb strings.Builder
+	sb.WriteString(lipgloss.NewStyle().Foreground(colorAccent).Render(f.command.Name()) + "\n")
+	sb.WriteString(lipgloss.NewStyle().Foreground(colorMuted).Render(f.command.Description()) + "\n\n")
+
+	for i, p := range f.params {
+		label := formLabelStyle
+		if i == f.focus {
+			label = formFocusStyle
+		}
+		sb.WriteString(label.Render(p.Name) + f.inputs[i].View() + "\n")
+		if p.Description != "" {
+			sb.WriteString(formLabelStyle.Render("") + helpStyle.Render("  "+p.Description) + "\n")
+		}
+	}
+
+	if f.err != "" {
+		sb.WriteString("\n" + formErrorStyle.Render(f.err) + "\n")
+	}
+	help := keyStyle.Render("Tab/↑/↓") + " field • " + keyStyle.Render("Enter") + " run • " + keyStyle.Render("Esc") + " cancel • empty fields use the default"
+	sb.WriteString("\n" + helpStyle.Render(help))
+	return sb.String()
+}
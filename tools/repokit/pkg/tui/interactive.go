@@ -0,0 +1,41 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"repokit/pkg/runner"
+)
+
+// ─── Interactive Sessions ────────────────────────────────────────────────────
+
+// execRequest asks the UI to suspend itself and run an interactive command
+// from the task goroutine; the result is sent back on done.
+type execRequest struct {
+	cmd  *runner.InteractiveCmd
+	done chan error
+}
+
+var execRequests = make(chan execRequest)
+
+// execInTUI is installed as runner.ExecInteractive while the TUI runs. It
+// blocks the calling task until the session ends and the TUI is restored.
+func execInTUI(c *runner.InteractiveCmd) error {
+	req := execRequest{cmd: c, done: make(chan error, 1)}
+	execRequests <- req
+	return <-req.done
+}
+
+func listenForExec() tea.Cmd {
+	return func() tea.Msg {
+		return <-execRequests
+	}
+}
+
+// execCmd releases the terminal to the command and resumes the TUI when it
+// exits. The exit status reaches the task list through the task's events.
+func execCmd(req execRequest) tea.Cmd {
+	return tea.Exec(req.cmd, func(err error) tea.Msg {
+		req.done <- err
+		return nil
+	})
+}
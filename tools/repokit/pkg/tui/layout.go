@@ -0,0 +1,187 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ─── Layout Regions & Mouse Hit-Testing ──────────────────────────────────────
+
+const (
+	defaultSidebarWidth = 35
+	minSidebarWidth     = 20
+	wheelStep           = 3
+)
+
+type rect struct {
+	x, y, w, h int
+}
+
+func (r rect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h
+}
+
+type zoneKind int
+
+const (
+	zoneTab zoneKind = iota
+	zoneSidebar
+	zoneDivider
+	zoneItem
+	zoneTaskRow
+	zonePane
+	zoneLog
+)
+
+// zone is a clickable region recorded while rendering. index identifies the
+// tab, list item, task row or pane the region belongs to.
+type zone struct {
+	kind   zoneKind
+	index  int
+	bounds rect
+}
+
+// zoneMap holds the regions of the last rendered frame. It is shared by
+// pointer so that View, which works on a copy of the model, can record into it.
+type zoneMap struct {
+	zones []zone
+}
+
+func (z *zoneMap) reset() { z.zones = z.zones[:0] }
+
+func (z *zoneMap) add(kind zoneKind, index int, bounds rect) {
+	z.zones = append(z.zones, zone{kind: kind, index: index, bounds: bounds})
+}
+
+// at returns the innermost region under the cursor. Regions recorded later
+// are nested inside earlier ones, so the search runs backwards.
+func (z *zoneMap) at(x, y int) (zone, bool) {
+	for i := len(z.zones) - 1; i >= 0; i-- {
+		if z.zones[i].bounds.contains(x, y) {
+			return z.zones[i], true
+		}
+	}
+	return zone{}, false
+}
+
+// recordSidebar registers the sidebar, its resize handle and every visible
+// list item, given the rendered sidebar block and its origin.
+func (m Model) recordSidebar(rendered string, x, y int) {
+	w, h := lipgloss.Width(rendered), lipgloss.Height(rendered)
+	m.zones.add(zoneSidebar, 0, rect{x, y, w, h})
+	m.zones.add(zoneDivider, 0, rect{x + w - 1, y, 1, h})
+
+	titleHeight := lipgloss.Height(m.list.Styles.TitleBar.Render(m.list.Styles.Title.Render(m.list.Title)))
+	itemHeight := itemDelegate{}.Height() + itemDelegate{}.Spacing()
+	start, end := m.list.Paginator.GetSliceBounds(len(m.list.VisibleItems()))
+	for i := start; i < end; i++ {
+		m.zones.add(zoneItem, i, rect{x, y + titleHeight + (i-start)*itemHeight, w - 1, itemDelegate{}.Height()})
+	}
+}
+
+// resize recomputes component sizes from the window and sidebar widths.
+func (m *Model) resize() {
+	h, v := appStyle.GetFrameSize()
+	innerWidth := m.width - h
+	innerHeight := m.height - v
+
+	// Left menu width calculation
+	leftWidth := m.sidebarWidth
+	if m.sidebarCollapsed {
+		leftWidth = 0
+	}
+
+	m.list.SetSize(m.sidebarWidth-5, innerHeight-6)
+
+	m.viewport.Width = innerWidth - leftWidth - 1
+	m.viewport.Height = innerHeight - 12
+	m.layoutPanes()
+}
+
+// updateMouse routes mouse input to the region under the cursor.
+func (m Model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// Dragging the sidebar border resizes it.
+	if m.resizingSidebar {
+		switch msg.Action {
+		case tea.MouseActionMotion:
+			m.sidebarWidth = min(max(msg.X, minSidebarWidth), m.width/2)
+			m.resize()
+		case tea.MouseActionRelease:
+			m.resizingSidebar = false
+		}
+		return m, nil
+	}
+
+	z, ok := m.zones.at(msg.X, msg.Y)
+	if !ok {
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		m.scrollZone(z, msg.Button == tea.MouseButtonWheelUp)
+		return m, nil
+	case tea.MouseButtonLeft:
+		if msg.Action == tea.MouseActionPress && z.kind == zoneDivider {
+			m.resizingSidebar = true
+			return m, nil
+		}
+		if msg.Action == tea.MouseActionRelease {
+			return m, m.click(z)
+		}
+	}
+	return m, nil
+}
+
+// click activates the clicked region.
+func (m *Model) click(z zone) tea.Cmd {
+	switch z.kind {
+	case zoneTab:
+		m.activeTab = tab(z.index)
+	case zoneItem:
+		// The first click selects, clicking the selected item runs it.
+		if m.list.Index() != z.index {
+			m.list.Select(z.index)
+			return nil
+		}
+		if m.currentState == stateRunning {
+			return nil
+		}
+		if i, ok := m.list.SelectedItem().(item); ok {
+			return m.selectItem(i.id)
+		}
+	case zoneTaskRow:
+		m.selectedTaskIndex = z.index
+		m.focusOutputList = true
+		m.updateViewportContent()
+	case zonePane:
+		m.focusedPane = z.index
+	case zoneLog:
+		m.focusOutputList = false
+	case zoneSidebar, zoneDivider:
+	}
+	return nil
+}
+
+// scrollZone applies a wheel step to the list or viewport under the cursor.
+func (m *Model) scrollZone(z zone, up bool) {
+	switch z.kind {
+	case zoneSidebar, zoneItem, zoneDivider:
+		if up {
+			m.list.CursorUp()
+		} else {
+			m.list.CursorDown()
+		}
+		return
+	case zonePane:
+		m.focusedPane = z.index
+	case zoneTab, zoneTaskRow, zoneLog:
+	}
+
+	vp := m.scrollTarget()
+	if up {
+		vp.ScrollUp(wheelStep)
+	} else {
+		vp.ScrollDown(wheelStep)
+	}
+}
@@ -13,7 +13,6 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
-	"repokit/pkg/commands"
 	"repokit/pkg/core"
 	"repokit/pkg/runner"
 )
@@ -40,6 +39,7 @@ var (
 	taskStyleSuccess = lipgloss.NewStyle().Foreground(lipgloss.Color("#10b981")).Bold(true)
 	taskStyleError   = lipgloss.NewStyle().Foreground(lipgloss.Color("#ef4444")).Bold(true)
 	logStyle         = lipgloss.NewStyle().Foreground(colorMuted)
+	stderrStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#f87171"))
 
 	// Layout Styles
 	headerStyle    = lipgloss.NewStyle().
@@ -88,16 +88,11 @@ const (
 	tabCommands tab = iota
 	tabOutput
 	tabHistory
+	tabTimeline
+	tabProblems
+	tabCount
 )
 
-type runRecord struct {
-	ID        string
-	StartTime time.Time
-	Duration  time.Duration
-	Status    string
-	Error     string
-}
-
 type item struct {
 	title       string
 	description string
@@ -110,7 +105,7 @@ func (i item) FilterValue() string { return i.title + " " + i.id }
 
 type itemDelegate struct{}
 
-func (d itemDelegate) Height() int                               { return 1 }
+func (d itemDelegate) Height() int                               { return 2 }
 func (d itemDelegate) Spacing() int                              { return 0 }
 func (d itemDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
@@ -140,17 +135,32 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 
 type taskState struct {
 	name      string
-	status    string // "running", "done", "error"
+	status    string // "running", "done", "error", "cancelled"
 	errorMsg  string
 	start     time.Time
 	elapsed   time.Duration
 	logs      []string
+
+	usage      core.ResourceUsage
+	cpuSamples []float64
+	memSamples []float64
+
+	output []string // full (capped) log shown in the task's split pane
+
+	logFile   string
+	artifacts []string
+	problems  []core.Problem
+
+	// The last line received was an in-place \r update on this stream, at
+	// this index of the combined log; the next line of the stream replaces it.
+	partialStream core.Stream
+	partialAt     int
 }
 
 type Model struct {
 	currentState state
 	list         list.Model
-	textInput    textinput.Model
+	form         paramForm
 	spinner      spinner.Model
 	viewport     viewport.Model
 	quitting     bool
@@ -159,7 +169,8 @@ type Model struct {
 	height int
 
 	activeMenuItem string
-	taskQuery      string
+	runStart       time.Time
+	recorder       *core.Recorder
 
 	// Engine state
 	tasks map[string]*taskState
@@ -168,12 +179,34 @@ type Model struct {
 
 	// Tab state
 	activeTab tab
-	history   []runRecord
+	history   []core.RunRecord
+
+	// Timeline tab: how many runs back from the most recent is shown
+	timelineOffset int
+
+	// Problems tab: how many lines the list is scrolled down
+	problemsOffset int
 
 	// Navigation State
 	selectedTaskIndex int  // -1 for All
 	focusOutputList  bool // true: task list, false: viewport
 	sidebarCollapsed bool
+	sidebarWidth     int
+	resizingSidebar  bool
+
+	// Regions of the last rendered frame, used for mouse hit-testing
+	zones *zoneMap
+
+	// Split output layout
+	layout      outputLayout
+	panes       map[string]*viewport.Model
+	focusedPane int
+	zoomed      bool
+
+	// Command palette (ctrl+k)
+	palette     palette
+	paletteOpen bool
+	notice      string
 }
 
 func NewAppModel(initialTask string) (*Model, error) {
@@ -190,13 +223,12 @@ func NewAppModel(initialTask string) (*Model, error) {
 		}
 		items = append(items, item{title: t.Name, description: desc, id: id})
 	}
-	items = append(items, item{title: "Pack", description: "Bundle the project", id: "pack"})
-	items = append(items, item{title: "Clean", description: "Clean untracked files", id: "clean"})
-	items = append(items, item{title: "Optimize SVGs", description: "Minify SVG assets", id: "optimize_svg"})
-	items = append(items, item{title: "Export Schema", description: "Export JSON schema", id: "export_schema"})
-	items = append(items, item{title: "Generate README", description: "AI README generation", id: "generate_readme"})
-	items = append(items, item{title: "Auto Commit", description: "AI-assisted commits", id: "auto_commit"})
-	items = append(items, item{title: "Help", description: "Show help information", id: "help"})
+	// Native commands, unless a task of the same ID replaces them
+	for _, c := range core.NativeCommands() {
+		if _, ok := config.Tasks[c.ID()]; !ok {
+			items = append(items, item{title: c.Name(), description: c.Description(), id: c.ID()})
+		}
+	}
 
 	// Setup compact list
 	delegate := itemDelegate{}
@@ -209,11 +241,6 @@ func NewAppModel(initialTask string) (*Model, error) {
 	l.SetShowPagination(true)
 	l.Styles.PaginationStyle = lipgloss.NewStyle().PaddingLeft(2).Foreground(colorMuted)
 
-	ti := textinput.New()
-	ti.Focus()
-	ti.CharLimit = 156
-	ti.Width = 40
-
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(colorAccent)
@@ -225,22 +252,32 @@ func NewAppModel(initialTask string) (*Model, error) {
 	core.OSExit = func(code int) {
 		panic(fmt.Errorf("task exited with code %d", code))
 	}
+	runner.ExecInteractive = execInTUI
 
 	m := &Model{
 		currentState: stateMenu,
 		list:         l,
-		textInput:    ti,
 		spinner:      s,
 		viewport:     vp,
 		tasks:        make(map[string]*taskState),
 		activeTab:    tabCommands,
 		selectedTaskIndex: -1,
 		focusOutputList:  true,
+		palette:           newPalette(),
+		panes:             make(map[string]*viewport.Model),
+		sidebarWidth:      defaultSidebarWidth,
+		zones:             &zoneMap{},
 	}
 
+	history, err := core.LoadHistory()
+	if err != nil {
+		m.notice = fmt.Sprintf("Failed to load run history: %v", err)
+	}
+	m.history = history
+
 	if initialTask != "" {
-		m.currentState = stateRunning
 		m.activeMenuItem = initialTask
+		m.resetRun()
 	}
 
 	return m, nil
@@ -248,9 +285,9 @@ func NewAppModel(initialTask string) (*Model, error) {
 
 func (m Model) Init() tea.Cmd {
 	var cmds []tea.Cmd
-	cmds = append(cmds, textinput.Blink, listenForEvents(), m.spinner.Tick)
+	cmds = append(cmds, textinput.Blink, listenForEvents(), listenForExec(), m.spinner.Tick)
 	if m.currentState == stateRunning {
-		cmds = append(cmds, runBgTaskCmd(m.activeMenuItem, ""))
+		cmds = append(cmds, runBgTaskCmd(m.activeMenuItem, nil))
 	}
 	return tea.Batch(cmds...)
 }
@@ -265,14 +302,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
+		if m.paletteOpen {
+			return m.updatePalette(msg)
+		}
+		if msg.String() == "ctrl+k" && m.currentState != stateInput {
+			m.paletteOpen = true
+			m.palette.open(m.menuItems(), m.history)
+			return m, textinput.Blink
+		}
+
 		// Tab switching (available everywhere except when inputting)
 		if m.currentState != stateInput {
 			switch msg.String() {
 			case "tab":
-				m.activeTab = (m.activeTab + 1) % 3
+				m.activeTab = (m.activeTab + 1) % tabCount
 				return m, nil
 			case "shift+tab":
-				m.activeTab = (m.activeTab + 2) % 3
+				m.activeTab = (m.activeTab + tabCount - 1) % tabCount
 				return m, nil
 			case "1":
 				m.activeTab = tabCommands
@@ -283,6 +329,41 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "3":
 				m.activeTab = tabHistory
 				return m, nil
+			case "4":
+				m.activeTab = tabTimeline
+				m.timelineOffset = 0
+				return m, nil
+			case "5":
+				m.activeTab = tabProblems
+				return m, nil
+			}
+		}
+
+		if m.activeTab == tabProblems {
+			switch msg.String() {
+			case "up", "k":
+				m.problemsOffset = max(m.problemsOffset-1, 0)
+				return m, nil
+			case "down", "j":
+				m.problemsOffset = min(m.problemsOffset+1, m.maxProblemsOffset())
+				return m, nil
+			case "pgup":
+				m.problemsOffset = max(m.problemsOffset-10, 0)
+				return m, nil
+			case "pgdown":
+				m.problemsOffset = min(m.problemsOffset+10, m.maxProblemsOffset())
+				return m, nil
+			}
+		}
+
+		if m.activeTab == tabTimeline {
+			switch msg.String() {
+			case "[", "left":
+				m.timelineOffset = min(m.timelineOffset+1, max(len(m.history)-1, 0))
+				return m, nil
+			case "]", "right":
+				m.timelineOffset = max(m.timelineOffset-1, 0)
+				return m, nil
 			}
 		}
 
@@ -302,41 +383,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				if i, ok := m.list.SelectedItem().(item); ok {
-					m.activeMenuItem = i.id
-					switch i.id {
-					case "pack":
-						m.currentState = stateInput
-						m.taskQuery = "Enter directory to pack:"
-						m.textInput.Placeholder = "."
-						m.textInput.SetValue("")
-					case "clean":
-						m.currentState = stateInput
-						m.taskQuery = "Proceed deleting untracked? [y/N]:"
-						m.textInput.Placeholder = "y/N"
-						m.textInput.SetValue("")
-					case "generate_readme":
-						m.currentState = stateInput
-						m.taskQuery = "LLM Provider (openai/gemini/ollama):"
-						m.textInput.Placeholder = "ollama"
-						m.textInput.SetValue("")
-					case "auto_commit":
-						m.currentState = stateInput
-						m.taskQuery = "LLM Provider (none to skip):"
-						m.textInput.Placeholder = "none"
-						m.textInput.SetValue("")
-					default:
-						m.currentState = stateRunning
-						m.tasks = make(map[string]*taskState)
-						m.taskIds = nil
-						m.fullLog = nil
-						m.viewport.SetContent("")
-						m.activeTab = tabOutput
-						cmds = append(cmds, runBgTaskCmd(m.activeMenuItem, ""))
-					}
-					if m.currentState == stateInput {
-						m.textInput.Focus()
-						cmds = append(cmds, textinput.Blink)
-					}
+					cmds = append(cmds, m.selectItem(i.id))
 				}
 			} else if msg.String() != "up" && msg.String() != "down" && m.list.FilterState() != list.Filtering {
 				// We want any typing to implicitly start filtering
@@ -356,16 +403,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case stateInput:
 			if msg.String() == "esc" {
 				m.currentState = stateMenu
-			} else if msg.String() == "enter" {
-				m.currentState = stateRunning
-				m.tasks = make(map[string]*taskState)
-				m.taskIds = nil
-				m.fullLog = nil
-				m.viewport.SetContent("")
-				m.activeTab = tabOutput
-				val := m.textInput.Value()
-				cmds = append(cmds, runBgTaskCmd(m.activeMenuItem, val))
+				return m, nil
 			}
+			params, cmd := m.form.Update(msg)
+			if params != nil {
+				m.resetRun()
+				return m, runBgTaskCmd(m.activeMenuItem, params)
+			}
+			return m, cmd
 		case stateRunning, stateDone:
 			if m.currentState == stateDone {
 				if msg.String() == "q" {
@@ -379,6 +424,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, tea.Batch(cmds...)
 				}
 			}
+			if m.activeTab == tabOutput && msg.String() == "s" {
+				if m.layout == layoutSplit {
+					m.layout = layoutCombined
+				} else {
+					m.layout = layoutSplit
+					m.layoutPanes()
+				}
+				return m, nil
+			}
+			if m.activeTab == tabOutput && m.layout == layoutSplit {
+				if next, cmd, handled := m.updateSplit(msg); handled {
+					return next, cmd
+				}
+			}
 			if msg.String() == "up" || msg.String() == "down" || msg.String() == "pgup" || msg.String() == "pgdown" {
 				if m.activeTab == tabOutput && m.focusOutputList {
 					if msg.String() == "up" {
@@ -399,6 +458,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			if msg.String() == "b" {
 				m.sidebarCollapsed = !m.sidebarCollapsed
+				m.resize()
 				return m, nil
 			}
 			if msg.String() == "enter" && m.activeTab == tabOutput {
@@ -408,74 +468,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.MouseMsg:
-		if msg.Type == tea.MouseLeftRelease {
-			// Tab switching
-			if msg.Y == 0 { // Clicked in top row
-				// logo is roughly 9 chars " REPOKIT "
-				x := msg.X
-				if x > 10 {
-					tabX := x - 10
-					// tabs are roughly "1:Commands ", "2:Output ", "3:History "
-					if tabX < 12 {
-						m.activeTab = tabCommands
-					} else if tabX < 21 {
-						m.activeTab = tabOutput
-					} else if tabX < 31 {
-						m.activeTab = tabHistory
-					}
-					return m, nil
-				}
-			}
-
-			// Sidebar click
-			if !m.sidebarCollapsed && msg.X < 35 && msg.Y > 1 {
-				// Rough index calculation - delegate height is 1
-				// Header is line 0, border is line 1 + margin
-				idx := msg.Y - 2
-				if idx >= 0 && idx < len(m.list.Items()) {
-					m.list.Select(idx)
-					// Trigger action if in menu
-					if m.currentState == stateMenu {
-						// Logic duplicated from enter key for now or just select
-					}
-					return m, nil
-				}
-			}
-		}
-
-		if msg.Type == tea.MouseWheelUp {
-			if !m.sidebarCollapsed && msg.X < 35 {
-				m.list.CursorUp()
-			} else {
-				m.viewport.LineUp(3)
-			}
-			return m, nil
-		}
-		if msg.Type == tea.MouseWheelDown {
-			if !m.sidebarCollapsed && msg.X < 35 {
-				m.list.CursorDown()
-			} else {
-				m.viewport.LineDown(3)
-			}
+		// The palette covers the regions the zones were recorded for, so
+		// clicks on it must not reach them.
+		if m.paletteOpen {
 			return m, nil
 		}
+		return m.updateMouse(msg)
 
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
-		h, v := appStyle.GetFrameSize()
-		innerWidth := m.width - h
-		innerHeight := m.height - v
-
-		// Left menu width calculation
-		leftWidth := 35
-		if m.sidebarCollapsed {
-			leftWidth = 0
-		}
-
-		m.list.SetSize(30, innerHeight-6)
-
-		m.viewport.Width = innerWidth - leftWidth - 1
-		m.viewport.Height = innerHeight - 12
+		m.resize()
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -493,6 +495,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		paneCount := len(m.paneTasks())
+
 		switch msg.Type {
 		case core.EventTaskStart:
 			if t, ok := m.tasks[msg.TaskID]; ok {
@@ -502,21 +506,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		case core.EventTaskLog:
+			m.appendLog(msg)
+		case core.EventTaskUsage:
+			if t, ok := m.tasks[msg.TaskID]; ok && msg.Usage != nil {
+				t.recordUsage(*msg.Usage)
+			}
+		case core.EventTaskLogFile:
 			if t, ok := m.tasks[msg.TaskID]; ok {
-				clean := core.CleanANSI(msg.Data)
-				t.logs = append(t.logs, clean)
-				if len(t.logs) > 5 {
-					t.logs = t.logs[1:]
-				}
+				t.logFile = msg.Data
 			}
-			newLog := core.CleanANSI(msg.Data)
-			if newLog != "" {
-				formatted := colorizeLog(fmt.Sprintf("[%s] %s", msg.TaskID, newLog))
-				m.fullLog = append(m.fullLog, formatted)
-				if len(m.fullLog) > 500 {
-					m.fullLog = m.fullLog[len(m.fullLog)-500:]
-				}
-				m.updateViewportContent()
+		case core.EventTaskArtifact:
+			if t, ok := m.tasks[msg.TaskID]; ok {
+				t.artifacts = append(t.artifacts, msg.Data)
+			}
+		case core.EventTaskProblem:
+			if t, ok := m.tasks[msg.TaskID]; ok && msg.Problem != nil {
+				t.problems = append(t.problems, *msg.Problem)
 			}
 		case core.EventTaskDone:
 			if t, ok := m.tasks[msg.TaskID]; ok {
@@ -526,27 +531,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case core.EventTaskError:
 			if t, ok := m.tasks[msg.TaskID]; ok {
 				t.status = "error"
+				if strings.HasPrefix(msg.Data, "cancelled") {
+					t.status = "cancelled"
+				}
 				t.errorMsg = msg.Data
 				t.elapsed = msg.Time.Sub(t.start)
 			}
 		case core.EventPipelineDone:
+			if m.currentState != stateRunning {
+				break
+			}
 			m.currentState = stateDone
 			// Add to history
-			status := "done"
-			for _, t := range m.tasks {
-				if t.status == "error" {
-					status = "error"
-					break
-				}
-			}
-			m.history = append(m.history, runRecord{
-				ID:        m.activeMenuItem,
-				StartTime: time.Now(), // rough estimate or track properly
-				Status:    status,
-			})
-			if len(m.history) > 20 {
-				m.history = m.history[1:]
-			}
+			run := m.recorder.Finish(msg.Data)
+			m.history = append(m.history, run)
+			m.timelineOffset = 0
+			cmds = append(cmds, saveHistoryCmd(run), notifyCmd(core.Notification{
+				Task:     run.Task,
+				Failed:   run.Status == "error",
+				Duration: run.Duration,
+			}))
+		}
+
+		// Re-tile when tasks start or finish
+		if len(m.paneTasks()) != paneCount {
+			m.layoutPanes()
 		}
 
 		// Wait for next event
@@ -554,6 +563,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case taskResultMsg:
 		m.currentState = stateDone
+
+	case execRequest:
+		cmds = append(cmds, execCmd(msg), listenForExec())
+
+	case notifyResultMsg:
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("Notification failed: %v", msg.err)
+		}
+
+	case historyResultMsg:
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("Failed to save run history: %v", msg.err)
+		}
 	}
 
 	// Always route standard messages nicely if inside menu or input
@@ -562,9 +584,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.list, cmd = m.list.Update(msg)
 		cmds = append(cmds, cmd)
 	} else if m.currentState == stateInput {
-		var cmd tea.Cmd
-		m.textInput, cmd = m.textInput.Update(msg)
-		cmds = append(cmds, cmd)
+		cmds = append(cmds, m.form.UpdateInput(msg))
 	}
 
 	return m, tea.Batch(cmds...)
@@ -574,14 +594,22 @@ func (m Model) renderHeader() string {
 	logo := logoStyle.Render("REPOKIT")
 
 	var tabs []string
-	titles := []string{"Commands", "Output", "History"}
+	titles := []string{"Commands", "Output", "History", "Timeline", "Problems"}
+	if n := len(m.problems()); n > 0 {
+		titles[tabProblems] = fmt.Sprintf("Problems (%d)", n)
+	}
+	x := appStyle.GetPaddingLeft() + headerStyle.GetPaddingLeft() + lipgloss.Width(logo)
 	for i, t := range titles {
 		label := fmt.Sprintf("%d:%s", i+1, t)
+		var rendered string
 		if tab(i) == m.activeTab {
-			tabs = append(tabs, activeTabStyle.Render(label))
+			rendered = activeTabStyle.Render(label)
 		} else {
-			tabs = append(tabs, tabStyle.Render(label))
+			rendered = tabStyle.Render(label)
 		}
+		tabs = append(tabs, rendered)
+		m.zones.add(zoneTab, i, rect{x, 0, lipgloss.Width(rendered), 1})
+		x += lipgloss.Width(rendered)
 	}
 
 	tabRow := lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
@@ -600,7 +628,9 @@ func (m Model) View() string {
 		return ""
 	}
 
+	m.zones.reset()
 	header := m.renderHeader()
+	top := lipgloss.Height(header)
 	var content string
 
 	switch m.activeTab {
@@ -612,9 +642,10 @@ func (m Model) View() string {
 		rightWidth := innerWidth
 
 		if !m.sidebarCollapsed {
-			leftWidth := 35
+			leftWidth := m.sidebarWidth
 			rightWidth = innerWidth - leftWidth
 			left = leftPaneStyle.Width(leftWidth - 2).Render(m.list.View())
+			m.recordSidebar(left, appStyle.GetPaddingLeft(), top)
 		}
 
 		var right string
@@ -622,14 +653,11 @@ func (m Model) View() string {
 		case stateMenu:
 			desc := lipgloss.NewStyle().Foreground(colorMuted).Render("Select a command from the left to execute.\n\n" +
 				"Use Tab / Shift-Tab to switch views.\n" +
-				"Use 1, 2, 3 for direct navigation.")
+				"Use 1, 2, 3 for direct navigation.\n" +
+				"Press Ctrl+K to open the command palette.")
 			right = fmt.Sprintf("\n%s\n", desc)
 		case stateInput:
-			right = fmt.Sprintf(
-				"%s\n\n%s\n\n(esc to cancel)",
-				lipgloss.NewStyle().Foreground(colorAccent).Render(m.taskQuery),
-				m.textInput.View(),
-			)
+			right = m.form.View()
 		case stateRunning, stateDone:
 			right = lipgloss.NewStyle().Foreground(colorMuted).Italic(true).Render("Task is running...\n\nSwitch to Output tab (2) to see details.")
 		}
@@ -645,6 +673,9 @@ func (m Model) View() string {
 		var sb strings.Builder
 		if len(m.taskIds) == 0 {
 			sb.WriteString(fmt.Sprintf("\n  %s Waiting for task execution...\n", m.spinner.View()))
+		} else if m.layout == layoutSplit {
+			sb.WriteString(fmt.Sprintf(" Pipeline: %s\n", lipgloss.NewStyle().Foreground(colorAccent).Render(m.activeMenuItem)))
+			sb.WriteString(m.renderPanes(top + 1))
 		} else {
 			sb.WriteString(fmt.Sprintf(" Pipeline: %s\n", lipgloss.NewStyle().Foreground(colorAccent).Render(m.activeMenuItem)))
 
@@ -659,6 +690,8 @@ func (m Model) View() string {
 				allText = lipgloss.NewStyle().Background(colorAccent).Foreground(lipgloss.Color("#FFFFFF")).Render(" ALL TASKS ")
 			}
 			sb.WriteString(fmt.Sprintf(" %s %s\n", allIcon, allText))
+			rowX, rowWidth := appStyle.GetPaddingLeft(), m.width-appStyle.GetHorizontalPadding()
+			m.zones.add(zoneTaskRow, -1, rect{rowX, top + 1, rowWidth, 1})
 
 			// Show task summaries in a compact way
 			for idx, id := range m.taskIds {
@@ -667,7 +700,7 @@ func (m Model) View() string {
 
 				var icon, statText string
 				durStr := lipgloss.NewStyle().Foreground(colorMuted).Render(fmt.Sprintf("%5.1fs", time.Since(t.start).Seconds()))
-				if t.status == "done" || t.status == "error" {
+				if t.status == "done" || t.status == "error" || t.status == "cancelled" {
 					durStr = lipgloss.NewStyle().Foreground(colorMuted).Render(fmt.Sprintf("%5.1fs", t.elapsed.Seconds()))
 				}
 
@@ -678,6 +711,9 @@ func (m Model) View() string {
 				case "error":
 					icon = taskStyleError.Render("✕")
 					statText = taskStyleError.Render("FAIL")
+				case "cancelled":
+					icon = core.Yellow.Render("⏹")
+					statText = core.Yellow.Render("STOP")
 				case "running":
 					icon = taskStylePending.Render(m.spinner.View())
 					statText = taskStylePending.Render("RUN ")
@@ -695,26 +731,51 @@ func (m Model) View() string {
 					taskName = lipgloss.NewStyle().Foreground(colorAccent).Underline(true).Render(t.name)
 				}
 
-				sb.WriteString(fmt.Sprintf(" %s %-25.25s %s %s\n", icon, taskName, statText, durStr))
+				detail := renderUsage(t)
+				if t.status == "cancelled" {
+					detail = core.Subtle.Render(t.errorMsg)
+				}
+				if outputs := renderOutputs(t.logFile, t.artifacts); outputs != "" {
+					detail += "  " + outputs
+				}
+				sb.WriteString(fmt.Sprintf(" %s %-25.25s %s %s  %s\n", icon, taskName, statText, durStr, detail))
+				m.zones.add(zoneTaskRow, idx, rect{rowX, top + 2 + idx, rowWidth, 1})
 			}
 		}
 
-		viewportStyle := tabWindowStyle.Width(m.width - 4)
-		if !m.focusOutputList {
-			viewportStyle = viewportStyle.BorderForeground(colorAccent)
+		if m.layout == layoutCombined {
+			viewportStyle := tabWindowStyle.Width(m.width - 4)
+			if !m.focusOutputList {
+				viewportStyle = viewportStyle.BorderForeground(colorAccent)
+			}
+			rendered := viewportStyle.Render(m.viewport.View())
+			logTop := top + strings.Count(sb.String(), "\n") + 1
+			m.zones.add(zoneLog, 0, rect{appStyle.GetPaddingLeft(), logTop, lipgloss.Width(rendered), lipgloss.Height(rendered)})
+			sb.WriteString("\n" + rendered)
 		}
-		sb.WriteString("\n" + viewportStyle.Render(m.viewport.View()))
 
 		// Navigation Bar
 		sb.WriteString("\n\n")
 		var help []string
-		help = append(help, keyStyle.Render("↑/↓")+" navigate tasks")
-		help = append(help, keyStyle.Render("Enter")+" toggle scroll")
-		help = append(help, keyStyle.Render("1,2,3")+" switch tabs")
+		if m.layout == layoutSplit {
+			help = append(help, keyStyle.Render("←/→")+" focus pane")
+			help = append(help, keyStyle.Render("↑/↓")+" scroll")
+			help = append(help, keyStyle.Render("z")+" zoom")
+			help = append(help, keyStyle.Render("s")+" combined view")
+		} else {
+			help = append(help, keyStyle.Render("↑/↓")+" navigate tasks")
+			help = append(help, keyStyle.Render("Enter")+" toggle scroll")
+			help = append(help, keyStyle.Render("s")+" split view")
+		}
+		help = append(help, keyStyle.Render("1-5")+" switch tabs")
+		help = append(help, keyStyle.Render("Ctrl+K")+" palette")
 		if m.currentState == stateDone {
 			help = append(help, keyStyle.Render("Esc")+" back to menu")
 		}
 		sb.WriteString("  " + helpStyle.Render(strings.Join(help, " • ")))
+		if m.notice != "" {
+			sb.WriteString("\n  " + core.Subtle.Render(m.notice))
+		}
 
 		if m.currentState == stateDone {
 			// sb.WriteString("\n" + core.Subtle.Render("(Press enter/esc to return to menu)"))
@@ -733,15 +794,80 @@ func (m Model) View() string {
 				if h.Status == "error" {
 					status = taskStyleError.Render("FAIL")
 				}
-				sb.WriteString(fmt.Sprintf("  %-25s %s %s\n", h.ID, core.Subtle.Render(h.StartTime.Format("15:04:05")), status))
+				when := fmt.Sprintf("%s %7.1fs", h.Start.Format("01-02 15:04:05"), h.Duration.Seconds())
+				sb.WriteString(fmt.Sprintf("  %-25s %s %s  %s%s\n", h.Task, core.Subtle.Render(when), status, renderHeaviest(h.Tasks), renderRunOutputs(h)))
 			}
 		}
 		content = sb.String()
+
+	case tabTimeline:
+		content = m.renderTimeline()
+
+	case tabProblems:
+		content = m.renderProblems()
+	}
+
+	if m.paletteOpen {
+		width := min(m.width-4, 90)
+		content = lipgloss.PlaceHorizontal(m.width-2, lipgloss.Center, m.palette.View(width))
 	}
 
 	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left, header, content))
 }
 
+// menuItems returns every command listed in the sidebar.
+func (m Model) menuItems() []item {
+	var items []item
+	for _, li := range m.list.Items() {
+		if i, ok := li.(item); ok {
+			items = append(items, i)
+		}
+	}
+	return items
+}
+
+// selectItem starts the command with the given ID, first asking for its
+// params when it is a native command that takes any.
+func (m *Model) selectItem(id string) tea.Cmd {
+	m.activeMenuItem = id
+	if c, ok := m.nativeCommand(id); ok && len(c.Params()) > 0 {
+		m.currentState = stateInput
+		m.form = newParamForm(c)
+		m.activeTab = tabCommands
+		return textinput.Blink
+	}
+	m.resetRun()
+	return runBgTaskCmd(id, nil)
+}
+
+// nativeCommand returns the native command an item runs. Tasks in
+// tasks.yaml take precedence over native commands of the same ID.
+func (m *Model) nativeCommand(id string) (core.NativeCommand, bool) {
+	if config, err := core.GetConfig(); err == nil {
+		if _, ok := config.Tasks[id]; ok {
+			return nil, false
+		}
+	}
+	return core.LookupNative(id)
+}
+
+// resetRun clears the output of the previous run and switches to the Output tab.
+func (m *Model) resetRun() {
+	m.currentState = stateRunning
+	m.runStart = time.Now()
+	m.recorder = core.StartRecording(m.activeMenuItem)
+	m.tasks = make(map[string]*taskState)
+	m.taskIds = nil
+	m.selectedTaskIndex = -1
+	m.fullLog = nil
+	m.problemsOffset = 0
+	m.viewport.SetContent("")
+	m.panes = make(map[string]*viewport.Model)
+	m.focusedPane = 0
+	m.zoomed = false
+	m.activeTab = tabOutput
+}
+
 // ─── TUI Event Loop and Executor ─────────────────────────────────────────────
 
 func listenForEvents() tea.Cmd {
@@ -754,32 +880,47 @@ type taskResultMsg struct {
 	err error
 }
 
-func runBgTaskCmd(taskID string, arg string) tea.Cmd {
+type notifyResultMsg struct {
+	err error
+}
+
+type historyResultMsg struct {
+	err error
+}
+
+// saveHistoryCmd persists a finished run so that it survives restarts and
+// can be exported with `repokit report timeline`.
+func saveHistoryCmd(run core.RunRecord) tea.Cmd {
+	return func() tea.Msg {
+		return historyResultMsg{err: core.AppendHistory(run)}
+	}
+}
+
+// notifyCmd sends the completion notification off the UI goroutine, since
+// the user's notify command may take a moment.
+func notifyCmd(n core.Notification) tea.Cmd {
+	return func() tea.Msg {
+		return notifyResultMsg{err: core.Notify(n)}
+	}
+}
+
+// runBgTaskCmd runs a task, or a native command with the params entered in
+// its form when params is non-nil.
+func runBgTaskCmd(taskID string, params core.Params) tea.Cmd {
 	return func() tea.Msg {
 		var runErr error
 		defer func() {
+			var failure string
 			if r := recover(); r != nil {
 				runErr = fmt.Errorf("task panic: %v", r)
+				failure = runErr.Error()
 			}
-			core.PublishEvent(core.EventPipelineDone, "pipeline", "")
+			core.PublishEvent(core.EventPipelineDone, "pipeline", failure)
 		}()
 
-		switch taskID {
-		case "pack":
-			commands.RunPack(arg)
-		case "clean":
-			val := strings.ToLower(strings.TrimSpace(arg))
-			force := val == "y" || val == "yes"
-			if force || arg == "" {
-				commands.RunClean(force)
-			} else {
-				core.PublishEvent(core.EventTaskLog, "clean", "Aborted by user.")
-			}
-		case "generate_readme":
-			runner.RunTask(taskID, nil, nil)
-		case "auto_commit":
-			runner.RunTask(taskID, nil, nil)
-		default:
+		if params != nil {
+			runner.RunNative(taskID, params)
+		} else {
 			runner.RunTask(taskID, nil, nil)
 		}
 
@@ -788,6 +929,62 @@ func runBgTaskCmd(taskID string, arg string) tea.Cmd {
 	}
 }
 
+// appendLog adds a line of task output to the combined log and the task's
+// pane. A line following an in-place update on the same stream overwrites
+// it, so progress bars redraw instead of filling the log.
+func (m *Model) appendLog(e core.Event) {
+	t, ok := m.tasks[e.TaskID]
+	if !ok {
+		return
+	}
+	clean := core.CleanANSI(e.Data)
+	replace := t.partialStream != "" && t.partialStream == e.Stream &&
+		t.partialAt >= 0 && t.partialAt < len(m.fullLog)
+	t.partialStream = ""
+
+	if !replace {
+		t.logs = append(t.logs, clean)
+		if len(t.logs) > 5 {
+			t.logs = t.logs[1:]
+		}
+	} else if len(t.logs) > 0 {
+		t.logs[len(t.logs)-1] = clean
+	}
+
+	if clean == "" && !replace {
+		return
+	}
+	text := colorizeLog(clean)
+	if e.Stream == core.StreamStderr {
+		text = stderrStyle.Render(clean)
+	}
+	formatted := colorizeLog(fmt.Sprintf("[%s] ", e.TaskID)) + text
+
+	if replace {
+		m.fullLog[t.partialAt] = formatted
+	} else {
+		m.fullLog = append(m.fullLog, formatted)
+		if over := len(m.fullLog) - 500; over > 0 {
+			m.fullLog = m.fullLog[over:]
+			for _, other := range m.tasks {
+				other.partialAt -= over
+				if other.partialAt < 0 {
+					other.partialStream = ""
+				}
+			}
+		}
+	}
+	if e.Partial {
+		t.partialStream = e.Stream
+		if !replace {
+			t.partialAt = len(m.fullLog) - 1
+		}
+	}
+
+	m.updateViewportContent()
+	m.appendPaneLog(e.TaskID, text, replace)
+}
+
 func (m *Model) updateViewportContent() {
 	var logs []string
 	if m.selectedTaskIndex == -1 {
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestUpdate_MouseIgnoredUnderPalette(t *testing.T) {
+	click := func(m Model) Model {
+		for _, action := range []tea.MouseAction{tea.MouseActionPress, tea.MouseActionRelease} {
+			next, _ := m.Update(tea.MouseMsg{X: 5, Y: 1, Button: tea.MouseButtonLeft, Action: action})
+			m = next.(Model)
+		}
+		return m
+	}
+	m := Model{activeTab: tabCommands, zones: &zoneMap{}, palette: newPalette()}
+	m.zones.add(zoneTab, int(tabHistory), rect{0, 0, 20, 3})
+
+	m.paletteOpen = true
+	if got := click(m); got.activeTab != tabCommands {
+		t.Errorf("a click on the open palette switched to tab %d", got.activeTab)
+	}
+
+	m.paletteOpen = false
+	if got := click(m); got.activeTab != tabHistory {
+		t.Errorf("expected the click to switch to the history tab, got tab %d", got.activeTab)
+	}
+}
+
+func TestResetRun_ClearsSelection(t *testing.T) {
+	m := Model{zones: &zoneMap{}, taskIds: []string{"a", "b", "c"}, selectedTaskIndex: 2}
+	m.resetRun()
+	defer m.recorder.Finish("")
+
+	if m.selectedTaskIndex != -1 {
+		t.Fatalf("expected the new run to show all tasks, got index %d", m.selectedTaskIndex)
+	}
+	m.taskIds = []string{"a"}
+	m.updateViewportContent() // panicked on the stale index
+}
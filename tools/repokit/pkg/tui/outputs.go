@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"repokit/pkg/core"
+)
+
+// ─── Captured Logs & Artifacts ───────────────────────────────────────────────
+
+// hyperlink wraps label in an OSC 8 escape linking to a local file, which
+// supporting terminals make clickable and others render as plain text.
+func hyperlink(path, label string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return label
+	}
+	return fmt.Sprintf("\x1b]8;;file://%s\x1b\\%s\x1b]8;;\x1b\\", filepath.ToSlash(abs), label)
+}
+
+// renderOutputs links a task's captured log and its artifacts directory.
+func renderOutputs(logFile string, artifacts []string) string {
+	var out string
+	if logFile != "" {
+		out = hyperlink(logFile, core.Cyan.Render("log"))
+	}
+	if len(artifacts) > 0 {
+		if out != "" {
+			out += core.Subtle.Render(" · ")
+		}
+		label := core.Cyan.Render(fmt.Sprintf("%d artifacts", len(artifacts)))
+		if len(artifacts) == 1 {
+			label = core.Cyan.Render(filepath.Base(artifacts[0]))
+		}
+		out += hyperlink(artifactsDir(artifacts[0]), label)
+	}
+	return out
+}
+
+// renderRunOutputs links the run directory of a history entry when any of
+// its tasks captured a log or collected artifacts.
+func renderRunOutputs(run core.RunRecord) string {
+	for _, t := range run.Tasks {
+		if t.LogFile != "" || len(t.Artifacts) > 0 {
+			dir := core.RunDir(run.ID)
+			return "  " + hyperlink(dir, core.Cyan.Render(dir))
+		}
+	}
+	return ""
+}
+
+// artifactsDir finds the artifacts directory an artifact was collected into.
+func artifactsDir(artifact string) string {
+	for dir := filepath.Dir(artifact); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == "artifacts" {
+			return dir
+		}
+	}
+	return filepath.Dir(artifact)
+}
@@ -0,0 +1,378 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+
+	"repokit/pkg/core"
+)
+
+// ─── Command Palette ─────────────────────────────────────────────────────────
+
+type paletteKind int
+
+const (
+	paletteRecent paletteKind = iota
+	paletteAction
+	paletteTask
+)
+
+const (
+	actionToggleSidebar = "toggle_sidebar"
+	actionRerun         = "rerun"
+	actionExportLog     = "export_log"
+
+	paletteMaxRows = 10
+)
+
+var (
+	paletteBoxStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(colorAccent).
+			Padding(0, 1)
+
+	paletteSelectedStyle = lipgloss.NewStyle().Background(colorAccent).Foreground(lipgloss.Color("#FFFFFF"))
+	paletteKindStyle     = lipgloss.NewStyle().Foreground(colorMuted).Width(10)
+)
+
+type paletteEntry struct {
+	kind        paletteKind
+	id          string
+	title       string
+	description string
+	runs        int
+}
+
+func (e paletteEntry) kindLabel() string {
+	switch e.kind {
+	case paletteRecent:
+		return fmt.Sprintf("recent×%d", e.runs)
+	case paletteAction:
+		return "action"
+	default:
+		return "task"
+	}
+}
+
+// paletteSource adapts palette entries to the fuzzy matcher, matching against
+// the ID, name and description of every entry.
+type paletteSource []paletteEntry
+
+func (s paletteSource) String(i int) string {
+	return s[i].id + " " + s[i].title + " " + s[i].description
+}
+func (s paletteSource) Len() int { return len(s) }
+
+type palette struct {
+	input   textinput.Model
+	entries []paletteEntry
+	matches []paletteEntry
+	cursor  int
+}
+
+func newPalette() palette {
+	ti := textinput.New()
+	ti.Prompt = "❯ "
+	ti.Placeholder = "Search tasks, recent runs and actions..."
+	ti.CharLimit = 64
+	ti.Width = 50
+	return palette{input: ti}
+}
+
+// open resets the palette with a fresh set of entries built from the menu
+// items and run history.
+func (p *palette) open(items []item, history []core.RunRecord) {
+	p.entries = buildPaletteEntries(items, history)
+	p.input.SetValue("")
+	p.input.Focus()
+	p.refresh()
+}
+
+func (p *palette) close() {
+	p.input.Blur()
+}
+
+// refresh re-runs the fuzzy match for the current query.
+func (p *palette) refresh() {
+	query := strings.TrimSpace(p.input.Value())
+	if query == "" {
+		p.matches = p.entries
+	} else {
+		found := fuzzy.FindFrom(query, paletteSource(p.entries))
+		p.matches = make([]paletteEntry, 0, len(found))
+		for _, f := range found {
+			p.matches = append(p.matches, p.entries[f.Index])
+		}
+	}
+	if p.cursor >= len(p.matches) {
+		p.cursor = len(p.matches) - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+}
+
+func (p *palette) move(delta int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.cursor = (p.cursor + delta + len(p.matches)) % len(p.matches)
+}
+
+func (p *palette) selected() (paletteEntry, bool) {
+	if p.cursor < 0 || p.cursor >= len(p.matches) {
+		return paletteEntry{}, false
+	}
+	return p.matches[p.cursor], true
+}
+
+// buildPaletteEntries orders entries as recent runs (most frequent first),
+// TUI actions, then every task alphabetically.
+func buildPaletteEntries(items []item, history []core.RunRecord) []paletteEntry {
+	byID := make(map[string]item, len(items))
+	for _, it := range items {
+		byID[it.id] = it
+	}
+
+	counts := make(map[string]int)
+	lastSeen := make(map[string]int)
+	for i, h := range history {
+		counts[h.Task]++
+		lastSeen[h.Task] = i
+	}
+
+	var recent []paletteEntry
+	for id, n := range counts {
+		it, ok := byID[id]
+		if !ok {
+			continue
+		}
+		recent = append(recent, paletteEntry{kind: paletteRecent, id: id, title: it.title, description: it.description, runs: n})
+	}
+	sort.Slice(recent, func(i, j int) bool {
+		if recent[i].runs != recent[j].runs {
+			return recent[i].runs > recent[j].runs
+		}
+		return lastSeen[recent[i].id] > lastSeen[recent[j].id]
+	})
+
+	entries := append([]paletteEntry{}, recent...)
+	entries = append(entries,
+		paletteEntry{kind: paletteAction, id: actionToggleSidebar, title: "Toggle Sidebar", description: "Show or hide the command list"},
+		paletteEntry{kind: paletteAction, id: actionRerun, title: "Rerun Last Task", description: "Run the most recent task again"},
+		paletteEntry{kind: paletteAction, id: actionExportLog, title: "Export Log", description: "Write the combined output log to a file"},
+	)
+
+	tasks := make([]paletteEntry, 0, len(items))
+	for _, it := range items {
+		tasks = append(tasks, paletteEntry{kind: paletteTask, id: it.id, title: it.title, description: it.description})
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].id < tasks[j].id })
+
+	return append(entries, tasks...)
+}
+
+// ─── Model Integration ───────────────────────────────────────────────────────
+
+// updatePalette handles key input while the palette is open.
+func (m Model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+k":
+		m.paletteOpen = false
+		m.palette.close()
+		return m, nil
+	case "up", "ctrl+p":
+		m.palette.move(-1)
+		return m, nil
+	case "down", "ctrl+n":
+		m.palette.move(1)
+		return m, nil
+	case "enter":
+		e, ok := m.palette.selected()
+		if !ok {
+			return m, nil
+		}
+		m.paletteOpen = false
+		m.palette.close()
+		return m, m.runPaletteEntry(e)
+	}
+
+	var cmd tea.Cmd
+	m.palette.input, cmd = m.palette.input.Update(msg)
+	m.palette.refresh()
+	return m, cmd
+}
+
+// runPaletteEntry executes the selected task or TUI action.
+func (m *Model) runPaletteEntry(e paletteEntry) tea.Cmd {
+	m.notice = ""
+
+	if e.kind != paletteAction {
+		if m.currentState == stateRunning {
+			m.notice = "A task is already running."
+			return nil
+		}
+		return m.selectItem(e.id)
+	}
+
+	switch e.id {
+	case actionToggleSidebar:
+		m.sidebarCollapsed = !m.sidebarCollapsed
+		m.resize()
+	case actionRerun:
+		if m.currentState == stateRunning {
+			m.notice = "A task is already running."
+			return nil
+		}
+		if len(m.history) == 0 {
+			m.notice = "Nothing to rerun yet."
+			return nil
+		}
+		return m.selectItem(m.history[len(m.history)-1].Task)
+	case actionExportLog:
+		path, err := exportLog(m.activeMenuItem, m.fullLog)
+		if err != nil {
+			m.notice = fmt.Sprintf("Failed to export log: %v", err)
+		} else {
+			m.notice = "Log exported to " + path
+		}
+		m.activeTab = tabOutput
+	}
+	return nil
+}
+
+// exportLog writes the combined output, stripped of ANSI codes, next to the
+// other generated artifacts in the dist directory of the rk_dir var. Without
+// that var, logs go to the history directory with the per-run logs.
+func exportLog(taskID string, lines []string) (string, error) {
+	if len(lines) == 0 {
+		return "", fmt.Errorf("log is empty")
+	}
+	if taskID == "" {
+		taskID = "repokit"
+	}
+
+	dir := filepath.Join(core.HistoryDir, "logs")
+	if config, err := core.GetConfig(); err == nil && config.Vars["rk_dir"] != "" {
+		dir = filepath.Join(config.Vars["rk_dir"], "dist", "logs")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, line := range lines {
+		sb.WriteString(core.CleanANSI(line) + "\n")
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s.log", taskID, time.Now().Format("20060102-150405")))
+	return path, os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+// ─── Rendering ───────────────────────────────────────────────────────────────
+
+func (p palette) View(width int) string {
+	var sb strings.Builder
+	sb.WriteString(p.input.View() + "\n\n")
+
+	if len(p.matches) == 0 {
+		sb.WriteString(core.Subtle.Render("  No matches."))
+	}
+
+	// Keep the cursor visible by scrolling the result window.
+	start := 0
+	if p.cursor >= paletteMaxRows {
+		start = p.cursor - paletteMaxRows + 1
+	}
+	end := start + paletteMaxRows
+	if end > len(p.matches) {
+		end = len(p.matches)
+	}
+
+	for i := start; i < end; i++ {
+		e := p.matches[i]
+		line := fmt.Sprintf("%-24.24s %s", e.title, lipgloss.NewStyle().Foreground(colorMuted).Render("("+e.id+")"))
+		if i == p.cursor {
+			line = paletteSelectedStyle.Render(fmt.Sprintf("%-24.24s (%s)", e.title, e.id))
+		}
+		sb.WriteString(paletteKindStyle.Render(e.kindLabel()) + " " + line + "\n")
+	}
+
+	if e, ok := p.selected(); ok {
+		sb.WriteString("\n" + tabWindowStyle.Width(width-4).Render(renderPreview(e)))
+	}
+
+	help := keyStyle.Render("↑/↓") + " select • " + keyStyle.Render("Enter") + " run • " + keyStyle.Render("Esc") + " close"
+	sb.WriteString("\n" + helpStyle.Render(help))
+
+	return paletteBoxStyle.Width(width).Render(sb.String())
+}
+
+// renderPreview describes what an entry will execute before it is run.
+func renderPreview(e paletteEntry) string {
+	label := lipgloss.NewStyle().Foreground(colorMuted).Width(10)
+	row := func(k, v string) string { return label.Render(k) + v + "\n" }
+
+	if e.kind == paletteAction {
+		return row("action", e.description)
+	}
+
+	task, err := core.GetTaskByID(e.id)
+	if err != nil {
+		return row("native", e.description)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(row("type", task.Type))
+	if c, ok := core.LookupNative(e.id); ok && task.Type == "native" {
+		var names []string
+		for _, p := range c.Params() {
+			names = append(names, p.Name)
+		}
+		if len(names) > 0 {
+			sb.WriteString(row("params", strings.Join(names, ", ")))
+		}
+	}
+	if task.Command != "" {
+		sb.WriteString(row("command", task.Command))
+	}
+	if len(task.Tasks) > 0 {
+		mode := "sequential"
+		if task.Parallel {
+			workers := task.Workers
+			if workers <= 0 {
+				workers = 3
+			}
+			mode = fmt.Sprintf("parallel (%d workers)", workers)
+		}
+		sb.WriteString(row("tasks", strings.Join(task.Tasks, ", ")+" "+core.Subtle.Render(mode)))
+	}
+	if task.Cwd != "" {
+		sb.WriteString(row("cwd", task.Cwd))
+	}
+	if len(task.PreRun) > 0 {
+		sb.WriteString(row("pre_run", strings.Join(task.PreRun, ", ")))
+	}
+	if len(task.PostRun) > 0 {
+		sb.WriteString(row("post_run", strings.Join(task.PostRun, ", ")))
+	}
+	if task.LogFile != "" {
+		sb.WriteString(row("log_file", task.LogFile))
+	}
+	if len(task.Artifacts) > 0 {
+		sb.WriteString(row("artifacts", strings.Join(task.Artifacts, ", ")))
+	}
+	if len(task.ProblemMatchers) > 0 {
+		sb.WriteString(row("problem_matchers", strings.Join(task.ProblemMatchers, ", ")))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
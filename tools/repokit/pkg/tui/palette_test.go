@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"repokit/pkg/core"
+)
+
+func TestExportLog(t *testing.T) {
+	config, err := core.GetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+
+	path, err := exportLog("build", []string{"\x1b[32mok\x1b[0m", "done"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir := filepath.Join(config.Vars["rk_dir"], "dist", "logs"); filepath.Dir(path) != dir {
+		t.Errorf("expected the log in %s, got %s", dir, path)
+	}
+	if !strings.HasPrefix(filepath.Base(path), "build_") {
+		t.Errorf("expected the log to be named after the task, got %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "ok\ndone\n" {
+		t.Errorf("unexpected log %q", data)
+	}
+
+	if _, err := exportLog("build", nil); err == nil {
+		t.Error("expected an empty log to fail")
+	}
+}
@@ -0,0 +1,254 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"repokit/pkg/core"
+)
+
+// ─── Split Output Layout ─────────────────────────────────────────────────────
+
+type outputLayout int
+
+const (
+	layoutCombined outputLayout = iota
+	layoutSplit
+)
+
+const (
+	minPaneWidth  = 30
+	minPaneHeight = 6
+	maxPaneLines  = 500
+)
+
+var (
+	paneStyle        = lipgloss.NewStyle().Border(lipgloss.NormalBorder()).BorderForeground(colorBorder)
+	paneFocusedStyle = paneStyle.BorderForeground(colorAccent)
+)
+
+// paneTasks returns the tasks that get a pane: every running task, or the
+// whole run once nothing is running so the result can still be reviewed.
+func (m Model) paneTasks() []string {
+	var running []string
+	for _, id := range m.taskIds {
+		if m.tasks[id].status == "running" {
+			running = append(running, id)
+		}
+	}
+	if len(running) > 0 {
+		return running
+	}
+	return m.taskIds
+}
+
+// paneArea returns the space available to the pane grid below the header.
+func (m Model) paneArea() (width, height int) {
+	h, v := appStyle.GetFrameSize()
+	// Header (3), pipeline title (1), help bar (3).
+	return m.width - h - 2, m.height - v - 7
+}
+
+// paneGrid picks the column/row arrangement that keeps panes closest to
+// square (terminal cells are roughly twice as tall as wide) while respecting
+// the minimum pane size. It returns how many panes fit.
+func paneGrid(n, width, height int) (cols, rows, fit int) {
+	if n == 0 {
+		return 0, 0, 0
+	}
+	best := -1.0
+	for c := 1; c <= n; c++ {
+		r := (n + c - 1) / c
+		w, h := width/c, height/r
+		if w < minPaneWidth || h < minPaneHeight {
+			continue
+		}
+		score := min(float64(w)/2, float64(h))
+		if score > best {
+			best, cols, rows = score, c, r
+		}
+	}
+	if cols > 0 {
+		return cols, rows, n
+	}
+
+	// Not everything fits: show as many minimum-size panes as possible.
+	cols = max(width/minPaneWidth, 1)
+	rows = max(height/minPaneHeight, 1)
+	fit = min(n, cols*rows)
+	rows = (fit + cols - 1) / cols
+	return cols, rows, fit
+}
+
+// pane returns the viewport for a task, creating it on first use.
+func (m *Model) pane(id string) *viewport.Model {
+	if vp, ok := m.panes[id]; ok {
+		return vp
+	}
+	vp := viewport.New(minPaneWidth, minPaneHeight)
+	m.panes[id] = &vp
+	return &vp
+}
+
+// appendPaneLog adds a line to a task's pane, following the tail unless the
+// user has scrolled up. With replace set, the line overwrites the last one.
+func (m *Model) appendPaneLog(id, line string, replace bool) {
+	t, ok := m.tasks[id]
+	if !ok {
+		return
+	}
+	if replace && len(t.output) > 0 {
+		t.output[len(t.output)-1] = line
+	} else {
+		t.output = append(t.output, line)
+	}
+	if len(t.output) > maxPaneLines {
+		t.output = t.output[len(t.output)-maxPaneLines:]
+	}
+
+	vp := m.pane(id)
+	follow := vp.AtBottom()
+	vp.SetContent(strings.Join(t.output, "\n"))
+	if follow {
+		vp.GotoBottom()
+	}
+}
+
+// layoutPanes resizes every pane viewport to the current grid.
+func (m *Model) layoutPanes() {
+	ids := m.paneTasks()
+	width, height := m.paneArea()
+	cols, rows, _ := paneGrid(len(ids), width, height)
+	if m.zoomed {
+		cols, rows = 1, 1
+	}
+	if cols == 0 {
+		return
+	}
+	for _, id := range ids {
+		vp := m.pane(id)
+		vp.Width = width/cols - 2
+		vp.Height = height/rows - 3
+		if vp.AtBottom() || vp.PastBottom() {
+			vp.GotoBottom()
+		}
+	}
+	if m.focusedPane >= len(ids) {
+		m.focusedPane = max(len(ids)-1, 0)
+	}
+}
+
+// scrollTarget returns the viewport that scroll input applies to: the focused
+// pane in the split layout, otherwise the combined log.
+func (m *Model) scrollTarget() *viewport.Model {
+	if m.activeTab == tabOutput && m.layout == layoutSplit {
+		if ids := m.paneTasks(); m.focusedPane < len(ids) {
+			return m.pane(ids[m.focusedPane])
+		}
+	}
+	return &m.viewport
+}
+
+// updateSplit handles keys specific to the split layout.
+func (m Model) updateSplit(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	ids := m.paneTasks()
+	switch msg.String() {
+	case "left", "h":
+		if len(ids) > 0 {
+			m.focusedPane = (m.focusedPane + len(ids) - 1) % len(ids)
+		}
+	case "right", "l":
+		if len(ids) > 0 {
+			m.focusedPane = (m.focusedPane + 1) % len(ids)
+		}
+	case "z":
+		m.zoomed = !m.zoomed
+		m.layoutPanes()
+	case "up", "down", "pgup", "pgdown":
+		if m.focusedPane < len(ids) {
+			vp := m.pane(ids[m.focusedPane])
+			var cmd tea.Cmd
+			*vp, cmd = vp.Update(msg)
+			return m, cmd, true
+		}
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// renderPanes tiles one viewport per task, or just the focused one when zoomed,
+// recording each pane's bounds relative to the given top row.
+func (m Model) renderPanes(top int) string {
+	ids := m.paneTasks()
+	width, height := m.paneArea()
+	cols, _, fit := paneGrid(len(ids), width, height)
+	if m.zoomed && m.focusedPane < len(ids) {
+		ids, cols, fit = ids[m.focusedPane:m.focusedPane+1], 1, 1
+	}
+	if fit == 0 {
+		return ""
+	}
+
+	var rows []string
+	var row []string
+	x, y := appStyle.GetPaddingLeft(), top
+	flush := func() {
+		joined := lipgloss.JoinHorizontal(lipgloss.Top, row...)
+		rows = append(rows, joined)
+		row = nil
+		x, y = appStyle.GetPaddingLeft(), y+lipgloss.Height(joined)
+	}
+	for i, id := range ids[:fit] {
+		index := i
+		if m.zoomed {
+			index = m.focusedPane
+		}
+		rendered := m.renderPane(id, m.zoomed || i == m.focusedPane)
+		m.zones.add(zonePane, index, rect{x, y, lipgloss.Width(rendered), lipgloss.Height(rendered)})
+		x += lipgloss.Width(rendered)
+		row = append(row, rendered)
+		if len(row) == cols {
+			flush()
+		}
+	}
+	if len(row) > 0 {
+		flush()
+	}
+
+	grid := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	if hidden := len(ids) - fit; hidden > 0 {
+		grid += "\n" + helpStyle.Render(fmt.Sprintf(" +%d more (enlarge the terminal or press z to zoom)", hidden))
+	}
+	return grid
+}
+
+func (m Model) renderPane(id string, focused bool) string {
+	t := m.tasks[id]
+	vp := m.pane(id)
+
+	icon := m.spinner.View()
+	elapsed := time.Since(t.start)
+	switch t.status {
+	case "done":
+		icon, elapsed = taskStyleSuccess.Render("✓"), t.elapsed
+	case "error":
+		icon, elapsed = taskStyleError.Render("✕"), t.elapsed
+	case "cancelled":
+		icon, elapsed = core.Yellow.Render("⏹"), t.elapsed
+	}
+
+	title := fmt.Sprintf("%s %s %s", icon, t.name, logStyle.Render(fmt.Sprintf("%.1fs", elapsed.Seconds())))
+	title = lipgloss.NewStyle().MaxWidth(vp.Width).Render(title)
+
+	style := paneStyle
+	if focused {
+		style = paneFocusedStyle
+	}
+	return style.Width(vp.Width).Height(vp.Height + 1).Render(title + "\n" + vp.View())
+}
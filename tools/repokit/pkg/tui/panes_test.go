@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPaneGrid(t *testing.T) {
+	tests := []struct {
+		name                string
+		n, width, height    int
+		cols, rows, fitting int
+	}{
+		{"No tasks", 0, 120, 40, 0, 0, 0},
+		{"One pane", 1, 100, 30, 1, 1, 1},
+		{"Side by side", 2, 120, 30, 2, 1, 2},
+		{"Two by two", 4, 120, 40, 2, 2, 4},
+		{"Tall terminal stacks", 2, 60, 40, 1, 2, 2},
+		{"Overflow shows minimum panes", 10, 60, 12, 2, 2, 4},
+		{"Tiny terminal shows one", 3, 10, 3, 1, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, rows, fit := paneGrid(tt.n, tt.width, tt.height)
+			if cols != tt.cols || rows != tt.rows || fit != tt.fitting {
+				t.Errorf("paneGrid(%d, %d, %d) = %d, %d, %d; want %d, %d, %d",
+					tt.n, tt.width, tt.height, cols, rows, fit, tt.cols, tt.rows, tt.fitting)
+			}
+		})
+	}
+}
+
+// splitModel returns a model showing the split layout of running tasks.
+func splitModel(ids ...string) Model {
+	m := Model{
+		tasks:   make(map[string]*taskState),
+		taskIds: ids,
+		layout:  layoutSplit,
+		width:   120,
+		height:  40,
+		zones:   &zoneMap{},
+	}
+	m.panes = make(map[string]*viewport.Model)
+	for _, id := range ids {
+		m.tasks[id] = &taskState{name: id, status: "running"}
+	}
+	return m
+}
+
+func TestUpdateSplit_Focus(t *testing.T) {
+	key := func(s string) tea.KeyMsg {
+		switch s {
+		case "left":
+			return tea.KeyMsg{Type: tea.KeyLeft}
+		case "right":
+			return tea.KeyMsg{Type: tea.KeyRight}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+	tests := []struct {
+		name  string
+		keys  []string
+		focus int
+		zoom  bool
+	}{
+		{"Right moves to the next pane", []string{"right"}, 1, false},
+		{"Right wraps around", []string{"right", "l", "right"}, 0, false},
+		{"Left wraps to the last pane", []string{"left"}, 2, false},
+		{"Left and right cancel out", []string{"h", "l"}, 0, false},
+		{"Zoom keeps focus", []string{"l", "z"}, 1, true},
+		{"Zoom toggles back", []string{"z", "z"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := splitModel("a", "b", "c")
+			for _, k := range tt.keys {
+				var handled bool
+				m, _, handled = m.updateSplit(key(k))
+				if !handled {
+					t.Fatalf("key %q was not handled", k)
+				}
+			}
+			if m.focusedPane != tt.focus || m.zoomed != tt.zoom {
+				t.Errorf("focus %d, zoomed %v; want %d, %v", m.focusedPane, m.zoomed, tt.focus, tt.zoom)
+			}
+		})
+	}
+
+	m := splitModel("a")
+	if _, _, handled := m.updateSplit(key("x")); handled {
+		t.Error("expected other keys to be left to the caller")
+	}
+}
+
+func TestLayoutPanes_ClampsFocus(t *testing.T) {
+	m := splitModel("a", "b", "c")
+	m.focusedPane = 2
+	m.tasks["b"].status = "done"
+	m.tasks["c"].status = "done"
+	m.layoutPanes()
+	if m.focusedPane != 0 {
+		t.Errorf("expected focus on the only running pane, got %d", m.focusedPane)
+	}
+	width, height := m.paneArea()
+	if vp := m.pane("a"); vp.Width != width-2 || vp.Height != height-3 {
+		t.Errorf("expected the single pane to fill the area, got %dx%d", vp.Width, vp.Height)
+	}
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"repokit/pkg/core"
+	"repokit/pkg/report"
+)
+
+// ─── Problems Tab ────────────────────────────────────────────────────────────
+
+// problems returns the diagnostics of the current run or, before anything
+// has run in this session, of the most recent recorded run.
+func (m Model) problems() []report.TaskProblem {
+	if len(m.taskIds) == 0 && len(m.history) > 0 {
+		return report.Problems(m.history[len(m.history)-1])
+	}
+	var problems []report.TaskProblem
+	for _, id := range m.taskIds {
+		for _, p := range m.tasks[id].problems {
+			problems = append(problems, report.TaskProblem{Task: id, Problem: p})
+		}
+	}
+	return problems
+}
+
+// problemLines renders the diagnostics grouped by file, one line each.
+func problemLines(problems []report.TaskProblem) []string {
+	var lines []string
+	for _, g := range report.GroupByFile(problems) {
+		file := core.Subtle.Render("(no location)")
+		if g.File != "" {
+			file = hyperlink(g.File, core.Cyan.Render(g.File))
+		}
+		lines = append(lines, " "+file+" "+core.Subtle.Render(fmt.Sprintf("(%d)", len(g.Problems))))
+		for _, p := range g.Problems {
+			pos := ""
+			if p.Line > 0 {
+				pos = fmt.Sprintf("%d:%d", p.Line, p.Column)
+			}
+			severity := logStyle.Render(fmt.Sprintf("%-7s", p.Severity))
+			switch p.Severity {
+			case "error":
+				severity = taskStyleError.Render(fmt.Sprintf("%-7s", p.Severity))
+			case "warning":
+				severity = core.Yellow.Render(fmt.Sprintf("%-7s", p.Severity))
+			}
+			lines = append(lines, fmt.Sprintf("   %-8s %s %s  %s", pos, severity, p.Message, core.Subtle.Render(p.Task)))
+		}
+	}
+	return lines
+}
+
+// problemsHeight is the number of list lines that fit on screen.
+func (m Model) problemsHeight() int {
+	return max(m.height-8, 3)
+}
+
+// maxProblemsOffset is the offset that shows the end of the list.
+func (m Model) maxProblemsOffset() int {
+	return max(len(problemLines(m.problems()))-m.problemsHeight(), 0)
+}
+
+// renderProblems draws the Problems tab, scrolled by problemsOffset lines.
+func (m Model) renderProblems() string {
+	var sb strings.Builder
+	problems := m.problems()
+	errors, warnings := report.CountSeverities(problems)
+	sb.WriteString(lipgloss.NewStyle().Bold(true).PaddingLeft(1).Render("Problems") + " " +
+		core.Subtle.Render(fmt.Sprintf("%d errors, %d warnings", errors, warnings)) + "\n\n")
+
+	if len(problems) == 0 {
+		sb.WriteString("  " + core.Subtle.Render("No problems found. Tasks report them through problem_matchers."))
+		return sb.String()
+	}
+
+	lines := problemLines(problems)
+	offset := min(m.problemsOffset, m.maxProblemsOffset())
+	end := min(offset+m.problemsHeight(), len(lines))
+	sb.WriteString(strings.Join(lines[offset:end], "\n") + "\n")
+	if end < len(lines) {
+		sb.WriteString(core.Subtle.Render(fmt.Sprintf("  … %d more lines", len(lines)-end)) + "\n")
+	}
+
+	help := []string{keyStyle.Render("↑/↓") + " scroll", keyStyle.Render("repokit report problems") + " export"}
+	sb.WriteString("\n  " + helpStyle.Render(strings.Join(help, " • ")))
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"repokit/pkg/core"
+	"repokit/pkg/report"
+)
+
+// ─── Timeline Tab ────────────────────────────────────────────────────────────
+
+const timelineLabelWidth = 18
+
+var (
+	ganttCriticalStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#fbbf24"))
+	ganttIdleStyle     = lipgloss.NewStyle().Foreground(colorBorder)
+)
+
+// renderTimeline draws a Gantt chart of the selected run: one bar per task,
+// critical path bars highlighted, and a row showing how many tasks ran at once.
+func (m Model) renderTimeline() string {
+	var sb strings.Builder
+	sb.WriteString(lipgloss.NewStyle().Bold(true).PaddingLeft(1).Render("Timeline") + "\n\n")
+
+	if len(m.history) == 0 {
+		sb.WriteString("  " + core.Subtle.Render("No completed runs yet."))
+		return sb.String()
+	}
+
+	index := len(m.history) - 1 - m.timelineOffset
+	run := m.history[index]
+	tl := report.NewTimeline(run)
+
+	sb.WriteString(fmt.Sprintf("  %s %s  %s\n\n", keyStyle.Render(run.Task),
+		core.Subtle.Render(run.Start.Format("2006-01-02 15:04:05")),
+		core.Subtle.Render(fmt.Sprintf("run %d/%d", index+1, len(m.history)))))
+
+	h, _ := appStyle.GetFrameSize()
+	width := max(m.width-h-timelineLabelWidth-12, 10)
+	if tl.Wall == 0 || len(tl.Bars) == 0 {
+		sb.WriteString("  " + core.Subtle.Render("This run recorded no task timings."))
+		return sb.String()
+	}
+	col := func(d time.Duration) int {
+		return min(int(float64(d)/float64(tl.Wall)*float64(width)), width)
+	}
+
+	for _, b := range tl.Bars {
+		start, end := col(b.Offset), col(b.End())
+		if end == start && b.Length > 0 {
+			end = min(start+1, width)
+		}
+		bar := strings.Repeat("█", end-start)
+		switch {
+		case b.Task.Status == "error":
+			bar = taskStyleError.Render(bar)
+		case b.Task.Status == "cancelled":
+			bar = core.Yellow.Render(bar)
+		case b.Critical:
+			bar = ganttCriticalStyle.Render(bar)
+		default:
+			bar = taskStyleSuccess.Render(bar)
+		}
+		label := fmt.Sprintf("%-*.*s", timelineLabelWidth, timelineLabelWidth, b.Task.ID)
+		sb.WriteString(fmt.Sprintf("  %s %s%s%s %s\n", label,
+			strings.Repeat(" ", start), bar, strings.Repeat(" ", width-end),
+			logStyle.Render(fmt.Sprintf("%.1fs", b.Length.Seconds()))))
+	}
+
+	// Concurrency row: one digit per column, dimmed where workers sat idle.
+	var conc strings.Builder
+	for _, n := range tl.Concurrency(tl.Wall, tl.Wall/time.Duration(width)) {
+		cell := fmt.Sprintf("%d", min(n, 9))
+		if n < tl.Workers {
+			cell = ganttIdleStyle.Render(cell)
+		}
+		conc.WriteString(cell)
+	}
+	sb.WriteString(fmt.Sprintf("  %-*s %s\n\n", timelineLabelWidth, "concurrency", conc.String()))
+
+	sb.WriteString("  " + core.Subtle.Render(tl.Summary()) + "\n")
+	sb.WriteString("  " + ganttCriticalStyle.Render("critical path: ") + core.Subtle.Render(strings.Join(tl.CriticalPath, " → ")) + "\n\n")
+
+	help := []string{keyStyle.Render("[/]") + " older/newer run", keyStyle.Render("repokit report timeline") + " export"}
+	sb.WriteString("  " + helpStyle.Render(strings.Join(help, " • ")))
+	return sb.String()
+}
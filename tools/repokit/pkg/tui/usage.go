@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"repokit/pkg/core"
+)
+
+const sparkWidth = 8
+
+var (
+	sparkBlocks   = []rune("▁▂▃▄▅▆▇█")
+	sparkCPUStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#06b6d4"))
+	sparkMemStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#a855f7"))
+)
+
+// recordUsage appends a usage sample to the task's sparkline history.
+func (t *taskState) recordUsage(u core.ResourceUsage) {
+	t.usage = u
+	if u.RSS == 0 && u.CPUPercent == 0 {
+		return // final summary sample, keep the sparkline as it was
+	}
+	t.cpuSamples = appendSample(t.cpuSamples, u.CPUPercent)
+	t.memSamples = appendSample(t.memSamples, float64(u.RSS))
+}
+
+func appendSample(samples []float64, v float64) []float64 {
+	samples = append(samples, v)
+	if len(samples) > sparkWidth {
+		samples = samples[len(samples)-sparkWidth:]
+	}
+	return samples
+}
+
+// sparkline renders values as a fixed-width bar chart scaled to the largest value.
+func sparkline(values []float64) string {
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat(" ", sparkWidth-len(values)))
+	for _, v := range values {
+		idx := 0
+		if peak > 0 {
+			idx = int(v / peak * float64(len(sparkBlocks)-1))
+		}
+		sb.WriteRune(sparkBlocks[idx])
+	}
+	return sb.String()
+}
+
+// renderUsage renders the live CPU and memory columns for a task row, or the
+// peak figures once the task has finished.
+func renderUsage(t *taskState) string {
+	if t.usage.PeakRSS == 0 && t.usage.CPUTime == 0 {
+		return ""
+	}
+	if t.status != "running" {
+		return core.Subtle.Render(fmt.Sprintf("peak %s · cpu %.1fs", core.FormatBytes(int64(t.usage.PeakRSS)), t.usage.CPUTime.Seconds()))
+	}
+	cpu := sparkCPUStyle.Render(sparkline(t.cpuSamples)) + core.Subtle.Render(fmt.Sprintf(" %4.0f%%", t.usage.CPUPercent))
+	mem := sparkMemStyle.Render(sparkline(t.memSamples)) + core.Subtle.Render(fmt.Sprintf(" %9s", core.FormatBytes(int64(t.usage.RSS))))
+	return cpu + "  " + mem
+}
+
+// renderHeaviest summarises the task with the highest peak memory in a run.
+func renderHeaviest(tasks []core.TaskRecord) string {
+	var heaviest *core.TaskRecord
+	for i := range tasks {
+		if tasks[i].PeakRSS > 0 && (heaviest == nil || tasks[i].PeakRSS > heaviest.PeakRSS) {
+			heaviest = &tasks[i]
+		}
+	}
+	if heaviest == nil {
+		return ""
+	}
+	return core.Subtle.Render(fmt.Sprintf("heaviest: %s (peak %s · cpu %.1fs)",
+		heaviest.ID, core.FormatBytes(int64(heaviest.PeakRSS)), heaviest.CPUTime.Seconds()))
+}